@@ -0,0 +1,212 @@
+package data
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ggymm/db/data/page"
+	"github.com/ggymm/db/pkg/bin"
+	"github.com/ggymm/db/tx"
+)
+
+// 堆（一组只保存同一个表的数据的页面）
+//
+// 堆中每个页面的第一个数据对象是所有者信息，flag 为 flagOwner（不能被读取）
+// +----------------+----------------+----------------+
+// |     flag       |      size      |     heapId     |
+// +----------------+----------------+----------------+
+// |    1 byte      |     2 byte     |     8 byte     |
+// +----------------+----------------+----------------+
+//
+// heapId 为堆中第一个页面的编号
+// 打开数据库时，根据所有者信息区分堆的页面和普通页面（普通页面才会添加到 pageIndex 中）
+//
+// 遍历堆中的页面即可获取表的全部数据，不需要通过索引
+// 删除堆时重置全部页面，页面变为普通页面
+
+var ErrNoSuchHeap = errors.New("no such heap")
+
+type heap struct {
+	sync.Mutex
+
+	pages []uint32
+	frees []uint32
+}
+
+// readHeapOwner 读取页面的所有者（不是堆的页面时返回 0）
+func readHeapOwner(p page.Page) uint64 {
+	off := page.DataOffset()
+	if page.ParsePageFSO(p) == off {
+		return 0
+	}
+	data := p.Data()[off:]
+	if data[offFlag] != flagOwner {
+		return 0
+	}
+	return bin.Uint64(data[offData:])
+}
+
+// heap 获取堆，open 为 true 时不存在则创建
+func (m *dataManage) heap(id uint64, open bool) *heap {
+	m.heapLock.Lock()
+	defer m.heapLock.Unlock()
+
+	h, ok := m.heaps[id]
+	if !ok && open {
+		h = new(heap)
+		m.heaps[id] = h
+	}
+	return h
+}
+
+// newHeapPage 创建堆的页面，并写入所有者信息（优先使用空页面）
+func (m *dataManage) newHeapPage(h *heap, id uint64) (uint32, error) {
+	no, ok := m.pageIndex.SelectEmpty()
+	if !ok {
+		no = m.pageManage.NewPage(page.NewPageX())
+	}
+	if id == 0 {
+		id = uint64(no)
+	}
+
+	p, err := m.pageManage.ObtainPage(no)
+	if err != nil {
+		return 0, err
+	}
+	defer p.Release()
+
+	data := wrapDataItem(bin.Uint64Raw(id))
+	data[offFlag] = flagOwner
+	m.log.Log(wrapInsertLog(tx.Super, p, data))
+	page.WritePageData(p, data)
+
+	h.pages = append(h.pages, no)
+	h.frees = append(h.frees, page.CalcPageFree(p))
+	return no, nil
+}
+
+// NewHeap 创建堆，返回堆的 id
+func (m *dataManage) NewHeap() (uint64, error) {
+	h := new(heap)
+	h.Lock()
+	defer h.Unlock()
+
+	no, err := m.newHeapPage(h, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	m.heapLock.Lock()
+	m.heaps[uint64(no)] = h
+	m.heapLock.Unlock()
+	return uint64(no), nil
+}
+
+// WriteHeap 写入数据到堆中
+func (m *dataManage) WriteHeap(tid uint64, id uint64, data []byte) (uint64, error) {
+	data = wrapDataItem(data)
+	length := uint32(len(data))
+	if length > page.MaxPageFree()-uint32(offData+8) {
+		return 0, ErrDataTooLarge
+	}
+
+	h := m.heap(id, false)
+	if h == nil {
+		return 0, ErrNoSuchHeap
+	}
+	h.Lock()
+	defer h.Unlock()
+
+	// 选择可以写入的页面（从最后一个页面开始）
+	i := len(h.pages) - 1
+	for ; i >= 0; i-- {
+		if h.frees[i] >= length {
+			break
+		}
+	}
+	if i < 0 {
+		_, err := m.newHeapPage(h, id)
+		if err != nil {
+			return 0, err
+		}
+		i = len(h.pages) - 1
+	}
+
+	// 获取 page
+	p, err := m.pageManage.ObtainPage(h.pages[i])
+	if err != nil {
+		return 0, err
+	}
+	defer p.Release()
+
+	// 保存日志
+	m.log.Log(wrapInsertLog(tid, p, data))
+
+	// 保存数据
+	off := page.WritePageData(p, data)
+	h.frees[i] = page.CalcPageFree(p)
+	return wrapDataItemId(p.No(), off), nil
+}
+
+// ScanHeap 获取堆中第 i 个页面中全部合法的数据对象的 id（按照偏移量的顺序）
+//
+// 每次只读取一个页面，调用方依次增加 i 遍历整个堆，页面不存在时返回 false
+func (m *dataManage) ScanHeap(id uint64, i int) ([]uint64, bool, error) {
+	h := m.heap(id, false)
+	if h == nil {
+		return nil, false, ErrNoSuchHeap
+	}
+	h.Lock()
+	defer h.Unlock()
+	if i >= len(h.pages) {
+		return nil, false, nil
+	}
+	no := h.pages[i]
+
+	p, err := m.pageManage.ObtainPage(no)
+	if err != nil {
+		return nil, false, err
+	}
+	defer p.Release()
+
+	var (
+		ids  = make([]uint64, 0)
+		data = p.Data()
+		off  = page.DataOffset()
+		fso  = page.ParsePageFSO(p)
+	)
+	for off < fso {
+		if data[off+offFlag] == flagValid {
+			ids = append(ids, wrapDataItemId(no, off))
+		}
+		off += offData + readDataItemSize(data[off+offSize:])
+	}
+	return ids, true, nil
+}
+
+// DropHeap 删除堆，重置堆的全部页面（调用方需要保证堆中的数据不再被引用）
+func (m *dataManage) DropHeap(id uint64) error {
+	m.heapLock.Lock()
+	h, ok := m.heaps[id]
+	delete(m.heaps, id)
+	m.heapLock.Unlock()
+	if !ok {
+		return ErrNoSuchHeap
+	}
+	h.Lock()
+	defer h.Unlock()
+
+	for _, no := range h.pages {
+		p, err := m.pageManage.ObtainPage(no)
+		if err != nil {
+			return err
+		}
+
+		// 保存日志，重置页面
+		m.log.Log(wrapResetLog(tx.Super, no))
+		page.ResetPage(p)
+		m.pageIndex.Add(no, page.CalcPageFree(p))
+		p.Release()
+	}
+	return nil
+}
@@ -24,10 +24,20 @@ import (
 // |    1 byte      |     2 byte     |     * byte     |
 // +----------------+----------------+----------------+
 //
-// flag：1 byte，标记数据是否合法（0 表示合法，1 表示非法）
+// flag：1 byte，标记数据是否合法（0 表示合法，1 表示非法，2 表示已释放，3 表示堆页面的所有者信息）
 // size：2 byte，标记 data 的长度
 // data：* byte，数据内容
 
+// 数据对象的标记
+//
+// 非法的数据对象（回滚的插入）仍然可能被索引引用，因此只有已释放的数据对象才能回收空间
+const (
+	flagValid   byte = 0
+	flagInvalid byte = 1
+	flagFree    byte = 2
+	flagOwner   byte = 3
+)
+
 const (
 	offFlag = 0
 	offSize = 1 // flag 占用 1 字节
@@ -119,7 +129,7 @@ func (item *dataItem) Id() uint64 {
 }
 
 func (item *dataItem) Flag() bool {
-	return item.data[offFlag] == 0
+	return item.data[offFlag] == flagValid
 }
 
 func (item *dataItem) Page() page.Page {
@@ -223,6 +223,7 @@ func (l *logger) check() error {
 	if err != nil {
 		return err
 	}
+	l.size = l.pos
 	_, err = l.file.Seek(l.pos, 0)
 	if err != nil {
 		return err
@@ -257,6 +258,7 @@ func (l *logger) Log(data []byte) {
 	if err != nil {
 		panic(err)
 	}
+	l.size += int64(len(log))
 
 	// 计算并写入新的 checksum
 	l.checksum = calcChecksum(l.checksum, log)
@@ -278,7 +280,7 @@ func (l *logger) Next() ([]byte, bool) {
 	if next == false {
 		return nil, false
 	}
-	return log[offData:], false
+	return log[offData:], true
 }
 
 func (l *logger) Rewind() {
@@ -12,4 +12,26 @@ func TestNewLog(t *testing.T) {
 }
 
 func TestLogger_Next(t *testing.T) {
+	opt := db.NewOption(db.RunPath(), "temp/log/next")
+	l := NewLog(opt)
+
+	src := []string{"hello", "world", "log"}
+	for _, s := range src {
+		l.Log([]byte(s))
+	}
+
+	l.Rewind()
+	for _, s := range src {
+		data, next := l.Next()
+		if !next {
+			t.Fatalf("log %s not found", s)
+		}
+		if string(data) != s {
+			t.Fatalf("log %s not equal %s", data, s)
+		}
+	}
+	if _, next := l.Next(); next {
+		t.Fatalf("log should be end")
+	}
+	l.Close()
 }
@@ -2,6 +2,7 @@ package data
 
 import (
 	"errors"
+	"sync"
 
 	"github.com/ggymm/db"
 	"github.com/ggymm/db/data/log"
@@ -34,6 +35,12 @@ type Manage interface {
 
 	Read(id uint64) (Item, bool, error)
 	Write(tid uint64, data []byte) (uint64, error)
+	Free(id uint64) error
+
+	NewHeap() (uint64, error)
+	WriteHeap(tid uint64, heap uint64, data []byte) (uint64, error)
+	ScanHeap(heap uint64, i int) ([]uint64, bool, error)
+	DropHeap(heap uint64) error
 
 	LogDataItem(tid uint64, item Item)
 	ReleaseDataItem(item Item)
@@ -50,6 +57,9 @@ type dataManage struct {
 	pageManage page.Manage // page 管理
 
 	cache cache.Cache // item 缓存
+
+	heapLock sync.Mutex
+	heaps    map[uint64]*heap // 堆（堆的页面不在 pageIndex 中）
 }
 
 func open(m *dataManage) {
@@ -63,17 +73,23 @@ func open(m *dataManage) {
 	// 根据 page1 校验数据
 	if page.CheckVc(m.page1) == false {
 		// 执行恢复操作
-		// TODO
+		recoverData(m)
 	}
 
-	// 读取 page 数据，填充 pageIndex
+	// 读取 page 数据，填充 pageIndex 和堆
 	num := m.pageManage.PageNum()
 	for i := 2; i <= num; i++ {
 		p, e := m.pageManage.ObtainPage(uint32(i))
 		if e != nil {
 			panic(e)
 		}
-		m.pageIndex.Add(p.No(), page.CalcPageFree(p))
+		if id := readHeapOwner(p); id != 0 {
+			h := m.heap(id, true)
+			h.pages = append(h.pages, p.No())
+			h.frees = append(h.frees, page.CalcPageFree(p))
+		} else {
+			m.pageIndex.Add(p.No(), page.CalcPageFree(p))
+		}
 		p.Release()
 	}
 
@@ -106,6 +122,7 @@ func NewManage(tm tx.Manage, opt *db.Option) Manage {
 	m.log = log.NewLog(opt)
 	m.pageIndex = page.NewIndex()
 	m.pageManage = page.NewManage(opt)
+	m.heaps = make(map[uint64]*heap)
 
 	m.cache = cache.NewCache(&cache.Option{
 		Obtain:   m.obtainForCache,
@@ -219,6 +236,68 @@ func (m *dataManage) Write(tid uint64, data []byte) (uint64, error) {
 	return wrapDataItemId(no, off), nil
 }
 
+// Free 释放数据对象
+//
+// 将数据对象标记为已释放，使用 tx.Super 记录日志（不受事务控制，调用方需要保证数据对象不再被引用）
+// 如果页面中的数据对象全部被释放，则重置页面，页面的空间可以通过 pageIndex 重新使用
+func (m *dataManage) Free(id uint64) error {
+	item, ok, err := m.Read(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	item.Before()
+	item.Data()[offFlag] = flagFree
+	item.After(tx.Super)
+	item.Release()
+
+	no, _ := parseDataItemId(id)
+	return m.reclaimPage(no)
+}
+
+// reclaimPage 重置数据对象全部被释放的页面
+func (m *dataManage) reclaimPage(no uint32) error {
+	// 正在写入的页面不在 pageIndex 中，此时不能重置
+	free, ok := m.pageIndex.Remove(no)
+	if !ok {
+		return nil
+	}
+
+	p, err := m.pageManage.ObtainPage(no)
+	if err != nil {
+		m.pageIndex.Add(no, free)
+		return err
+	}
+	defer func() {
+		m.pageIndex.Add(no, page.CalcPageFree(p))
+		p.Release()
+	}()
+
+	// 判断页面中的数据对象是否全部被释放
+	var (
+		data = p.Data()
+		off  = page.DataOffset()
+		fso  = page.ParsePageFSO(p)
+	)
+	if off == fso {
+		return nil
+	}
+	for off < fso {
+		if data[off+offFlag] != flagFree {
+			return nil
+		}
+		off += offData + readDataItemSize(data[off+offSize:])
+	}
+
+	// 保存日志，重置页面
+	m.log.Log(wrapResetLog(tx.Super, no))
+	page.ResetPage(p)
+	return nil
+}
+
 func (m *dataManage) LogDataItem(tid uint64, item Item) {
 	// 包装 update log 数据
 	data := wrapUpdateLog(tid, item)
@@ -49,6 +49,29 @@ func (m *mockManage) Write(_ uint64, data []byte) (uint64, error) {
 	return id, nil
 }
 
+func (m *mockManage) Free(id uint64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.cache, id)
+	return nil
+}
+
+func (m *mockManage) NewHeap() (uint64, error) {
+	return 0, nil
+}
+
+func (m *mockManage) WriteHeap(tid uint64, _ uint64, data []byte) (uint64, error) {
+	return m.Write(tid, data)
+}
+
+func (m *mockManage) ScanHeap(_ uint64, _ int) ([]uint64, bool, error) {
+	return nil, false, nil
+}
+
+func (m *mockManage) DropHeap(_ uint64) error {
+	return nil
+}
+
 func (m *mockManage) LogDataItem(tid uint64, item Item) {
 	println(tid, item)
 }
@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -162,7 +163,7 @@ func TestDataManage_DataHandleAsync(t *testing.T) {
 				if !bytes.Equal(data0.DataBody(), data1.DataBody()) {
 					t.Logf("%+v", data0.DataBody())
 					t.Logf("%+v", data1.DataBody())
-					t.Fatalf("check error data not equal")
+					t.Errorf("check error data not equal")
 				}
 				data0.RUnlock()
 				data1.RUnlock()
@@ -187,3 +188,97 @@ func TestDataManage_DataHandleAsync(t *testing.T) {
 	}
 	waitGroup.Wait()
 }
+
+func TestDataManage_Heap(t *testing.T) {
+	path := filepath.Join(db.RunPath(), "temp/data")
+	err := os.RemoveAll(path)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	opt := newOpt()
+
+	tm := tx.NewManager(opt)
+	dm := NewManage(tm, opt)
+
+	// 堆中的数据和普通数据交替写入
+	heap, err := dm.NewHeap()
+	if err != nil {
+		t.Fatalf("new heap err %v", err)
+	}
+	ids := make([]uint64, 0)
+	others := make(map[uint32]bool)
+	for i := 0; i < 200; i++ {
+		id, e := dm.WriteHeap(tx.Super, heap, randB(100))
+		if e != nil {
+			t.Fatalf("write heap err %v", e)
+		}
+		ids = append(ids, id)
+
+		id, e = dm.Write(tx.Super, randB(100))
+		if e != nil {
+			t.Fatalf("write err %v", e)
+		}
+		others[uint32(id>>16)] = true
+	}
+	for _, id := range ids {
+		if others[uint32(id>>16)] {
+			t.Fatalf("page %d is shared", id>>16)
+		}
+	}
+
+	// 释放的数据不会被遍历
+	err = dm.Free(ids[0])
+	if err != nil {
+		t.Fatalf("free err %v", err)
+	}
+	ids = ids[1:]
+
+	scan := func() {
+		got := make([]uint64, 0)
+		for i := 0; ; i++ {
+			page, ok, e := dm.ScanHeap(heap, i)
+			if e != nil {
+				t.Fatalf("scan err %v", e)
+			}
+			if !ok {
+				break
+			}
+			got = append(got, page...)
+		}
+		if !slices.Equal(got, ids) {
+			t.Fatalf("scan got %d ids, want %d", len(got), len(ids))
+		}
+	}
+	scan()
+
+	// 重新打开后，仍然可以遍历堆中的数据
+	dm.Close()
+	tm.Close()
+	opt = newOpt()
+	tm = tx.NewManager(opt)
+	dm = NewManage(tm, opt)
+	scan()
+
+	// 删除堆之后，页面被普通数据使用
+	pages := make(map[uint32]bool)
+	for _, id := range ids {
+		pages[uint32(id>>16)] = true
+	}
+	err = dm.DropHeap(heap)
+	if err != nil {
+		t.Fatalf("drop heap err %v", err)
+	}
+	reused := false
+	for i := 0; i < 200 && !reused; i++ {
+		id, e := dm.Write(tx.Super, randB(100))
+		if e != nil {
+			t.Fatalf("write err %v", e)
+		}
+		reused = pages[uint32(id>>16)]
+	}
+	if !reused {
+		t.Fatalf("heap pages not reused")
+	}
+	dm.Close()
+	tm.Close()
+}
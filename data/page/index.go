@@ -26,6 +26,8 @@ const (
 type Index interface {
 	Add(no, free uint32)
 	Select(free uint32) (uint32, uint32)
+	Remove(no uint32) (uint32, bool)
+	SelectEmpty() (uint32, bool)
 }
 
 type pageIndex struct {
@@ -76,3 +78,38 @@ func (pi *pageIndex) Select(free uint32) (uint32, uint32) {
 	}
 	return 0, 0
 }
+
+// Remove 从链表中删除指定的页面，返回页面的空余空间
+// 页面不在链表中时（例如正在被写入），返回 false
+func (pi *pageIndex) Remove(no uint32) (uint32, bool) {
+	pi.Lock()
+	defer pi.Unlock()
+
+	for i := range pi.spaceList {
+		for e := pi.spaceList[i].Front(); e != nil; e = e.Next() {
+			item := e.Value.(*indexItem)
+			if item.no == no {
+				pi.spaceList[i].Remove(e)
+				return item.free, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// SelectEmpty 选择一个没有任何数据的页面，并从链表中删除
+func (pi *pageIndex) SelectEmpty() (uint32, bool) {
+	pi.Lock()
+	defer pi.Unlock()
+
+	free := MaxPageFree()
+	i := min(free/threshold, interval)
+	for e := pi.spaceList[i].Front(); e != nil; e = e.Next() {
+		item := e.Value.(*indexItem)
+		if item.free == free {
+			pi.spaceList[i].Remove(e)
+			return item.no, true
+		}
+	}
+	return 0, false
+}
@@ -8,3 +8,20 @@ func TestNewIndex(t *testing.T) {
 	pi := NewIndex()
 	t.Logf("%+v", pi)
 }
+
+func TestIndex_SelectEmpty(t *testing.T) {
+	pi := NewIndex()
+	pi.Add(2, MaxPageFree()-100)
+	if _, ok := pi.SelectEmpty(); ok {
+		t.Fatalf("select non-empty page")
+	}
+
+	pi.Add(3, MaxPageFree())
+	no, ok := pi.SelectEmpty()
+	if !ok || no != 3 {
+		t.Fatalf("select empty page %d %v", no, ok)
+	}
+	if _, ok = pi.Remove(3); ok {
+		t.Fatalf("page still in index")
+	}
+}
@@ -34,6 +34,11 @@ func NewPageX() []byte {
 	return data
 }
 
+// DataOffset 页面中第一个数据的偏移量
+func DataOffset() uint16 {
+	return headLen
+}
+
 func MaxPageFree() uint32 {
 	return Size - headLen
 }
@@ -69,3 +74,10 @@ func RecoverPageUpdate(p Page, off uint16, data []byte) {
 	p.SetDirty(true)
 	copy(p.Data()[off:], data)
 }
+
+// ResetPage 重置页面（页面中的数据全部被释放之后）
+func ResetPage(p Page) {
+	p.SetDirty(true)
+	clear(p.Data()[headLen:])
+	writePageOffset(p.Data(), headLen)
+}
@@ -1 +1,174 @@
 package data
+
+import (
+	"slices"
+
+	"github.com/ggymm/db/data/page"
+)
+
+// 数据恢复
+//
+// 数据库没有被正常关闭时（page1 校验失败），需要根据 DB.LOG 中的日志恢复数据
+//
+// 恢复的步骤如下：
+// 1. 扫描全部日志，找到日志中出现的最大页号，截断之后的页面
+// 2. 倒序撤销（undo）未提交事务（进行中或者已回滚）的日志
+// 3. 正序重做（redo）已提交事务的日志
+// 4. 将日志中仍处于进行中的事务标记为已回滚
+//
+// 为什么先 undo 再 redo？
+// 事务回滚之后，其他事务可以继续修改同一个数据对象（例如 entry 的 max）
+// 如果先 redo 再 undo，已提交事务的修改会被回滚事务的 old_data 覆盖
+//
+// 页面被重置之后（数据对象全部被释放），页面的空间会被重新使用
+// 此时重做重置日志会清空之前撤销的插入，因此需要在 redo 之后
+// 重新撤销页面最后一次重置之后的插入（将数据对象标记为非法，并且占用页面空间）
+
+// recoverData 执行恢复操作
+func recoverData(m *dataManage) {
+	var (
+		seq    int
+		maxNo  = uint32(1)
+		undos  = make([][]byte, 0)
+		redos  = make([][]byte, 0)
+		active = make(map[uint64]bool)
+
+		resets  = make(map[uint32]int) // 页面最后一次重置的日志序号
+		inserts = make([]undoInsert, 0)
+	)
+
+	// 扫描日志
+	m.log.Rewind()
+	for ; ; seq++ {
+		log, next := m.log.Next()
+		if !next {
+			break
+		}
+
+		var (
+			tid uint64
+			no  uint32
+		)
+		switch log[0] {
+		case InsertLog:
+			tid, no, _, _ = parseInsertLog(log)
+		case UpdateLog:
+			tid, no, _, _, _ = parseUpdateLog(log)
+		case ResetLog:
+			tid, no = parseResetLog(log)
+			resets[no] = seq
+		default:
+			continue
+		}
+		maxNo = max(maxNo, no)
+
+		if m.txManage.IsCommitted(tid) {
+			redos = append(redos, log)
+		} else {
+			undos = append(undos, log)
+			if log[0] == InsertLog {
+				inserts = append(inserts, undoInsert{seq: seq, no: no, log: log})
+			}
+			if m.txManage.IsActive(tid) {
+				active[tid] = true
+			}
+		}
+	}
+	m.log.Rewind()
+
+	// 截断页面
+	m.pageManage.PageTruncate(maxNo)
+
+	// 倒序 undo
+	slices.Reverse(undos)
+	for _, log := range undos {
+		recoverLog(m, log, undoLog)
+	}
+
+	// 正序 redo
+	for _, log := range redos {
+		recoverLog(m, log, redoLog)
+	}
+
+	// 重新撤销页面重置之后的插入
+	for _, ins := range inserts {
+		if r, ok := resets[ins.no]; ok && ins.seq > r {
+			recoverInsert(m, ins.log, undoLog)
+		}
+	}
+
+	// 标记事务为已回滚
+	for tid := range active {
+		m.txManage.Rollback(tid)
+	}
+}
+
+// undoInsert 需要撤销的插入日志
+type undoInsert struct {
+	seq int
+	no  uint32
+	log []byte
+}
+
+func recoverLog(m *dataManage, log []byte, flag int) {
+	switch log[0] {
+	case InsertLog:
+		recoverInsert(m, log, flag)
+	case UpdateLog:
+		recoverUpdate(m, log, flag)
+	case ResetLog:
+		recoverReset(m, log)
+	}
+}
+
+// recoverInsert 处理插入日志
+//
+// redo：重新写入数据对象
+// undo：将数据对象标记为非法
+func recoverInsert(m *dataManage, log []byte, flag int) {
+	_, no, off, data := parseInsertLog(log)
+	if flag == undoLog {
+		buf := make([]byte, len(data))
+		copy(buf, data)
+		buf[offFlag] = flagInvalid
+		data = buf
+	}
+
+	p, err := m.pageManage.ObtainPage(no)
+	if err != nil {
+		panic(err)
+	}
+	page.RecoverPageInsert(p, off, data)
+	p.Release()
+}
+
+// recoverUpdate 处理更新日志
+//
+// redo：将数据对象设置为 new_data
+// undo：将数据对象设置为 old_data
+func recoverUpdate(m *dataManage, log []byte, flag int) {
+	_, no, off, dataOld, dataNew := parseUpdateLog(log)
+	data := dataNew
+	if flag == undoLog {
+		data = dataOld
+	}
+
+	p, err := m.pageManage.ObtainPage(no)
+	if err != nil {
+		panic(err)
+	}
+	page.RecoverPageUpdate(p, off, data)
+	p.Release()
+}
+
+// recoverReset 处理重置日志（只会 redo）
+func recoverReset(m *dataManage, log []byte) {
+	_, no := parseResetLog(log)
+
+	p, err := m.pageManage.ObtainPage(no)
+	if err != nil {
+		panic(err)
+	}
+	page.ResetPage(p)
+	p.Release()
+}
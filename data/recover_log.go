@@ -22,6 +22,16 @@ import (
 // |    1 byte      |     8 byte     |     8 byte     |     * byte     |     * byte     |
 // +----------------+----------------+----------------+----------------+----------------+
 //
+// 重置日志，数据的结构如下：
+// +----------------+----------------+----------------+
+// |      type      |      tid       |     item_id    |
+// +----------------+----------------+----------------+
+// |     1 byte     |     8 byte     |     8 byte     |
+// +----------------+----------------+----------------+
+//
+// item_id 中的 offset 为 0，只使用 page 的编号
+// 重置日志只使用 tx.Super 记录，恢复时总是重做
+//
 // 如何保证 old_data 和 new_data 的长度相同？
 // 通过上层业务保证
 // 因为此时 data 表示的是数据表中的每一行数据，所以只需要保证数据字段的长度为固定值即可
@@ -35,6 +45,7 @@ const (
 
 	InsertLog = 1
 	UpdateLog = 2
+	ResetLog  = 3
 )
 
 func wrapInsertLog(tid uint64, p page.Page, data []byte) []byte {
@@ -107,3 +118,27 @@ func parseUpdateLog(log []byte) (uint64, uint32, uint16, []byte, []byte) {
 	dataNew := log[pos+dataLen : pos+dataLen*2]
 	return tid, no, off, dataOld, dataNew
 }
+
+func wrapResetLog(tid uint64, no uint32) []byte {
+	// type: 1; tid: 8; itemId: 8
+	log := make([]byte, typeLen+tidLen+itemIdLen)
+
+	pos := 0
+	log[pos] = ResetLog // type
+
+	pos += typeLen
+	bin.PutUint64(log[pos:], tid) // tid
+
+	pos += tidLen
+	writeDataItemId(log[pos:], wrapDataItemId(no, 0)) // item_id
+	return log
+}
+
+func parseResetLog(log []byte) (uint64, uint32) {
+	pos := typeLen
+	tid := bin.Uint64(log[pos:]) // tid
+
+	pos += tidLen
+	no, _ := parseDataItemId(readDataItemId(log[pos:])) // item_id
+	return tid, no
+}
@@ -0,0 +1,187 @@
+package data
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/ggymm/db"
+	"github.com/ggymm/db/tx"
+)
+
+func newRecoverOpt() *db.Option {
+	opt := db.NewOption(db.RunPath(), "temp/recover")
+	opt.Memory = (1 << 20) * 64
+	return opt
+}
+
+func TestDataManage_Recover(t *testing.T) {
+	opt := newRecoverOpt()
+	err := os.RemoveAll(opt.Path)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	opt = newRecoverOpt()
+
+	tm := tx.NewManager(opt)
+	dm := NewManage(tm, opt)
+
+	// 已提交事务插入的数据
+	data1 := randB(60)
+	tid1 := tm.Begin()
+	id1, err := dm.Write(tid1, data1)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	tm.Commit(tid1)
+
+	// 进行中事务插入的数据
+	data2 := randB(60)
+	tid2 := tm.Begin()
+	id2, err := dm.Write(tid2, data2)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+
+	// 进行中事务更新的数据（页面已经刷新到磁盘）
+	tid3 := tm.Begin()
+	item, ok, err := dm.Read(id1)
+	if err != nil || !ok {
+		t.Fatalf("read err %v %t", err, ok)
+	}
+	item.Before()
+	copy(item.DataBody(), randB(60))
+	item.After(tid3)
+	item.Release()
+
+	// 已提交事务插入并更新的数据（页面没有刷新到磁盘）
+	data4 := randB(60)
+	tid4 := tm.Begin()
+	id4, err := dm.Write(tid4, randB(60))
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	item, ok, err = dm.Read(id4)
+	if err != nil || !ok {
+		t.Fatalf("read err %v %t", err, ok)
+	}
+	item.Before()
+	copy(item.DataBody(), data4)
+	item.After(tid4)
+	tm.Commit(tid4)
+
+	// 模拟崩溃（不关闭数据管理器），重新打开数据库
+	opt = newRecoverOpt()
+	if !opt.Open {
+		t.Fatalf("database not exist")
+	}
+	tm = tx.NewManager(opt)
+	dm = NewManage(tm, opt)
+
+	check := func(id uint64, want []byte) {
+		it, exist, e := dm.Read(id)
+		if e != nil {
+			t.Fatalf("read err %v", e)
+		}
+		if want == nil {
+			if exist {
+				t.Fatalf("item %d should be invalid", id)
+			}
+			return
+		}
+		if !exist {
+			t.Fatalf("item %d not exist", id)
+		}
+		if !bytes.Equal(it.DataBody(), want) {
+			t.Fatalf("item %d data not equal", id)
+		}
+		it.Release()
+	}
+	check(id1, data1)
+	check(id2, nil)
+	check(id4, data4)
+
+	if !tm.IsRolledBack(tid2) || !tm.IsRolledBack(tid3) {
+		t.Fatalf("active transaction should be rolled back")
+	}
+	dm.Close()
+	tm.Close()
+}
+
+func TestDataManage_RecoverFree(t *testing.T) {
+	opt := newRecoverOpt()
+	err := os.RemoveAll(opt.Path)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	opt = newRecoverOpt()
+
+	tm := tx.NewManager(opt)
+	dm := NewManage(tm, opt)
+
+	// 释放页面中的全部数据，页面被重置
+	tid1 := tm.Begin()
+	id1, err := dm.Write(tid1, randB(200))
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	id2, err := dm.Write(tid1, randB(200))
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	tm.Commit(tid1)
+	for _, id := range []uint64{id1, id2} {
+		err = dm.Free(id)
+		if err != nil {
+			t.Fatalf("free err %v", err)
+		}
+	}
+
+	// 重新使用页面的空间
+	data3 := randB(50)
+	tid3 := tm.Begin()
+	id3, err := dm.Write(tid3, data3)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	tm.Commit(tid3)
+	if id3 != id1 {
+		t.Fatalf("page not reused, id %d, want %d", id3, id1)
+	}
+
+	// 进行中事务插入的数据
+	tid4 := tm.Begin()
+	id4, err := dm.Write(tid4, randB(60))
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+
+	// 模拟崩溃（不关闭数据管理器），重新打开数据库
+	opt = newRecoverOpt()
+	tm = tx.NewManager(opt)
+	dm = NewManage(tm, opt)
+
+	item, ok, err := dm.Read(id3)
+	if err != nil || !ok || !bytes.Equal(item.DataBody(), data3) {
+		t.Fatalf("read err %v %t", err, ok)
+	}
+	item.Release()
+	_, ok, err = dm.Read(id4)
+	if err != nil || ok {
+		t.Fatalf("item %d should be invalid, err %v", id4, err)
+	}
+
+	// 回滚的数据仍然占用页面空间
+	no4, off4 := parseDataItemId(id4)
+	tid5 := tm.Begin()
+	id5, err := dm.Write(tid5, randB(10))
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	tm.Commit(tid5)
+	if no5, off5 := parseDataItemId(id5); no5 == no4 && off5 <= off4 {
+		t.Fatalf("item %d overwrites rolled back item %d", id5, id4)
+	}
+	dm.Close()
+	tm.Close()
+}
@@ -0,0 +1,250 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	sqlparser "github.com/ggymm/db/pkg/sql"
+)
+
+var (
+	ErrTxStmt        = errors.New("use the database/sql transaction api instead of BEGIN/COMMIT/ROLLBACK")
+	ErrInTransaction = errors.New("already in transaction")
+	ErrInvalidLevel  = errors.New("unsupported isolation level")
+	ErrReadOnly      = errors.New("read only transaction is not supported")
+	ErrUnsupported   = errors.New("unsupported statement")
+	ErrExecQuery     = errors.New("use Query instead of Exec for SELECT statements")
+	ErrNoInsertId    = errors.New("last insert id is not supported")
+)
+
+// conn 数据库连接
+//
+// 没有开启事务时，每条语句都在一个独立的事务中执行（自动提交，可重复读）
+type conn struct {
+	e *engine
+
+	tid  uint64
+	inTx bool
+}
+
+func newConn(e *engine) *conn {
+	return &conn{
+		e: e,
+	}
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	p, err := sqlparser.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	switch p.Statement().StmtType() {
+	case sqlparser.Begin, sqlparser.Commit, sqlparser.Rollback:
+		return nil, ErrTxStmt
+	}
+	return &stmtWrap{
+		c: c,
+		p: p,
+	}, nil
+}
+
+// Close 关闭连接，回滚未提交的事务
+func (c *conn) Close() error {
+	if c.inTx {
+		c.e.tbm.Rollback(c.tid)
+		c.inTx = false
+	}
+	c.e.release()
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx 开启事务
+//
+// 隔离级别：
+// sql.LevelDefault、sql.LevelRepeatableRead：可重复读（ver 中的级别 1）
+// sql.LevelReadCommitted：读已提交（ver 中的级别 0）
+func (c *conn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.inTx {
+		return nil, ErrInTransaction
+	}
+	if opts.ReadOnly {
+		return nil, ErrReadOnly
+	}
+
+	var level int
+	switch sql.IsolationLevel(opts.Isolation) {
+	case sql.LevelDefault, sql.LevelRepeatableRead:
+		level = 1
+	case sql.LevelReadCommitted:
+		level = 0
+	default:
+		return nil, ErrInvalidLevel
+	}
+
+	c.tid = c.e.tbm.Begin(level)
+	c.inTx = true
+	return &txWrap{c: c}, nil
+}
+
+// run 在当前事务中执行函数，没有开启事务时自动提交
+func (c *conn) run(fn func(tid uint64) error) error {
+	if c.inTx {
+		return fn(c.tid)
+	}
+
+	tbm := c.e.tbm
+	tid := tbm.Begin(1)
+	err := fn(tid)
+	if err != nil {
+		tbm.Rollback(tid)
+		return err
+	}
+	err = tbm.Commit(tid)
+	if err != nil {
+		tbm.Rollback(tid)
+		return err
+	}
+	return nil
+}
+
+type txWrap struct {
+	c *conn
+}
+
+func (t *txWrap) Commit() error {
+	c := t.c
+	if !c.inTx {
+		return sql.ErrTxDone
+	}
+	c.inTx = false
+
+	err := c.e.tbm.Commit(c.tid)
+	if err != nil {
+		c.e.tbm.Rollback(c.tid)
+		return err
+	}
+	return nil
+}
+
+func (t *txWrap) Rollback() error {
+	c := t.c
+	if !c.inTx {
+		return sql.ErrTxDone
+	}
+	c.inTx = false
+
+	c.e.tbm.Rollback(c.tid)
+	return nil
+}
+
+// stmtWrap 预备语句，参数在执行时绑定
+type stmtWrap struct {
+	c *conn
+	p *sqlparser.Prepared
+}
+
+func (s *stmtWrap) Close() error {
+	return nil
+}
+
+func (s *stmtWrap) NumInput() int {
+	return s.p.NumParams()
+}
+
+func (s *stmtWrap) bind(args []driver.Value) (sqlparser.Statement, error) {
+	vals := make([]any, 0, len(args))
+	for _, arg := range args {
+		vals = append(vals, arg)
+	}
+	return s.p.Bind(vals...)
+}
+
+func (s *stmtWrap) Exec(args []driver.Value) (driver.Result, error) {
+	stmt, err := s.bind(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var n int
+	err = s.c.run(func(tid uint64) (err error) {
+		tbm := s.c.e.tbm
+		switch stmt.StmtType() {
+		case sqlparser.Create:
+			err = tbm.Create(tid, stmt.(*sqlparser.CreateStmt))
+		case sqlparser.Alter:
+			err = tbm.Alter(tid, stmt.(*sqlparser.AlterStmt))
+		case sqlparser.Drop:
+			err = tbm.Drop(tid, stmt.(*sqlparser.DropStmt))
+		case sqlparser.Truncate:
+			err = tbm.Truncate(tid, stmt.(*sqlparser.TruncateStmt))
+		case sqlparser.IndexCreate:
+			err = tbm.CreateIndex(tid, stmt.(*sqlparser.CreateIndexStmt))
+		case sqlparser.IndexDrop:
+			err = tbm.DropIndex(tid, stmt.(*sqlparser.DropIndexStmt))
+		case sqlparser.Analyze:
+			err = tbm.Analyze(tid, stmt.(*sqlparser.AnalyzeStmt))
+		case sqlparser.Insert:
+			n, err = tbm.Insert(tid, stmt.(*sqlparser.InsertStmt))
+		case sqlparser.Update:
+			n, err = tbm.Update(tid, stmt.(*sqlparser.UpdateStmt))
+		case sqlparser.Delete:
+			n, err = tbm.Delete(tid, stmt.(*sqlparser.DeleteStmt))
+		case sqlparser.Select:
+			err = ErrExecQuery
+		default:
+			err = ErrUnsupported
+		}
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result(n), nil
+}
+
+func (s *stmtWrap) Query(args []driver.Value) (driver.Rows, error) {
+	if s.p.Statement().StmtType() != sqlparser.Select {
+		_, err := s.Exec(args)
+		if err != nil {
+			return nil, err
+		}
+		return &rows{}, nil
+	}
+
+	stmt, err := s.bind(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// 字段信息和数据使用相同的表结构（其他事务正在修改表结构时返回错误）
+	r := new(rows)
+	err = s.c.run(func(tid uint64) (err error) {
+		r.cols, r.entries, err = s.c.e.tbm.Query(tid, stmt.(*sqlparser.SelectStmt))
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// result 执行结果，影响的行数
+type result int
+
+func (r result) LastInsertId() (int64, error) {
+	return 0, ErrNoInsertId
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return int64(r), nil
+}
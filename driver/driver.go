@@ -0,0 +1,135 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ggymm/db"
+	"github.com/ggymm/db/boot"
+	"github.com/ggymm/db/data"
+	"github.com/ggymm/db/table"
+	"github.com/ggymm/db/tx"
+	"github.com/ggymm/db/ver"
+)
+
+// database/sql 驱动
+//
+// 使用方式：
+// sql.Open("ggymm", "path/to/db")
+// sql.Open("ggymm", "path/to/db?memory=67108864")
+//
+// path: 数据库目录，目录不存在或者为空时创建数据库
+// memory: 缓存使用的内存大小（字节），默认 64MB
+//
+// 同一个目录只会打开一次，所有连接共享同一个 table.Manage
+// 最后一个连接关闭时，同步数据到磁盘
+
+const (
+	Name = "ggymm"
+
+	defaultMemory = (1 << 20) * 64
+)
+
+func init() {
+	sql.Register(Name, &Driver{})
+}
+
+var (
+	mu      sync.Mutex
+	engines = make(map[string]*engine)
+)
+
+// engine 打开的数据库
+type engine struct {
+	path string
+	refs int
+
+	tm  tx.Manage
+	dm  data.Manage
+	tbm table.Manage
+}
+
+func openEngine(dsn string) (*engine, error) {
+	path, query, _ := strings.Cut(dsn, "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	memory := int64(defaultMemory)
+	if v := params.Get("memory"); v != "" {
+		memory, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	e, ok := engines[path]
+	if !ok {
+		opt := db.NewOption(path)
+		opt.Memory = memory
+
+		e = &engine{path: path}
+		e.tm = tx.NewManager(opt)
+		e.dm = data.NewManage(e.tm, opt)
+		e.tbm = table.NewManage(boot.New(opt), ver.NewManage(e.tm, e.dm), e.dm)
+		engines[path] = e
+	}
+	e.refs++
+	return e, nil
+}
+
+func (e *engine) release() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	e.refs--
+	if e.refs == 0 {
+		delete(engines, e.path)
+		e.dm.Close()
+		e.tm.Close()
+	}
+}
+
+// Driver 实现 driver.Driver 和 driver.DriverContext
+type Driver struct {
+}
+
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	c, err := d.OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect(context.Background())
+}
+
+func (d *Driver) OpenConnector(dsn string) (driver.Connector, error) {
+	return &connector{
+		dsn:    dsn,
+		driver: d,
+	}, nil
+}
+
+type connector struct {
+	dsn    string
+	driver *Driver
+}
+
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	e, err := openEngine(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return newConn(e), nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
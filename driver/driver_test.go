@@ -0,0 +1,249 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ggymm/db"
+)
+
+func openDB(t *testing.T, name string) *sql.DB {
+	path := filepath.Join(db.RunPath(), "temp/driver", name)
+	err := os.RemoveAll(path)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+
+	conn, err := sql.Open(Name, path)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	_, err = conn.Exec("CREATE TABLE user (id INT64, age INT32, name VARCHAR, PRIMARY KEY (id));")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	return conn
+}
+
+func TestDriver_Query(t *testing.T) {
+	conn := openDB(t, "query")
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	for _, s := range []string{
+		"INSERT INTO user (id, age, name) VALUE (1, 18, 'a');",
+		"INSERT INTO user (id, age, name) VALUE (2, 20, 'b');",
+		"INSERT INTO user (id, age, name) VALUE (3, 22, 'c');",
+	} {
+		res, err := conn.Exec(s)
+		if err != nil {
+			t.Fatalf("err %v", err)
+		}
+		n, _ := res.RowsAffected()
+		if n != 1 {
+			t.Fatalf("affected %d", n)
+		}
+	}
+
+	rows, err := conn.Query("SELECT * FROM user WHERE id >= 2;")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	if types[1].DatabaseTypeName() != "INT32" || types[2].DatabaseTypeName() != "VARCHAR" {
+		t.Fatalf("types %s %s", types[1].DatabaseTypeName(), types[2].DatabaseTypeName())
+	}
+
+	count := 0
+	for rows.Next() {
+		var (
+			id   int64
+			age  int
+			name string
+		)
+		err = rows.Scan(&id, &age, &name)
+		if err != nil {
+			t.Fatalf("err %v", err)
+		}
+		if id < 2 || age != 16+int(id)*2 {
+			t.Fatalf("row %d %d %s", id, age, name)
+		}
+		count++
+	}
+	if rows.Err() != nil || count != 2 {
+		t.Fatalf("count %d err %v", count, rows.Err())
+	}
+
+	var name string
+	err = conn.QueryRow("SELECT * FROM user WHERE id = 1;").Scan(new(int64), new(int), &name)
+	if err != nil || name != "a" {
+		t.Fatalf("name %s err %v", name, err)
+	}
+}
+
+func TestDriver_Transaction(t *testing.T) {
+	conn := openDB(t, "tx")
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// 回滚
+	tx, err := conn.Begin()
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	_, err = tx.Exec("INSERT INTO user (id, age, name) VALUE (1, 18, 'a');")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	err = conn.QueryRow("SELECT * FROM user WHERE id = 1;").Scan(new(int64), new(int), new(string))
+	if err != sql.ErrNoRows {
+		t.Fatalf("err %v", err)
+	}
+
+	// 提交
+	tx, err = conn.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelReadCommitted})
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	_, err = tx.Exec("INSERT INTO user (id, age, name) VALUE (2, 20, 'b');")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	var name string
+	err = conn.QueryRow("SELECT * FROM user WHERE id = 2;").Scan(new(int64), new(int), &name)
+	if err != nil || name != "b" {
+		t.Fatalf("name %s err %v", name, err)
+	}
+
+	_, err = conn.BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err == nil {
+		t.Fatalf("serializable should not be supported")
+	}
+	_, err = conn.Exec("BEGIN;")
+	if err == nil {
+		t.Fatalf("begin statement should not be supported")
+	}
+}
+
+func TestDriver_Prepare(t *testing.T) {
+	conn := openDB(t, "prepare")
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	stmt, err := conn.Prepare("INSERT INTO user (id, age, name) VALUE (?, ?, ?);")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	for i := 1; i <= 5; i++ {
+		_, err = stmt.Exec(i, 10+i, "it's "+string(rune('a'+i)))
+		if err != nil {
+			t.Fatalf("err %v", err)
+		}
+	}
+	_ = stmt.Close()
+
+	var (
+		age  int
+		name string
+	)
+	err = conn.QueryRow("SELECT * FROM user WHERE id = $1;", 3).Scan(new(int64), &age, &name)
+	if err != nil || age != 13 || name != "it's d" {
+		t.Fatalf("age %d name %s err %v", age, name, err)
+	}
+
+	res, err := conn.Exec("DELETE FROM user WHERE id > ? AND id <= ?;", 1, 4)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	n, _ := res.RowsAffected()
+	if n != 3 {
+		t.Fatalf("affected %d", n)
+	}
+
+	_, err = conn.Exec("DELETE FROM user WHERE id = ?;")
+	if err == nil {
+		t.Fatalf("missing argument should fail")
+	}
+
+	// 查询语句需要使用 Query 执行
+	_, err = conn.Exec("SELECT * FROM user WHERE id = ?;", 1)
+	if !errors.Is(err, ErrExecQuery) {
+		t.Fatalf("exec select err %v", err)
+	}
+}
+
+func TestDriver_Types(t *testing.T) {
+	conn := openDB(t, "types")
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	_, err := conn.Exec("CREATE TABLE item (id INT64, ok BOOL, score DOUBLE, price DECIMAL(10,2), created TIMESTAMP, PRIMARY KEY (id));")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+
+	created := time.Date(2024, 5, 6, 7, 8, 9, 123456000, time.UTC)
+	_, err = conn.Exec("INSERT INTO item (id, ok, score, price, created) VALUE (?, ?, ?, ?, ?);", -7, true, -0.25, "12.34", created)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+
+	var (
+		id    int64
+		ok    bool
+		score float64
+		price string
+		ts    time.Time
+	)
+	err = conn.QueryRow("SELECT * FROM item WHERE id = ?;", -7).Scan(&id, &ok, &score, &price, &ts)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	if id != -7 || !ok || score != -0.25 || price != "12.34" || !ts.Equal(created) {
+		t.Fatalf("row %d %t %g %s %s", id, ok, score, price, ts)
+	}
+}
+
+func TestDriver_Null(t *testing.T) {
+	conn := openDB(t, "null")
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	_, err := conn.Exec("INSERT INTO user (id, age, name) VALUE (?, ?, ?);", 1, nil, "")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+
+	var (
+		age  sql.NullInt64
+		name sql.NullString
+	)
+	err = conn.QueryRow("SELECT * FROM user WHERE age IS NULL;").Scan(new(int64), &age, &name)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	if age.Valid || !name.Valid || name.String != "" {
+		t.Fatalf("age %v name %v", age, name)
+	}
+}
@@ -0,0 +1,94 @@
+package driver
+
+import (
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/ggymm/db/pkg/sql"
+	"github.com/ggymm/db/table"
+)
+
+// rows 查询结果
+//
+// 字段值的类型：
+// INT32、INT64：int64
+// BOOL：bool
+// DOUBLE：float64
+// DECIMAL：string（可以扫描到 float64 或者 string）
+// TIMESTAMP、DATE：time.Time（UTC）
+// VARCHAR：string
+type rows struct {
+	pos     int
+	cols    []*table.Column
+	entries []table.Entry
+}
+
+func (r *rows) Columns() []string {
+	names := make([]string, 0, len(r.cols))
+	for _, col := range r.cols {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+func (r *rows) Close() error {
+	r.pos = len(r.entries)
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.entries) {
+		return io.EOF
+	}
+	ent := r.entries[r.pos]
+	r.pos++
+
+	for i, col := range r.cols {
+		dest[i] = value(ent[col.Name])
+	}
+	return nil
+}
+
+// ColumnTypeDatabaseTypeName 字段的类型名称（INT32、DECIMAL(10,2)、VARCHAR 等）
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.cols[index].Type
+}
+
+func (r *rows) ColumnTypeScanType(index int) reflect.Type {
+	ct, _ := sql.ParseColumnType(r.cols[index].Type)
+	switch ct.Type {
+	case sql.Int32, sql.Int64:
+		return reflect.TypeOf(int64(0))
+	case sql.Bool:
+		return reflect.TypeOf(false)
+	case sql.Double:
+		return reflect.TypeOf(float64(0))
+	case sql.Decimal, sql.Varchar:
+		return reflect.TypeOf("")
+	case sql.Timestamp, sql.Date:
+		return reflect.TypeOf(time.Time{})
+	}
+	return reflect.TypeOf(new(any)).Elem()
+}
+
+// ColumnTypePrecisionScale DECIMAL 字段的精度
+func (r *rows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	ct, _ := sql.ParseColumnType(r.cols[index].Type)
+	if ct.Type != sql.Decimal {
+		return 0, 0, false
+	}
+	return int64(ct.Precision), int64(ct.Scale), true
+}
+
+// value 将字段值转换为 driver.Value
+func value(v any) driver.Value {
+	switch val := v.(type) {
+	case int32:
+		return int64(val)
+	case sql.Dec:
+		return val.String()
+	}
+	return v
+}
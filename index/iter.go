@@ -0,0 +1,283 @@
+package index
+
+import (
+	"bytes"
+	"math"
+)
+
+// Iterator 索引迭代器
+//
+// 在 [lower, upper] 范围内按照 key（字段值编码后的键）的顺序遍历索引，相同的 key 按照 itemId 排序
+// 没有定位时，Next 从第一个数据开始，Prev 从最后一个数据开始
+//
+// 每次从一个叶子节点中读取一批数据，读取下一批数据时，根据当前的 key 重新查找叶子节点
+// 因此迭代器不持有节点的引用，可以在任意时刻停止，并且不会受到节点合并的影响
+type Iterator interface {
+	First() bool
+	Last() bool
+	Seek(key []byte) bool
+	Next() bool
+	Prev() bool
+
+	Key() []byte
+	ItemId() uint64
+	Err() error
+	Close()
+}
+
+// Bound 迭代器的边界
+//
+// Key 为 nil 时没有边界，Exclusive 为 true 时不包含边界
+type Bound struct {
+	Key       []byte
+	Exclusive bool
+}
+
+type entry struct {
+	key    []byte
+	itemId uint64
+}
+
+type iterator struct {
+	tree *tree
+	err  error
+
+	lower []byte // 包含边界的 key（与 itemId 组合之后的 key）
+	upper []byte
+
+	pos   int
+	buf   []entry
+	valid bool
+	moved bool // 是否已经定位
+}
+
+func (t *tree) Iterator(lower, upper Bound) Iterator {
+	it := &iterator{
+		tree:  t,
+		lower: wrapKey(MinKey(), 0),
+		upper: wrapKey(MaxKey(), math.MaxUint64),
+	}
+	if lower.Key != nil {
+		if lower.Exclusive {
+			it.lower = wrapKey(lower.Key, math.MaxUint64)
+		} else {
+			it.lower = wrapKey(lower.Key, 0)
+		}
+	}
+	if upper.Key != nil {
+		if upper.Exclusive {
+			it.upper = wrapKey(upper.Key, 0)
+		} else {
+			it.upper = wrapKey(upper.Key, math.MaxUint64)
+		}
+	}
+	return it
+}
+
+// load 加载数据，并检查当前数据是否在范围内
+func (it *iterator) load(buf []entry, err error, pos int) bool {
+	it.err = err
+	it.moved = true
+	it.buf = buf
+	it.pos = pos
+	it.valid = err == nil && len(buf) != 0
+	return it.check()
+}
+
+func (it *iterator) check() bool {
+	if it.valid {
+		key := it.buf[it.pos].key
+		if bytes.Compare(key, it.lower) < 0 || bytes.Compare(key, it.upper) > 0 {
+			it.valid = false
+		}
+	}
+	return it.valid
+}
+
+// First 定位到范围内的第一个数据
+func (it *iterator) First() bool {
+	buf, err := it.tree.seekNext(it.lower, false)
+	return it.load(buf, err, 0)
+}
+
+// Last 定位到范围内的最后一个数据
+func (it *iterator) Last() bool {
+	buf, err := it.tree.seekPrev(it.upper, false)
+	return it.load(buf, err, len(buf)-1)
+}
+
+// Seek 定位到范围内第一个大于等于 key 的数据
+func (it *iterator) Seek(key []byte) bool {
+	full := wrapKey(key, 0)
+	if bytes.Compare(full, it.lower) < 0 {
+		return it.First()
+	}
+	buf, err := it.tree.seekNext(full, false)
+	return it.load(buf, err, 0)
+}
+
+func (it *iterator) Next() bool {
+	if !it.moved {
+		return it.First()
+	}
+	if !it.valid {
+		return false
+	}
+	if it.pos+1 < len(it.buf) {
+		it.pos++
+		return it.check()
+	}
+	buf, err := it.tree.seekNext(it.buf[it.pos].key, true)
+	return it.load(buf, err, 0)
+}
+
+func (it *iterator) Prev() bool {
+	if !it.moved {
+		return it.Last()
+	}
+	if !it.valid {
+		return false
+	}
+	if it.pos > 0 {
+		it.pos--
+		return it.check()
+	}
+	buf, err := it.tree.seekPrev(it.buf[it.pos].key, true)
+	return it.load(buf, err, len(buf)-1)
+}
+
+// Key 当前数据的 key（截断或者填充到 KeyLen）
+func (it *iterator) Key() []byte {
+	return it.buf[it.pos].key[:KeyLen]
+}
+
+func (it *iterator) ItemId() uint64 {
+	return it.buf[it.pos].itemId
+}
+
+func (it *iterator) Err() error {
+	return it.err
+}
+
+func (it *iterator) Close() {
+	it.buf = nil
+	it.valid = false
+	it.moved = true
+}
+
+// readEntries 读取叶子节点中满足条件的数据
+func readEntries(nd *node, match func(key []byte) bool) ([]entry, uint64) {
+	nd.item.RLock()
+	defer nd.item.RUnlock()
+
+	buf := make([]entry, 0)
+	num := getKeysNum(nd.data)
+	for i := 0; i < num; i++ {
+		key := getKey(nd.data, i)
+		if match(key) {
+			buf = append(buf, entry{
+				key:    bytes.Clone(key),
+				itemId: getChild(nd.data, i),
+			})
+		}
+	}
+	return buf, getSibling(nd.data)
+}
+
+// seekNext
+// 查找第一个大于等于 key（strict 为 true 时大于 key）的数据所在的叶子节点
+// 返回叶子节点中从该数据开始的全部数据
+func (t *tree) seekNext(key []byte, strict bool) ([]entry, error) {
+	t.latch.RLock()
+	defer t.latch.RUnlock()
+
+	nodeId, err := t.search(t.rootId(), key)
+	if err != nil {
+		return nil, err
+	}
+	match := func(k []byte) bool {
+		c := bytes.Compare(k, key)
+		return c > 0 || (c == 0 && !strict)
+	}
+	for nodeId != 0 {
+		nd, err := wrapNode(t, nodeId)
+		if err != nil {
+			return nil, err
+		}
+		buf, sibling := readEntries(nd, match)
+
+		// 释放 node 引用
+		release(nd)
+
+		if len(buf) != 0 {
+			return buf, nil
+		}
+		nodeId = sibling
+	}
+	return nil, nil
+}
+
+// seekPrev
+// 查找最后一个小于等于 key（strict 为 true 时小于 key）的数据所在的叶子节点
+// 返回叶子节点中到该数据为止的全部数据
+func (t *tree) seekPrev(key []byte, strict bool) ([]entry, error) {
+	t.latch.RLock()
+	defer t.latch.RUnlock()
+
+	match := func(k []byte) bool {
+		c := bytes.Compare(k, key)
+		return c < 0 || (c == 0 && !strict)
+	}
+	return t.prev(t.rootId(), key, match, true)
+}
+
+// prev
+// 从右向左查找子节点，直到找到满足条件的数据
+//
+// 非叶子节点分裂之后，左侧节点的最后一个 key 不是 infKey，大于等于该 key 的数据在兄弟节点中
+// follow 为 true 时（查找路径上的节点），需要先查找兄弟节点
+func (t *tree) prev(nodeId uint64, key []byte, match func([]byte) bool, follow bool) ([]entry, error) {
+	nd, err := wrapNode(t, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	if getLeaf(nd.data) {
+		buf, _ := readEntries(nd, match)
+		release(nd)
+		return buf, nil
+	}
+
+	// 复制子节点信息
+	nd.item.RLock()
+	num := getKeysNum(nd.data)
+	sibling := getSibling(nd.data)
+	children := make([]uint64, 0, num)
+	for j := 0; j < num; j++ {
+		children = append(children, getChild(nd.data, j))
+	}
+	i := 0
+	for i < num && bytes.Compare(key, getKey(nd.data, i)) >= 0 {
+		i++
+	}
+	nd.item.RUnlock()
+	release(nd)
+
+	// key 大于等于全部的 key 时，子节点中的数据都满足条件
+	routed := i < num
+	if !routed {
+		if follow && sibling != 0 {
+			buf, err := t.prev(sibling, key, match, true)
+			if err != nil || len(buf) != 0 {
+				return buf, err
+			}
+		}
+		i = num - 1
+	}
+	for j := i; j >= 0; j-- {
+		buf, err := t.prev(children[j], key, match, follow && routed && j == i)
+		if err != nil || len(buf) != 0 {
+			return buf, err
+		}
+	}
+	return nil, nil
+}
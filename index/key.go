@@ -0,0 +1,153 @@
+package index
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// 索引键
+//
+// 索引键是保持顺序的字节数组，使用 bytes.Compare 比较大小
+// 字段值按照以下规则编码：
+// 无符号整数：大端序
+// 有符号整数：符号位取反后的大端序（负数小于正数）
+// 浮点数：正数符号位取反，负数全部位取反后的大端序
+// 布尔值：false 为 0，true 为 1
+// 字符串：原始字节
+//
+// 节点中保存的键由两部分组成：
+// +----------------+----------------+
+// |      key       |     itemId     |
+// +----------------+----------------+
+// |    32 bytes    |     8 bytes    |
+// +----------------+----------------+
+//
+// key: 编码后的字段值，长度不足时使用 0 填充，超过时截断
+// itemId: 数据的 id，保证相同字段值的键也是唯一的
+//
+// 截断后的键只能保证 a < b => key(a) <= key(b)
+// 因此通过索引查询出的数据，需要使用完整的字段值再次比较
+//
+// 多列索引的键由每个字段编码后的值依次拼接（参考 EncodeComposite）
+// 每个部分之前有一个标记字节，NULL 为 0x00（小于任何值），其他为 0x01
+// 定长的部分直接拼接，变长的部分（字符串）需要转义，保证拼接之后仍然保持顺序
+
+const (
+	KeyLen = 32
+
+	fullKeyLen = KeyLen + 8
+)
+
+func EncodeUint32(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
+
+func EncodeUint64(v uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, v)
+}
+
+func EncodeInt32(v int32) []byte {
+	return EncodeUint32(uint32(v) ^ 1<<31)
+}
+
+func EncodeInt64(v int64) []byte {
+	return EncodeUint64(uint64(v) ^ 1<<63)
+}
+
+func EncodeFloat64(v float64) []byte {
+	if v == 0 {
+		v = 0 // -0 和 +0 相等
+	}
+	b := math.Float64bits(v)
+	if b>>63 == 1 {
+		b = ^b
+	} else {
+		b |= 1 << 63
+	}
+	return EncodeUint64(b)
+}
+
+func EncodeBool(v bool) []byte {
+	if v {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+func EncodeString(v string) []byte {
+	return []byte(v)
+}
+
+// EncodeVarPart 编码多列索引中变长的部分
+//
+// 0x00 转义为 0x00 0xff，结尾添加 0x00 0x01
+// 较短的值是较长的值的前缀时，结尾的 0x00 0x01 小于较长的值的后续字节，因此仍然保持顺序
+func EncodeVarPart(v []byte) []byte {
+	buf := make([]byte, 0, len(v)+2)
+	for _, b := range v {
+		buf = append(buf, b)
+		if b == 0 {
+			buf = append(buf, 0xff)
+		}
+	}
+	return append(buf, 0, 1)
+}
+
+// EncodePart 编码多列索引中非 NULL 的部分（变长的部分需要先使用 EncodeVarPart 编码）
+func EncodePart(v []byte) []byte {
+	return append([]byte{1}, v...)
+}
+
+// EncodeNullPart 编码多列索引中 NULL 的部分
+func EncodeNullPart() []byte {
+	return []byte{0}
+}
+
+// EncodeComposite 拼接多列索引的键（每个部分需要先使用 EncodePart 或者 EncodeNullPart 编码）
+func EncodeComposite(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// PrefixMax 以 prefix 为前缀的最大的索引键（多列索引的前缀查询使用）
+func PrefixMax(prefix []byte) []byte {
+	if len(prefix) >= KeyLen {
+		return prefix
+	}
+	return append(bytes.Clone(prefix), bytes.Repeat([]byte{0xff}, KeyLen-len(prefix))...)
+}
+
+// MinKey 最小的索引键
+func MinKey() []byte {
+	return make([]byte, KeyLen)
+}
+
+// MaxKey 最大的索引键
+func MaxKey() []byte {
+	return bytes.Repeat([]byte{0xff}, KeyLen)
+}
+
+// CompareKey 比较两个索引键（按照截断和填充后的结果比较）
+func CompareKey(a, b []byte) int {
+	return bytes.Compare(padKey(a), padKey(b))
+}
+
+// padKey 将字段值填充或截断为 KeyLen 长度
+func padKey(key []byte) []byte {
+	buf := make([]byte, KeyLen)
+	copy(buf, key)
+	return buf
+}
+
+// wrapKey 包装节点中保存的键
+func wrapKey(key []byte, itemId uint64) []byte {
+	buf := make([]byte, fullKeyLen)
+	copy(buf[:KeyLen], key)
+	binary.BigEndian.PutUint64(buf[KeyLen:], itemId)
+	return buf
+}
+
+// infKey 非叶子节点最右侧的键
+func infKey() []byte {
+	return bytes.Repeat([]byte{0xff}, fullKeyLen)
+}
@@ -0,0 +1,68 @@
+package index
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncodeKey_Order(t *testing.T) {
+	ints := []int64{math.MinInt64, -100, -1, 0, 1, 100, math.MaxInt64}
+	for i := 1; i < len(ints); i++ {
+		if CompareKey(EncodeInt64(ints[i-1]), EncodeInt64(ints[i])) >= 0 {
+			t.Fatalf("int64 %d >= %d", ints[i-1], ints[i])
+		}
+	}
+
+	int32s := []int32{math.MinInt32, -1, 0, 1, math.MaxInt32}
+	for i := 1; i < len(int32s); i++ {
+		if CompareKey(EncodeInt32(int32s[i-1]), EncodeInt32(int32s[i])) >= 0 {
+			t.Fatalf("int32 %d >= %d", int32s[i-1], int32s[i])
+		}
+	}
+
+	floats := []float64{math.Inf(-1), -1e10, -1.5, -math.SmallestNonzeroFloat64, 0, math.SmallestNonzeroFloat64, 1.5, 1e10, math.Inf(1)}
+	for i := 1; i < len(floats); i++ {
+		if CompareKey(EncodeFloat64(floats[i-1]), EncodeFloat64(floats[i])) >= 0 {
+			t.Fatalf("float64 %g >= %g", floats[i-1], floats[i])
+		}
+	}
+	if CompareKey(EncodeFloat64(math.Copysign(0, -1)), EncodeFloat64(0)) != 0 {
+		t.Fatalf("-0 != +0")
+	}
+	if CompareKey(EncodeBool(false), EncodeBool(true)) >= 0 {
+		t.Fatalf("false >= true")
+	}
+}
+
+func TestEncodeKey_Composite(t *testing.T) {
+	// (tenant, name) 按照 tenant 排序，tenant 相同时按照 name 排序
+	str := func(v string) []byte {
+		return EncodePart(EncodeVarPart(EncodeString(v)))
+	}
+	one := EncodePart(EncodeInt64(1))
+	keys := [][]byte{
+		EncodeComposite(one, EncodeNullPart()),
+		EncodeComposite(one, str("")),
+		EncodeComposite(one, str("a")),
+		EncodeComposite(one, str("a\x00")),
+		EncodeComposite(one, str("a\x00b")),
+		EncodeComposite(one, str("ab")),
+		EncodeComposite(EncodePart(EncodeInt64(2)), str("")),
+	}
+	for i := 1; i < len(keys); i++ {
+		if CompareKey(keys[i-1], keys[i]) >= 0 {
+			t.Fatalf("composite %x >= %x", keys[i-1], keys[i])
+		}
+	}
+
+	// 前缀范围包含全部以前缀开头的键
+	prefix := EncodeComposite(one)
+	for _, key := range keys[:6] {
+		if CompareKey(prefix, key) > 0 || CompareKey(key, PrefixMax(prefix)) > 0 {
+			t.Fatalf("prefix %x not contains %x", prefix, key)
+		}
+	}
+	if CompareKey(keys[6], PrefixMax(prefix)) <= 0 {
+		t.Fatalf("prefix %x contains %x", prefix, keys[6])
+	}
+}
@@ -1,7 +1,7 @@
 package index
 
 import (
-	"math"
+	"bytes"
 
 	"github.com/ggymm/db/data"
 	"github.com/ggymm/db/pkg/bin"
@@ -14,19 +14,19 @@ import (
 // +-------------------+-------------------+-------------------+-------------------+
 // |      isLeaf       |      keysNum      |      sibling      |   key and child   |
 // +-------------------+-------------------+-------------------+-------------------+
-// |      1 byte       |      2 bytes      |      8 bytes      | 16 bytes * 32 * 2 |
+// |      1 byte       |      2 bytes      |      8 bytes      | 48 bytes * 32 * 2 |
 // +-------------------+-------------------+-------------------+-------------------+
 //
 // isLeaf: 是否为叶子节点
 // keysNum: 节点中的 key 的数量
 // sibling: 兄弟节点的 id（itemId）（如果非叶子节点，则是子节点的 id）
 // keys
-//  key: 8 bytes（uint64）, 字段的 hash 值
+//  key: 40 bytes, 字段值编码后的键和数据的 id（参考 key.go）
 //  child: 8 bytes（uint64）, 数据的 id（itemId）（如果非叶子节点，则是子节点的 id）
 //
 // 特殊处理
 // 其他的 B+Tree 算法中，非叶子节点的会有一个指针指向最右边的子节点
-// 这里取消了指针，同时将 keyN 设置为 math.MaxUint64，childN 指向最右侧的子节点
+// 这里取消了指针，同时将 keyN 设置为 infKey（全部为 0xff），childN 指向最右侧的子节点
 //
 // 这样，非叶子节点和叶子节点的二进制结构保持一致
 // 执行查询操作时，也保持了和叶子节点一致的查询逻辑
@@ -37,9 +37,10 @@ const (
 	offSibling = offKeysNum + 2
 
 	headerLen  = 1 + 2 + 8
+	entryLen   = fullKeyLen + 8
 	balanceNum = 32
 
-	nodeSize = headerLen + (2*8)*(balanceNum*2+2)
+	nodeSize = headerLen + entryLen*(balanceNum*2+2)
 )
 
 type node struct {
@@ -79,35 +80,37 @@ func setSibling(data []byte, sibling uint64) {
 }
 
 func getOff(i int) int {
-	return headerLen + i*8*2
+	return headerLen + i*entryLen
 }
 
-func getKey(data []byte, i int) uint64 {
+func getKey(data []byte, i int) []byte {
 	off := getOff(i)
-	return bin.Uint64(data[off:])
+	return data[off : off+fullKeyLen]
 }
 
-func setKey(data []byte, i int, key uint64) {
+func setKey(data []byte, i int, key []byte) {
 	off := getOff(i)
-	bin.PutUint64(data[off:], key)
+	copy(data[off:off+fullKeyLen], key)
 }
 
 func getChild(data []byte, i int) uint64 {
-	off := getOff(i) + 8
+	off := getOff(i) + fullKeyLen
 	return bin.Uint64(data[off:])
 }
 
 func setChild(data []byte, i int, val uint64) {
-	off := getOff(i) + 8
+	off := getOff(i) + fullKeyLen
 	bin.PutUint64(data[off:], val)
 }
 
 func shiftData(data []byte, i int) {
-	start := getOff(i + 1)
-	length := nodeSize - 1
-	for n := length; n >= start; n-- {
-		data[n] = data[n-8*2]
-	}
+	start := getOff(i)
+	copy(data[start+entryLen:nodeSize], data[start:nodeSize-entryLen])
+}
+
+func unshiftData(data []byte, i int) {
+	start := getOff(i)
+	copy(data[start:nodeSize-entryLen], data[start+entryLen:nodeSize])
 }
 
 func writeInitData(i int, dst, src []byte) {
@@ -123,7 +126,7 @@ func initRoot() []byte {
 	return buf
 }
 
-func createRoot(key, prev, next uint64) []byte {
+func createRoot(key []byte, prev, next uint64) []byte {
 	buf := make([]byte, nodeSize)
 	setLeaf(buf, false) // 非叶子节点
 	setKeysNum(buf, 2)  // 相当于有两个子节点
@@ -134,7 +137,7 @@ func createRoot(key, prev, next uint64) []byte {
 	setChild(buf, 0, prev)
 
 	// 右节点
-	setKey(buf, 1, math.MaxUint64)
+	setKey(buf, 1, infKey())
 	setChild(buf, 1, next)
 	return buf
 }
@@ -169,7 +172,7 @@ func release(n *node) {
                   ↓                       ↓
    key0, child0, key60, child60, key60, child11, INF, child99
 */
-func (n *node) split() (uint64, uint64, error) {
+func (n *node) split() ([]byte, uint64, error) {
 	buf := make([]byte, nodeSize)
 
 	// 设置新节点的属性
@@ -181,7 +184,7 @@ func (n *node) split() (uint64, uint64, error) {
 	// 插入新节点
 	newChild, err := n.tree.DataManage.Write(tx.Super, buf)
 	if err != nil {
-		return 0, 0, err
+		return nil, 0, err
 	}
 
 	// 修改原节点属性
@@ -190,19 +193,23 @@ func (n *node) split() (uint64, uint64, error) {
 	return getKey(buf, 0), newChild, nil
 }
 
-func (n *node) insert(key, itemId uint64) bool {
+func (n *node) insert(key []byte, itemId uint64) bool {
 	// 遍历节点的 key，找到合适的位置
 	num := getKeysNum(n.data)
 	var i int
 	for i < num {
-		if key <= getKey(n.data, i) {
+		if bytes.Compare(key, getKey(n.data, i)) <= 0 {
 			break
 		}
 		i++
 	}
 	if i == num && getSibling(n.data) != 0 {
 		// 如果是最后一个节点，且有兄弟节点，则需要向兄弟节点插入
-		return false
+		// 叶子节点只有 key 不小于兄弟节点的第一个 key 时（兄弟节点是分裂产生的），才向兄弟节点插入
+		// 否则插入到兄弟节点中，会导致父节点中的分隔 key 与节点中的 key 不一致
+		if !getLeaf(n.data) || !n.beforeSibling(key) {
+			return false
+		}
 	}
 
 	if getLeaf(n.data) {
@@ -229,6 +236,19 @@ func (n *node) insert(key, itemId uint64) bool {
 	return true
 }
 
+// beforeSibling 判断 key 是否小于兄弟节点的第一个 key（兄弟节点为空时返回 true）
+func (n *node) beforeSibling(key []byte) bool {
+	sibling, err := wrapNode(n.tree, getSibling(n.data))
+	if err != nil || sibling == nil {
+		return false
+	}
+	defer release(sibling)
+
+	sibling.item.RLock()
+	defer sibling.item.RUnlock()
+	return getKeysNum(sibling.data) == 0 || bytes.Compare(key, getKey(sibling.data, 0)) < 0
+}
+
 func (n *node) IsLeaf() bool {
 	n.item.RLock()
 	defer n.item.RUnlock()
@@ -242,12 +262,12 @@ func (n *node) IsLeaf() bool {
 // sibling: 当前节点插入失败时，返回兄弟节点的 id
 // newKey: 当前节点分裂时，返回新节点的第一个 key
 // newChild: 当前节点分裂时，返回新节点的 itemId
-func (n *node) Insert(key, itemId uint64) (uint64, uint64, uint64, error) {
+func (n *node) Insert(key []byte, itemId uint64) (uint64, []byte, uint64, error) {
 	var (
 		err     error
 		success bool
 
-		newKey   uint64
+		newKey   []byte
 		newChild uint64
 	)
 
@@ -262,7 +282,7 @@ func (n *node) Insert(key, itemId uint64) (uint64, uint64, uint64, error) {
 
 	success = n.insert(key, itemId)
 	if !success {
-		return getSibling(n.data), 0, 0, nil
+		return getSibling(n.data), nil, 0, nil
 	}
 
 	// 检查是否需要分裂
@@ -272,19 +292,109 @@ func (n *node) Insert(key, itemId uint64) (uint64, uint64, uint64, error) {
 	return 0, newKey, newChild, err
 }
 
+// Delete 删除叶子节点中的数据
+//
+// 返回值：
+// bool: 是否删除成功
+// uint64: 当前节点中没有找到，并且 key 可能在兄弟节点中时，返回兄弟节点的 id
+func (n *node) Delete(key []byte) (bool, uint64) {
+	n.item.Before()
+
+	num := getKeysNum(n.data)
+	for i := 0; i < num; i++ {
+		c := bytes.Compare(key, getKey(n.data, i))
+		if c == 0 {
+			unshiftData(n.data, i)
+			setKeysNum(n.data, num-1)
+			n.item.After(tx.Super)
+			return true, 0
+		}
+		if c < 0 {
+			n.item.UnBefore()
+			return false, 0
+		}
+	}
+	n.item.UnBefore()
+	return false, getSibling(n.data)
+}
+
+// Underflow 判断节点的 key 数量是否过少（少于 balanceNum/2）
+func (n *node) Underflow() bool {
+	n.item.RLock()
+	defer n.item.RUnlock()
+
+	return getKeysNum(n.data) < balanceNum/2
+}
+
+// mergeNode 合并或者重新分配相邻的两个节点，返回是否合并
+//
+// left 和 right 是 parent 的第 i 和 i+1 个子节点
+// 非叶子节点的最后一个 key 是 infKey 时，合并时需要替换为父节点中的分隔 key
+// （分裂产生的左侧节点，最后一个 key 是实际的 key，大于等于该 key 的数据在右侧节点的第一个子节点中）
+/*
+   parent: ..., key_i, left, key_i+1, right, ...
+
+   合并：
+   parent: ..., key_i+1, left, ...
+   left: left entries, right entries
+
+   重新分配：
+   parent: ..., newKey, left, key_i+1, right, ...
+   left: entries[:half]
+   right: entries[half:]
+*/
+func mergeNode(parent, left, right []byte, i int) bool {
+	leaf := getLeaf(left)
+	entries := make([]byte, 0, nodeSize)
+	entries = append(entries, left[headerLen:getOff(getKeysNum(left))]...)
+	if !leaf && bytes.Equal(entries[len(entries)-entryLen:][:fullKeyLen], infKey()) {
+		copy(entries[len(entries)-entryLen:], getKey(parent, i))
+	}
+	entries = append(entries, right[headerLen:getOff(getKeysNum(right))]...)
+
+	total := len(entries) / entryLen
+	if total < balanceNum*2 {
+		copy(left[headerLen:], entries)
+		setKeysNum(left, total)
+		setSibling(left, getSibling(right))
+
+		// 删除父节点中的 right
+		num := getKeysNum(parent)
+		setKey(parent, i, getKey(parent, i+1))
+		unshiftData(parent, i+1)
+		setKeysNum(parent, num-1)
+		return true
+	}
+
+	half := total / 2
+	copy(left[headerLen:], entries[:half*entryLen])
+	setKeysNum(left, half)
+	copy(right[headerLen:], entries[half*entryLen:])
+	setKeysNum(right, total-half)
+
+	// 更新父节点中的分隔 key
+	if leaf {
+		setKey(parent, i, getKey(right, 0))
+	} else {
+		setKey(parent, i, getKey(left, half-1))
+		setKey(left, half-1, infKey())
+	}
+	return false
+}
+
 // Search 查找数据
 //
 // 返回值：
 // childId: 子节点的 id
 // siblingId: 兄弟节点的 id
-func (n *node) Search(key uint64) (uint64, uint64) {
+func (n *node) Search(key []byte) (uint64, uint64) {
 	n.item.RLock()
 	defer n.item.RUnlock()
 
 	nums := getKeysNum(n.data)
 	for i := 0; i < nums; i++ {
 		// 判断 key 是否小于当前节点的 key
-		if key < getKey(n.data, i) {
+		if bytes.Compare(key, getKey(n.data, i)) < 0 {
 			return getChild(n.data, i), 0
 		}
 	}
@@ -296,7 +406,7 @@ func (n *node) Search(key uint64) (uint64, uint64) {
 // 返回值：
 // []uint64: 满足条件的子节点 id
 // uint64: 兄弟节点的 id
-func (n *node) SearchRange(prevKey, nextKey uint64) ([]uint64, uint64) {
+func (n *node) SearchRange(prevKey, nextKey []byte) ([]uint64, uint64) {
 	n.item.RLock()
 	defer n.item.RUnlock()
 
@@ -305,7 +415,7 @@ func (n *node) SearchRange(prevKey, nextKey uint64) ([]uint64, uint64) {
 	var i int
 	// 查找大于等于 prevKey 的 key index
 	for i < num {
-		if prevKey <= getKey(n.data, i) {
+		if bytes.Compare(prevKey, getKey(n.data, i)) <= 0 {
 			break
 		}
 		i++
@@ -313,7 +423,7 @@ func (n *node) SearchRange(prevKey, nextKey uint64) ([]uint64, uint64) {
 	// 查找小于等于 nextKey 的 key index
 	// 将所有满足条件的 childId 加入到 res 中
 	for i < num {
-		if nextKey < getKey(n.data, i) {
+		if bytes.Compare(nextKey, getKey(n.data, i)) < 0 {
 			break
 		}
 		res = append(res, getChild(n.data, i))
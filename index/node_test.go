@@ -114,7 +114,7 @@ func Test_Data(t *testing.T) {
 	setSibling(buf, 1)
 	t.Logf("%v", buf)
 
-	setKey(buf, 0, 99)
+	setKey(buf, 0, wrapKey(EncodeUint64(99), 99))
 	setChild(buf, 0, 99)
 	t.Logf("%v", buf)
 }
@@ -1,6 +1,7 @@
 package index
 
 import (
+	"math"
 	"sync"
 
 	"github.com/ggymm/db"
@@ -12,15 +13,25 @@ import (
 type Index interface {
 	Close()
 
-	Insert(key, itemId uint64) error
-	Search(key uint64) ([]uint64, error)
-	SearchRange(prev, next uint64) ([]uint64, error)
+	Drop() error
+
+	Insert(key []byte, itemId uint64) error
+	Delete(key []byte, itemId uint64) error
+	Search(key []byte) ([]uint64, error)
+	SearchRange(prev, next []byte) ([]uint64, error)
+	Iterator(lower, upper Bound) Iterator
 
 	GetBootId() uint64
 }
 
+// tree
+// B+Tree 索引
+//
+// 插入和查询通过 sibling 处理节点分裂，可以并发执行
+// 删除时会合并或者重新分配节点，需要独占 latch
 type tree struct {
 	sync.Mutex
+	latch sync.RWMutex
 
 	bootId   uint64
 	bootItem data.Item
@@ -75,27 +86,31 @@ func (t *tree) rootId() uint64 {
 	return bin.Uint64(t.bootItem.DataBody())
 }
 
-func (t *tree) updateRootId(key, prev, next uint64) error {
+func (t *tree) updateRootId(rootId uint64) {
 	t.Lock()
 	defer t.Unlock()
 
-	// 插入根节点
-	root := createRoot(key, prev, next)
-	rootId, err := t.DataManage.Write(tx.Super, root)
-	if err != nil {
-		return err
-	}
-
 	// 更新根节点Id
 	t.bootItem.Before()
 	raw := bin.Uint64Raw(rootId)
 	copy(t.bootItem.DataBody(), raw)
 	t.bootItem.After(tx.Super)
+}
+
+// newRoot
+// 根节点分裂时，创建新的根节点
+func (t *tree) newRoot(key []byte, prev, next uint64) error {
+	root := createRoot(key, prev, next)
+	rootId, err := t.DataManage.Write(tx.Super, root)
+	if err != nil {
+		return err
+	}
+	t.updateRootId(rootId)
 	return nil
 }
 
 // insert
-func (t *tree) insert(nodeId, key, itemId uint64) (uint64, uint64, error) {
+func (t *tree) insert(nodeId uint64, key []byte, itemId uint64) ([]byte, uint64, error) {
 	var (
 		nd  *node
 		err error
@@ -103,7 +118,7 @@ func (t *tree) insert(nodeId, key, itemId uint64) (uint64, uint64, error) {
 
 	nd, err = wrapNode(t, nodeId)
 	if err != nil {
-		return 0, 0, err
+		return nil, 0, err
 	}
 	isLeaf := nd.IsLeaf()
 
@@ -115,17 +130,18 @@ func (t *tree) insert(nodeId, key, itemId uint64) (uint64, uint64, error) {
 		return t.insertNode(nodeId, key, itemId)
 	} else {
 		var (
-			child            uint64
-			newKey, newChild uint64
+			child    uint64
+			newKey   []byte
+			newChild uint64
 		)
 		// 查找可以插入的子节点，一直查找到叶子节点
 		child, err = t.searchNode(nodeId, key)
 		if err != nil {
-			return 0, 0, err
+			return nil, 0, err
 		}
 		newKey, newChild, err = t.insert(child, key, itemId)
 		if err != nil {
-			return 0, 0, err
+			return nil, 0, err
 		}
 
 		// 如果新的子节点不为 0 则代表下一层产生了分裂
@@ -136,24 +152,25 @@ func (t *tree) insert(nodeId, key, itemId uint64) (uint64, uint64, error) {
 			return t.insertNode(nodeId, newKey, newChild)
 		}
 	}
-	return 0, 0, err
+	return nil, 0, err
 }
 
 // insertNode
 // 向 node 中插入 key 和 itemId
 // 如果需要分裂，则返回新的 key 和新的 child
-func (t *tree) insertNode(nodeId, key, itemId uint64) (uint64, uint64, error) {
+func (t *tree) insertNode(nodeId uint64, key []byte, itemId uint64) ([]byte, uint64, error) {
 	var (
 		nd  *node
 		err error
 
-		sibling          uint64
-		newKey, newChild uint64
+		sibling  uint64
+		newKey   []byte
+		newChild uint64
 	)
 	for {
 		nd, err = wrapNode(t, nodeId)
 		if err != nil {
-			return 0, 0, err
+			return nil, 0, err
 		}
 		sibling, newKey, newChild, err = nd.Insert(key, itemId)
 
@@ -171,7 +188,7 @@ func (t *tree) insertNode(nodeId, key, itemId uint64) (uint64, uint64, error) {
 
 // search
 // 从 node 的子节点中查找 key 直到找到对应的叶子节点 id（itemId）
-func (t *tree) search(nodeId, key uint64) (uint64, error) {
+func (t *tree) search(nodeId uint64, key []byte) (uint64, error) {
 	var (
 		nd  *node
 		err error
@@ -199,7 +216,7 @@ func (t *tree) search(nodeId, key uint64) (uint64, error) {
 	}
 }
 
-func (t *tree) searchNode(nodeId, key uint64) (uint64, error) {
+func (t *tree) searchNode(nodeId uint64, key []byte) (uint64, error) {
 	for {
 		nd, err := wrapNode(t, nodeId)
 		if err != nil {
@@ -223,19 +240,78 @@ func (t *tree) Close() {
 	t.bootItem.Release()
 }
 
+// nodes
+// 获取索引的全部节点
+//
+// 同一层的节点通过 sibling 连接，因此逐层遍历，从每层最左侧的节点开始
+func (t *tree) nodes() ([]uint64, error) {
+	ids := make([]uint64, 0)
+	first := t.rootId()
+	for first != 0 {
+		var (
+			next   uint64
+			nodeId = first
+		)
+		for nodeId != 0 {
+			nd, err := wrapNode(t, nodeId)
+			if err != nil {
+				return nil, err
+			}
+			if nd == nil {
+				break
+			}
+			if next == 0 && !nd.IsLeaf() {
+				next = getChild(nd.data, 0)
+			}
+			ids = append(ids, nodeId)
+			nodeId = getSibling(nd.data)
+
+			// 释放 node 引用
+			release(nd)
+		}
+		first = next
+	}
+	return ids, nil
+}
+
+// Drop
+// 释放索引的全部节点和根节点信息，释放之后不能再使用索引
+func (t *tree) Drop() error {
+	t.latch.Lock()
+	defer t.latch.Unlock()
+
+	ids, err := t.nodes()
+	if err != nil {
+		return err
+	}
+
+	// 释放节点和根节点信息
+	t.bootItem.Release()
+	for _, id := range append(ids, t.bootId) {
+		err = t.DataManage.Free(id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Insert
-// 插入 key（字段计算的hash值） 和 itemId（数据项的Id） 的索引关系
-func (t *tree) Insert(key, itemId uint64) error {
+// 插入 key（字段值编码后的键） 和 itemId（数据项的Id） 的索引关系
+func (t *tree) Insert(key []byte, itemId uint64) error {
+	t.latch.RLock()
+	defer t.latch.RUnlock()
+
 	rootId := t.rootId()
 
-	newKey, newChild, err := t.insert(rootId, key, itemId)
+	newKey, newChild, err := t.insert(rootId, wrapKey(key, itemId), itemId)
 	if err != nil {
 		return err
 	}
 
 	if newChild != 0 {
 		// 需要变更根节点
-		err = t.updateRootId(newKey, rootId, newChild)
+		err = t.newRoot(newKey, rootId, newChild)
 		if err != nil {
 			return err
 		}
@@ -243,16 +319,183 @@ func (t *tree) Insert(key, itemId uint64) error {
 	return nil
 }
 
-func (t *tree) Search(key uint64) ([]uint64, error) {
+// Delete
+// 删除 key 和 itemId 的索引关系（不存在时忽略）
+//
+// 删除之后，子节点的 key 数量过少时与兄弟节点合并或者重新分配
+// 根节点只剩下一个子节点时，使用子节点作为新的根节点
+func (t *tree) Delete(key []byte, itemId uint64) error {
+	t.latch.Lock()
+	defer t.latch.Unlock()
+
+	_, err := t.delete(t.rootId(), wrapKey(key, itemId))
+	if err != nil {
+		return err
+	}
+	return t.collapseRoot()
+}
+
+// delete
+// 从 node 的子节点中删除 key，返回是否删除成功
+func (t *tree) delete(nodeId uint64, key []byte) (bool, error) {
+	nd, err := wrapNode(t, nodeId)
+	if err != nil {
+		return false, err
+	}
+	isLeaf := nd.IsLeaf()
+
+	// 释放 node 引用
+	release(nd)
+
+	// 判断是否是叶子节点
+	if isLeaf {
+		return t.deleteNode(nodeId, key)
+	}
+
+	child, err := t.searchNode(nodeId, key)
+	if err != nil {
+		return false, err
+	}
+	ok, err := t.delete(child, key)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	// 检查子节点是否需要合并
+	nd, err = wrapNode(t, child)
+	if err != nil {
+		return false, err
+	}
+	underflow := nd.Underflow()
+	release(nd)
+	if underflow {
+		return true, t.rebalance(nodeId, child)
+	}
+	return true, nil
+}
+
+// deleteNode
+// 从叶子节点中删除 key（当前节点中没有找到时，继续查找兄弟节点）
+func (t *tree) deleteNode(nodeId uint64, key []byte) (bool, error) {
+	for {
+		nd, err := wrapNode(t, nodeId)
+		if err != nil {
+			return false, err
+		}
+		ok, sibling := nd.Delete(key)
+
+		// 释放 node 引用
+		release(nd)
+
+		// 判断是否需要继续查找下一个节点
+		if ok || sibling == 0 {
+			return ok, nil
+		}
+		nodeId = sibling
+	}
+}
+
+// rebalance
+// 子节点的 key 数量过少时，与相邻的兄弟节点（同一个父节点）合并或者重新分配 key
+//
+// 两个节点的 key 数量之和小于 balanceNum*2 时合并（合并之后不需要分裂），并释放右侧的节点
+// 否则在两个节点之间平均分配 key，并更新父节点中的分隔 key
+func (t *tree) rebalance(parentId, childId uint64) error {
+	parent, err := wrapNode(t, parentId)
+	if err != nil {
+		return err
+	}
+	defer release(parent)
+
+	// 查找子节点的位置，选择右侧的兄弟节点（最后一个子节点选择左侧的兄弟节点）
+	num := getKeysNum(parent.data)
+	i := 0
+	for i < num && getChild(parent.data, i) != childId {
+		i++
+	}
+	if i == num || num < 2 {
+		return nil
+	}
+	if i == num-1 {
+		i--
+	}
+
+	left, err := wrapNode(t, getChild(parent.data, i))
+	if err != nil {
+		return err
+	}
+	defer release(left)
+	right, err := wrapNode(t, getChild(parent.data, i+1))
+	if err != nil {
+		return err
+	}
+	if getSibling(left.data) != right.id {
+		release(right)
+		return nil
+	}
+
+	parent.item.Before()
+	left.item.Before()
+	right.item.Before()
+	merged := mergeNode(parent.data, left.data, right.data, i)
+	if merged {
+		right.item.UnBefore()
+	} else {
+		right.item.After(tx.Super)
+	}
+	left.item.After(tx.Super)
+	parent.item.After(tx.Super)
+
+	// 释放 node 引用
+	release(right)
+	if merged {
+		return t.DataManage.Free(right.id)
+	}
+	return nil
+}
+
+// collapseRoot
+// 根节点只有一个子节点时，使用子节点作为新的根节点，并释放原来的根节点
+func (t *tree) collapseRoot() error {
+	for {
+		rootId := t.rootId()
+		nd, err := wrapNode(t, rootId)
+		if err != nil {
+			return err
+		}
+		if getLeaf(nd.data) || getKeysNum(nd.data) != 1 {
+			release(nd)
+			return nil
+		}
+		child := getChild(nd.data, 0)
+		release(nd)
+
+		t.updateRootId(child)
+		err = t.DataManage.Free(rootId)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (t *tree) Search(key []byte) ([]uint64, error) {
 	return t.SearchRange(key, key)
 }
 
-func (t *tree) SearchRange(prevKey, nextKey uint64) ([]uint64, error) {
+// SearchRange
+// 查找 key 在 [prev, next] 区间内的 itemId
+func (t *tree) SearchRange(prev, next []byte) ([]uint64, error) {
+	t.latch.RLock()
+	defer t.latch.RUnlock()
+
 	var (
 		err error
 
 		nd     *node
 		prevId uint64
+
+		prevKey = wrapKey(prev, 0)
+		nextKey = wrapKey(next, math.MaxUint64)
 	)
 
 	prevId, err = t.search(t.rootId(), prevKey)
@@ -2,9 +2,11 @@ package index
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"sync"
 	"testing"
@@ -50,10 +52,21 @@ func TestTree_Gen(t *testing.T) {
 	_ = f.Close()
 }
 
+func newOpt(t *testing.T, name string) *db.Option {
+	path := filepath.Join(db.RunPath(), "temp/index", name)
+	err := os.RemoveAll(path)
+	if err != nil {
+		t.Fatalf("remove err %v", err)
+	}
+
+	opt := db.NewOption(path)
+	opt.Memory = (1 << 20) * 64
+	return opt
+}
+
 func TestIndex_Func(t *testing.T) {
 	abs := db.RunPath()
-	opt := db.NewOption(abs, "temp/index")
-	opt.Memory = page.Size * 10
+	opt := newOpt(t, "func")
 
 	tm := tx.NewMockManage()
 	dm := data.NewManage(tm, opt)
@@ -78,7 +91,7 @@ func TestIndex_Func(t *testing.T) {
 	// 测试插入
 	for i := 0; i < len(lines)-1; i++ {
 		key, _ := strconv.ParseUint(lines[i], 10, 64)
-		err = index.Insert(key, key)
+		err = index.Insert(EncodeUint64(key), key)
 		if err != nil {
 			t.Fatalf("insert index err %v", err)
 		}
@@ -87,7 +100,7 @@ func TestIndex_Func(t *testing.T) {
 	// 测试搜索
 	for i := 0; i < len(lines)-1; i++ {
 		key, _ := strconv.ParseUint(lines[i], 10, 64)
-		result, err = index.Search(key)
+		result, err = index.Search(EncodeUint64(key))
 		if err != nil {
 			t.Fatalf("search index err %v", err)
 		} else {
@@ -104,9 +117,7 @@ func TestIndex_Func(t *testing.T) {
 }
 
 func TestIndex_FuncAsync(t *testing.T) {
-	abs := db.RunPath()
-	opt := db.NewOption(abs, "temp/index")
-	opt.Memory = page.Size * 10
+	opt := newOpt(t, "async")
 
 	txManage := tx.NewMockManage()
 	dataManage := data.NewManage(txManage, opt)
@@ -136,7 +147,7 @@ func TestIndex_FuncAsync(t *testing.T) {
 		go func() {
 			for j := 0; j < taskNum; j++ {
 				key := rand.Uint64()
-				err = index.Insert(key, key)
+				err = index.Insert(EncodeUint64(key), key)
 				if err != nil {
 					t.Errorf("insert key %d err %v", key, err)
 					continue
@@ -163,7 +174,7 @@ func TestIndex_FuncAsync(t *testing.T) {
 				if next-prev > 10000 {
 					next = prev + 10000
 				}
-				_, err = index.SearchRange(prev, next)
+				_, err = index.SearchRange(EncodeUint64(prev), EncodeUint64(next))
 				if err != nil {
 					continue
 				}
@@ -177,7 +188,7 @@ func TestIndex_FuncAsync(t *testing.T) {
 	// 检查
 	t.Log("index check")
 	for key, children := range cacheMap {
-		res, _ := index.Search(key)
+		res, _ := index.Search(EncodeUint64(key))
 
 		if len(res) != children {
 			t.Fatalf("error index check key %d %v %d", key, res, children)
@@ -185,3 +196,349 @@ func TestIndex_FuncAsync(t *testing.T) {
 	}
 	t.Log("index check success")
 }
+
+func TestIndex_SearchRange(t *testing.T) {
+	opt := newOpt(t, "range")
+
+	tm := tx.NewMockManage()
+	dm := data.NewManage(tm, opt)
+
+	index, err := NewIndex(dm, opt)
+	if err != nil {
+		t.Fatalf("new index err %v", err)
+	}
+
+	// 插入字符串（包含重复值）
+	values := make([]string, 0)
+	for i := 0; i < 500; i++ {
+		values = append(values, fmt.Sprintf("key-%03d", rand.Intn(300)))
+	}
+	for i, v := range values {
+		err = index.Insert(EncodeString(v), uint64(i+1))
+		if err != nil {
+			t.Fatalf("insert index err %v", err)
+		}
+	}
+
+	// 查询区间 [key-100, key-199]
+	prev, next := "key-100", "key-199"
+	res, err := index.SearchRange(EncodeString(prev), EncodeString(next))
+	if err != nil {
+		t.Fatalf("search index err %v", err)
+	}
+
+	want := make([]uint64, 0)
+	for i, v := range values {
+		if v >= prev && v <= next {
+			want = append(want, uint64(i+1))
+		}
+	}
+	if len(res) != len(want) {
+		t.Fatalf("search index err %d %d", len(res), len(want))
+	}
+
+	// 结果按照字段值排序
+	for i := 1; i < len(res); i++ {
+		if values[res[i-1]-1] > values[res[i]-1] {
+			t.Fatalf("search index order err %s %s", values[res[i-1]-1], values[res[i]-1])
+		}
+	}
+	slices.Sort(res)
+	if !slices.Equal(res, want) {
+		t.Fatalf("search index err %v %v", res, want)
+	}
+}
+
+func TestIndex_Drop(t *testing.T) {
+	opt := newOpt(t, "drop")
+
+	tm := tx.NewMockManage()
+	dm := data.NewManage(tm, opt)
+
+	build := func() (*tree, map[uint32]bool) {
+		index, err := NewIndex(dm, opt)
+		if err != nil {
+			t.Fatalf("new index err %v", err)
+		}
+		for i := uint64(0); i < 5000; i++ {
+			err = index.Insert(EncodeUint64(i), i)
+			if err != nil {
+				t.Fatalf("insert index err %v", err)
+			}
+		}
+
+		tr := index.(*tree)
+		ids, err := tr.nodes()
+		if err != nil {
+			t.Fatalf("nodes err %v", err)
+		}
+		pages := make(map[uint32]bool)
+		for _, id := range append(ids, tr.bootId) {
+			pages[uint32(id>>16)] = true
+		}
+		return tr, pages
+	}
+
+	// 删除索引之后，新的索引使用被释放的页面
+	tr, pages := build()
+	if len(pages) < 2 {
+		t.Fatalf("pages %v", pages)
+	}
+	err := tr.Drop()
+	if err != nil {
+		t.Fatalf("drop err %v", err)
+	}
+	tr, reused := build()
+	for no := range reused {
+		if !pages[no] {
+			t.Fatalf("page %d not in dropped pages %v", no, pages)
+		}
+	}
+	result, err := tr.SearchRange(EncodeUint64(100), EncodeUint64(199))
+	if err != nil || len(result) != 100 {
+		t.Fatalf("search err %v %d", err, len(result))
+	}
+}
+
+func TestIndex_Delete(t *testing.T) {
+	opt := newOpt(t, "delete")
+
+	tm := tx.NewMockManage()
+	dm := data.NewManage(tm, opt)
+
+	index, err := NewIndex(dm, opt)
+	if err != nil {
+		t.Fatalf("new index err %v", err)
+	}
+
+	// 插入重复的值，每个值对应多个 itemId
+	for i := uint64(0); i < 3000; i++ {
+		err = index.Insert(EncodeUint64(i%1000), i)
+		if err != nil {
+			t.Fatalf("insert index err %v", err)
+		}
+	}
+
+	// 删除偶数的 itemId，不存在的索引关系直接忽略
+	for i := uint64(0); i < 3000; i += 2 {
+		err = index.Delete(EncodeUint64(i%1000), i)
+		if err != nil {
+			t.Fatalf("delete index err %v", err)
+		}
+	}
+	err = index.Delete(EncodeUint64(1), 2)
+	if err != nil {
+		t.Fatalf("delete index err %v", err)
+	}
+
+	res, err := index.Search(EncodeUint64(1))
+	if err != nil || !slices.Equal(res, []uint64{1, 1001, 2001}) {
+		t.Fatalf("search err %v %v", err, res)
+	}
+	res, err = index.Search(EncodeUint64(2))
+	if err != nil || len(res) != 0 {
+		t.Fatalf("search err %v %v", err, res)
+	}
+	res, err = index.SearchRange(MinKey(), MaxKey())
+	if err != nil || len(res) != 1500 {
+		t.Fatalf("search err %v %d", err, len(res))
+	}
+
+	// 删除之后可以重新插入
+	err = index.Insert(EncodeUint64(2), 2)
+	if err != nil {
+		t.Fatalf("insert index err %v", err)
+	}
+	res, err = index.Search(EncodeUint64(2))
+	if err != nil || !slices.Equal(res, []uint64{2}) {
+		t.Fatalf("search err %v %v", err, res)
+	}
+}
+
+func TestIndex_DeleteMerge(t *testing.T) {
+	opt := newOpt(t, "merge")
+
+	tm := tx.NewMockManage()
+	dm := data.NewManage(tm, opt)
+
+	index, err := NewIndex(dm, opt)
+	if err != nil {
+		t.Fatalf("new index err %v", err)
+	}
+	tr := index.(*tree)
+
+	count := func() int {
+		ids, err := tr.nodes()
+		if err != nil {
+			t.Fatalf("nodes err %v", err)
+		}
+		return len(ids)
+	}
+	check := func(keys map[uint64]bool) {
+		res, err := index.SearchRange(MinKey(), MaxKey())
+		if err != nil || len(res) != len(keys) {
+			t.Fatalf("search err %v %d %d", err, len(res), len(keys))
+		}
+		for i, id := range res {
+			if !keys[id] || (i > 0 && res[i-1] >= id) {
+				t.Fatalf("search result err %v", res)
+			}
+		}
+		for id := range keys {
+			res, err = index.Search(EncodeUint64(id))
+			if err != nil || !slices.Equal(res, []uint64{id}) {
+				t.Fatalf("search %d err %v %v", id, err, res)
+			}
+		}
+	}
+
+	keys := make(map[uint64]bool)
+	for _, i := range rand.Perm(20000) {
+		err = index.Insert(EncodeUint64(uint64(i)), uint64(i))
+		if err != nil {
+			t.Fatalf("insert index err %v", err)
+		}
+		keys[uint64(i)] = true
+	}
+	before := count()
+
+	// 随机删除 95% 的 key，节点合并之后数量减少
+	for _, i := range rand.Perm(20000) {
+		if len(keys) == 1000 {
+			break
+		}
+		err = index.Delete(EncodeUint64(uint64(i)), uint64(i))
+		if err != nil {
+			t.Fatalf("delete index err %v", err)
+		}
+		delete(keys, uint64(i))
+	}
+	after := count()
+	if after*5 > before {
+		t.Fatalf("nodes not merged %d -> %d", before, after)
+	}
+	check(keys)
+
+	// 删除之后继续插入
+	for i := uint64(20000); i < 21000; i++ {
+		err = index.Insert(EncodeUint64(i), i)
+		if err != nil {
+			t.Fatalf("insert index err %v", err)
+		}
+		keys[i] = true
+	}
+	check(keys)
+
+	// 全部删除之后，根节点是叶子节点
+	for id := range keys {
+		err = index.Delete(EncodeUint64(id), id)
+		if err != nil {
+			t.Fatalf("delete index err %v", err)
+		}
+		delete(keys, id)
+	}
+	check(keys)
+	if count() != 1 {
+		t.Fatalf("root not collapsed, nodes %d", count())
+	}
+}
+
+func TestIndex_Iterator(t *testing.T) {
+	opt := newOpt(t, "iterator")
+
+	tm := tx.NewMockManage()
+	dm := data.NewManage(tm, opt)
+
+	index, err := NewIndex(dm, opt)
+	if err != nil {
+		t.Fatalf("new index err %v", err)
+	}
+
+	// 每个值对应两个 itemId，随机插入并删除部分数据（产生合并的节点）
+	want := make([]uint64, 0)
+	for _, i := range rand.Perm(6000) {
+		err = index.Insert(EncodeUint64(uint64(i/2)), uint64(i))
+		if err != nil {
+			t.Fatalf("insert index err %v", err)
+		}
+	}
+	for i := uint64(0); i < 6000; i++ {
+		if i >= 1000 && i < 4000 && i%3 != 0 {
+			err = index.Delete(EncodeUint64(i/2), i)
+			if err != nil {
+				t.Fatalf("delete index err %v", err)
+			}
+			continue
+		}
+		want = append(want, i)
+	}
+
+	collect := func(it Iterator, next func() bool, limit int) []uint64 {
+		res := make([]uint64, 0)
+		for len(res) < limit && next() {
+			res = append(res, it.ItemId())
+		}
+		if it.Err() != nil {
+			t.Fatalf("iterator err %v", it.Err())
+		}
+		return res
+	}
+	between := func(lo, hi uint64) []uint64 {
+		res := make([]uint64, 0)
+		for _, id := range want {
+			if id/2 >= lo && id/2 <= hi {
+				res = append(res, id)
+			}
+		}
+		return res
+	}
+
+	// 全部数据，正序和倒序
+	it := index.Iterator(Bound{}, Bound{})
+	if res := collect(it, it.Next, math.MaxInt); !slices.Equal(res, want) {
+		t.Fatalf("next %d %d", len(res), len(want))
+	}
+	it = index.Iterator(Bound{}, Bound{})
+	res := collect(it, it.Prev, math.MaxInt)
+	slices.Reverse(res)
+	if !slices.Equal(res, want) {
+		t.Fatalf("prev %d %d", len(res), len(want))
+	}
+
+	// 包含和不包含边界
+	it = index.Iterator(Bound{Key: EncodeUint64(100)}, Bound{Key: EncodeUint64(1800)})
+	if res = collect(it, it.Next, math.MaxInt); !slices.Equal(res, between(100, 1800)) {
+		t.Fatalf("inclusive %v", res)
+	}
+	it = index.Iterator(Bound{Key: EncodeUint64(100), Exclusive: true}, Bound{Key: EncodeUint64(1800), Exclusive: true})
+	res = collect(it, it.Prev, math.MaxInt)
+	slices.Reverse(res)
+	if !slices.Equal(res, between(101, 1799)) {
+		t.Fatalf("exclusive %v", res)
+	}
+
+	// 提前停止
+	it = index.Iterator(Bound{}, Bound{Key: EncodeUint64(2500)})
+	if res = collect(it, it.Prev, 3); !slices.Equal(res, []uint64{5001, 5000, 4999}) {
+		t.Fatalf("limit %v", res)
+	}
+	it.Close()
+	if it.Next() || it.Prev() {
+		t.Fatalf("closed iterator should stop")
+	}
+
+	// 定位之后前后移动
+	it = index.Iterator(Bound{Key: EncodeUint64(10)}, Bound{})
+	if !it.Seek(EncodeUint64(400)) || it.ItemId() != 800 || !slices.Equal(it.Key(), wrapKey(EncodeUint64(400), 0)[:KeyLen]) {
+		t.Fatalf("seek %d", it.ItemId())
+	}
+	if !it.Prev() || it.ItemId() != 799 || !it.Next() || !it.Next() || it.ItemId() != 801 {
+		t.Fatalf("move %d", it.ItemId())
+	}
+	if !it.Seek(EncodeUint64(0)) || it.ItemId() != 20 || it.Prev() {
+		t.Fatalf("seek before lower %d", it.ItemId())
+	}
+	if it.Seek(EncodeUint64(3000)) {
+		t.Fatalf("seek after last %d", it.ItemId())
+	}
+}
@@ -2,8 +2,11 @@ package sql
 
 import (
 	"cmp"
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Type int
@@ -15,6 +18,12 @@ const (
 	Rollback
 
 	Create
+	Alter
+	Drop
+	Truncate
+	IndexCreate
+	IndexDrop
+	Analyze
 	Select
 	Insert
 	Update
@@ -28,12 +37,29 @@ const (
 	Int32
 	Int64
 	Varchar
+	Bool
+	Double
+	Decimal
+	Timestamp
+	Date
 )
 
+// 字段类型的名称（包含别名）
 var typeMapping = map[string]FieldType{
-	"INT32":   Int32,
-	"INT64":   Int64,
-	"VARCHAR": Varchar,
+	"INT32":     Int32,
+	"INT":       Int32,
+	"INTEGER":   Int32,
+	"INT64":     Int64,
+	"BIGINT":    Int64,
+	"VARCHAR":   Varchar,
+	"BOOL":      Bool,
+	"BOOLEAN":   Bool,
+	"DOUBLE":    Double,
+	"DECIMAL":   Decimal,
+	"NUMERIC":   Decimal,
+	"TIMESTAMP": Timestamp,
+	"DATETIME":  Timestamp,
+	"DATE":      Date,
 }
 
 func (t FieldType) String() string {
@@ -44,10 +70,105 @@ func (t FieldType) String() string {
 		return "INT64"
 	case Varchar:
 		return "VARCHAR"
+	case Bool:
+		return "BOOL"
+	case Double:
+		return "DOUBLE"
+	case Decimal:
+		return "DECIMAL"
+	case Timestamp:
+		return "TIMESTAMP"
+	case Date:
+		return "DATE"
 	}
 	return ""
 }
 
+var ErrInvalidType = errors.New("invalid field type")
+
+const (
+	// DECIMAL 的默认精度（与 MySQL 相同）
+	defaultPrecision = 10
+	defaultScale     = 0
+
+	// DECIMAL 使用 int64 保存，最多 18 位有效数字
+	maxPrecision = 18
+)
+
+// ColumnType 字段类型
+//
+// Precision 和 Scale 只用于 DECIMAL，分别为有效数字的位数和小数的位数
+// VARCHAR(n) 中的长度会被忽略
+//
+// 字段类型在表信息中以 String 的结果保存，例如：INT64、DECIMAL(10,2)
+type ColumnType struct {
+	Type      FieldType
+	Precision int
+	Scale     int
+}
+
+// newColumnType 根据类型名称和参数创建字段类型
+func newColumnType(name string, args ...string) (ColumnType, error) {
+	t, ok := typeMapping[strings.ToUpper(name)]
+	if !ok {
+		return ColumnType{}, fmt.Errorf("%w: %s", ErrInvalidType, name)
+	}
+
+	nums := make([]int, 0, len(args))
+	for _, arg := range args {
+		n, err := strconv.Atoi(strings.TrimSpace(arg))
+		if err != nil || n < 0 {
+			return ColumnType{}, fmt.Errorf("%w: %s(%s)", ErrInvalidType, name, strings.Join(args, ","))
+		}
+		nums = append(nums, n)
+	}
+
+	ct := ColumnType{Type: t}
+	switch t {
+	case Varchar:
+		if len(nums) > 1 || (len(nums) == 1 && nums[0] == 0) {
+			return ColumnType{}, fmt.Errorf("%w: %s(%s)", ErrInvalidType, name, strings.Join(args, ","))
+		}
+	case Decimal:
+		ct.Precision = defaultPrecision
+		ct.Scale = defaultScale
+		if len(nums) > 0 {
+			ct.Precision = nums[0]
+		}
+		if len(nums) > 1 {
+			ct.Scale = nums[1]
+		}
+		if len(nums) > 2 || ct.Precision < 1 || ct.Precision > maxPrecision || ct.Scale > ct.Precision {
+			return ColumnType{}, fmt.Errorf("%w: %s(%s)", ErrInvalidType, name, strings.Join(args, ","))
+		}
+	default:
+		if len(nums) != 0 {
+			return ColumnType{}, fmt.Errorf("%w: %s(%s)", ErrInvalidType, name, strings.Join(args, ","))
+		}
+	}
+	return ct, nil
+}
+
+// ParseColumnType 解析字段类型，例如：INT64、DECIMAL(10,2)
+func ParseColumnType(s string) (ColumnType, error) {
+	name, rest, found := strings.Cut(s, "(")
+	if !found {
+		return newColumnType(strings.TrimSpace(name))
+	}
+	rest, ok := strings.CutSuffix(strings.TrimSpace(rest), ")")
+	if !ok {
+		return ColumnType{}, fmt.Errorf("%w: %s", ErrInvalidType, s)
+	}
+	return newColumnType(strings.TrimSpace(name), strings.Split(rest, ",")...)
+}
+
+func (c ColumnType) String() string {
+	if c.Type == Decimal {
+		return fmt.Sprintf("DECIMAL(%d,%d)", c.Precision, c.Scale)
+	}
+	return c.Type.String()
+}
+
 type CompareOperate int
 
 const (
@@ -76,6 +197,70 @@ func (o *CompareOperate) Negate() {
 	}
 }
 
+// Reverse 交换比较的两边（例如 a < b 等价于 b > a）
+func (o *CompareOperate) Reverse() {
+	switch *o {
+	case LT:
+		*o = GT
+	case GT:
+		*o = LT
+	case LE:
+		*o = GE
+	case GE:
+		*o = LE
+	}
+}
+
+// Value 语句中的值
+//
+// 字面量：Str 为去除引号后的字符串
+// NULL：Null 为 true（与字符串 'NULL' 和空字符串不同）
+// 参数占位符（? 或者 $n）：Param 为参数的序号（从 1 开始），绑定参数（Prepared.Bind）后才能使用
+type Value struct {
+	Str   string
+	Null  bool
+	Param int
+}
+
+// Truth 三值逻辑
+//
+// 与 NULL 比较的结果为 Unknown，Unknown 取反后仍然为 Unknown
+// 只有结果为 True 的数据才符合查询条件
+type Truth int8
+
+const (
+	False Truth = iota
+	True
+	Unknown
+)
+
+func (t Truth) Not() Truth {
+	switch t {
+	case True:
+		return False
+	case False:
+		return True
+	}
+	return Unknown
+}
+
+func (t Truth) And(o Truth) Truth {
+	if t == False || o == False {
+		return False
+	}
+	if t == Unknown || o == Unknown {
+		return Unknown
+	}
+	return True
+}
+
+func truthOf(b bool) Truth {
+	if b {
+		return True
+	}
+	return False
+}
+
 type Statement interface {
 	StmtType() Type
 	TableName() string
@@ -136,24 +321,141 @@ type CreateTable struct {
 }
 
 type CreateField struct {
-	Name     string
-	Type     FieldType
-	Default  string
-	Nullable bool
+	Name          string
+	Type          ColumnType
+	Default       *CreateDefault
+	Nullable      bool
+	AutoIncrement bool
+}
+
+// 默认值表达式
+const CurrentTimestamp = "CURRENT_TIMESTAMP"
+
+// CreateDefault 字段的默认值
+//
+// 常量：Value 为默认值（DEFAULT NULL 时 Value.Null 为 true）
+// 表达式：Expr 为表达式（CURRENT_TIMESTAMP），插入数据时计算
+type CreateDefault struct {
+	Value *Value
+	Expr  string
 }
 
+// CreateIndex 建表语句中的索引（多列索引时 Field 包含多个字段）
 type CreateIndex struct {
 	Pk    bool
 	Name  string
-	Field string
+	Field []string
 }
 
 type CreateTableOption struct{}
 
+// AlterAction 修改表的操作
+type AlterAction int
+
+const (
+	_ AlterAction = iota
+	AddColumn
+	DropColumn
+	RenameColumn
+	RenameTable
+)
+
+// AlterStmt 修改表
+//
+// ADD [COLUMN] field：Field 为新增的字段
+// DROP [COLUMN] name：Name 为删除的字段
+// RENAME COLUMN name TO new_name：Name 为原字段名，NewName 为新字段名
+// RENAME TO new_name：NewName 为新表名
+type AlterStmt struct {
+	Table   string
+	Action  AlterAction
+	Field   *CreateField
+	Name    string
+	NewName string
+}
+
+func (*AlterStmt) StmtType() Type {
+	return Alter
+}
+
+func (s *AlterStmt) TableName() string {
+	return s.Table
+}
+
+// DropStmt 删除表
+type DropStmt struct {
+	Table    string
+	IfExists bool
+}
+
+func (*DropStmt) StmtType() Type {
+	return Drop
+}
+
+func (s *DropStmt) TableName() string {
+	return s.Table
+}
+
+// TruncateStmt 清空表
+type TruncateStmt struct {
+	Table string
+}
+
+func (*TruncateStmt) StmtType() Type {
+	return Truncate
+}
+
+func (s *TruncateStmt) TableName() string {
+	return s.Table
+}
+
+// CreateIndexStmt 创建索引
+type CreateIndexStmt struct {
+	Name   string
+	Table  string
+	Field  []string
+	Unique bool
+}
+
+func (*CreateIndexStmt) StmtType() Type {
+	return IndexCreate
+}
+
+func (s *CreateIndexStmt) TableName() string {
+	return s.Table
+}
+
+// DropIndexStmt 删除索引（Table 为空时，在全部的表中查找索引）
+type DropIndexStmt struct {
+	Name  string
+	Table string
+}
+
+func (*DropIndexStmt) StmtType() Type {
+	return IndexDrop
+}
+
+func (s *DropIndexStmt) TableName() string {
+	return s.Table
+}
+
+// AnalyzeStmt 收集表的统计信息
+type AnalyzeStmt struct {
+	Table string
+}
+
+func (*AnalyzeStmt) StmtType() Type {
+	return Analyze
+}
+
+func (s *AnalyzeStmt) TableName() string {
+	return s.Table
+}
+
 type InsertStmt struct {
 	Table string
 	Field []string
-	Value []string
+	Value []*Value
 }
 
 func (*InsertStmt) StmtType() Type {
@@ -166,7 +468,7 @@ func (s *InsertStmt) TableName() string {
 
 type UpdateStmt struct {
 	Table string
-	Value map[string]string
+	Value map[string]*Value
 	Where []SelectWhere
 }
 
@@ -192,11 +494,15 @@ func (s *DeleteStmt) TableName() string {
 }
 
 type SelectStmt struct {
-	Table string
-	Field []*SelectField
-	Where []SelectWhere
-	Order []*SelectOrder
-	Limit *SelectLimit
+	Table  string
+	Alias  string
+	Join   []*SelectJoin
+	Field  []*SelectField
+	Where  []SelectWhere
+	Group  []string
+	Having []SelectWhere
+	Order  []*SelectOrder
+	Limit  *SelectLimit
 }
 
 func (*SelectStmt) StmtType() Type {
@@ -207,16 +513,67 @@ func (s *SelectStmt) TableName() string {
 	return s.Table
 }
 
+// SelectJoin 连接的表
+//
+// Left 为 true 时是 LEFT JOIN，否则是 INNER JOIN（没有连接条件时是笛卡尔积）
+type SelectJoin struct {
+	Left  bool
+	Table string
+	Alias string
+	On    []*SelectJoinOn
+}
+
+// SelectJoinOn 连接条件（两边都是字段，多个条件使用 AND 连接）
+//
+// Literal 为 true 时，有一边是常量（字符串或者数字），由执行器返回错误
+type SelectJoinOn struct {
+	Left    string
+	Right   string
+	Operate CompareOperate
+	Literal bool
+}
+
+// SelectField 查询的字段
+//
+// Func 为聚合函数的名称（大写），此时 Name 为函数的参数（COUNT(*) 的参数为 *）
 type SelectField struct {
 	Name  string
 	Alias string
+	Func  string
+}
+
+// 聚合函数
+const (
+	FuncCount = "COUNT"
+	FuncSum   = "SUM"
+	FuncMin   = "MIN"
+	FuncMax   = "MAX"
+	FuncAvg   = "AVG"
+)
+
+// ParseFunc 解析聚合函数的名称（不区分大小写）
+func ParseFunc(name string) (string, error) {
+	fn := strings.ToUpper(name)
+	switch fn {
+	case FuncCount, FuncSum, FuncMin, FuncMax, FuncAvg:
+		return fn, nil
+	}
+	return "", fmt.Errorf("unknown function %s", name)
+}
+
+// FuncName 聚合函数的结果在数据中的名称，例如 COUNT(*)、SUM(age)
+func FuncName(fn, arg string) string {
+	return fn + "(" + arg + ")"
 }
 
 type SelectWhere interface {
 	// Negate 取反
 	Negate()
 
-	// Match 判断是否符合条件
+	// Eval 计算条件的结果（三值逻辑）
+	Eval(row map[string]any) Truth
+
+	// Match 判断是否符合条件（结果为 True）
 	Match(row map[string]any) bool
 }
 
@@ -229,22 +586,26 @@ func (w *SelectWhereExpr) Negate() {
 	w.Negation = !w.Negation
 }
 
-func (w *SelectWhereExpr) Match(row map[string]any) bool {
-	filter := true
+func (w *SelectWhereExpr) Eval(row map[string]any) Truth {
+	ret := True
 	for _, be := range w.Cnf {
-		filter = filter && be.Match(row)
+		ret = ret.And(be.Eval(row))
 	}
 
 	if w.Negation {
-		return !filter
+		return ret.Not()
 	}
-	return filter
+	return ret
+}
+
+func (w *SelectWhereExpr) Match(row map[string]any) bool {
+	return w.Eval(row) == True
 }
 
 type SelectWhereField struct {
 	Pos     int
 	Field   string
-	Value   string
+	Value   *Value
 	Operate CompareOperate
 }
 
@@ -252,46 +613,111 @@ func (w *SelectWhereField) Negate() {
 	w.Operate.Negate()
 }
 
-func (w *SelectWhereField) Match(row map[string]any) bool {
-	val, ok := row[w.Field]
-	if !ok {
-		return false
+// Eval 字段值或者比较的值为 NULL 时，结果为 Unknown
+func (w *SelectWhereField) Eval(row map[string]any) Truth {
+	val := row[w.Field]
+	if val == nil || w.Value.Null {
+		return Unknown
 	}
 
-	var r int
-	switch v := val.(type) {
-	case uint32:
-		dst, err := strconv.ParseUint(w.Value, 10, 32)
-		if err != nil {
-			return false
-		}
-		r = cmp.Compare(v, uint32(dst))
-	case uint64:
-		dst, err := strconv.ParseUint(w.Value, 10, 64)
-		if err != nil {
-			return false
-		}
-		r = cmp.Compare(v, dst)
-	case string:
-		r = strings.Compare(v, w.Value)
-	default:
-		return false
+	r, ok := compareVal(val, w.Value.Str)
+	if !ok {
+		return False
 	}
 	switch w.Operate {
 	case EQ:
-		return r == 0
+		return truthOf(r == 0)
 	case NE:
-		return r != 0
+		return truthOf(r != 0)
 	case LT:
-		return r < 0
+		return truthOf(r < 0)
 	case GT:
-		return r > 0
+		return truthOf(r > 0)
 	case LE:
-		return r <= 0
+		return truthOf(r <= 0)
 	case GE:
-		return r >= 0
+		return truthOf(r >= 0)
+	}
+	return False
+}
+
+func (w *SelectWhereField) Match(row map[string]any) bool {
+	return w.Eval(row) == True
+}
+
+// SelectWhereNull IS NULL（Not 为 true 时表示 IS NOT NULL）
+type SelectWhereNull struct {
+	Field string
+	Not   bool
+}
+
+func (w *SelectWhereNull) Negate() {
+	w.Not = !w.Not
+}
+
+func (w *SelectWhereNull) Eval(row map[string]any) Truth {
+	return truthOf((row[w.Field] == nil) != w.Not)
+}
+
+func (w *SelectWhereNull) Match(row map[string]any) bool {
+	return w.Eval(row) == True
+}
+
+// compareVal 比较字段值和字面量
+// 字面量无法转换为字段值的类型时，返回 false
+func compareVal(val any, str string) (int, bool) {
+	switch v := val.(type) {
+	case int32:
+		return compareInt(int64(v), str)
+	case int64:
+		return compareInt(v, str)
+	case bool:
+		dst, err := strconv.ParseBool(str)
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case v == dst:
+			return 0, true
+		case dst:
+			return -1, true
+		}
+		return 1, true
+	case float64:
+		dst, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return 0, false
+		}
+		return cmp.Compare(v, dst), true
+	case Dec:
+		dst, err := ParseDecimal(str)
+		if err != nil {
+			return 0, false
+		}
+		return v.Compare(dst), true
+	case time.Time:
+		dst, err := parseTime(str)
+		if err != nil {
+			return 0, false
+		}
+		return v.Compare(dst), true
+	case string:
+		return strings.Compare(v, str), true
+	}
+	return 0, false
+}
+
+// compareInt 比较整数和字面量，字面量为小数时按照浮点数比较
+func compareInt(v int64, str string) (int, bool) {
+	dst, err := strconv.ParseInt(str, 10, 64)
+	if err == nil {
+		return cmp.Compare(v, dst), true
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false
 	}
-	return false
+	return cmp.Compare(float64(v), f), true
 }
 
 type SelectOrder struct {
@@ -1,19 +1,131 @@
 package sql
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"strconv"
+	"strings"
+	"time"
 )
 
-func FormatVal(t string, v string) any {
-	switch typeMapping[t] {
+var ErrInvalidValue = errors.New("invalid value")
+
+// 时间的文本格式
+const (
+	DateFormat      = "2006-01-02"
+	TimestampFormat = "2006-01-02 15:04:05.999999"
+)
+
+// 可以解析的时间格式（TIMESTAMP 使用 UTC 保存）
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	DateFormat,
+}
+
+// FormatVal 将字面量转换为字段类型对应的值
+//
+// INT32：int32
+// INT64：int64
+// VARCHAR：string
+// BOOL：bool
+// DOUBLE：float64
+// DECIMAL：Dec（四舍五入到字段的 Scale）
+// TIMESTAMP：time.Time（UTC，精确到微秒）
+// DATE：time.Time（UTC 零点）
+func FormatVal(t ColumnType, v string) (any, error) {
+	switch t.Type {
 	case Int32:
-		i, _ := strconv.ParseUint(v, 10, 32)
-		return uint32(i)
+		i, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return nil, invalidValue(t, v)
+		}
+		return int32(i), nil
 	case Int64:
-		i, _ := strconv.ParseUint(v, 10, 64)
-		return i
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, invalidValue(t, v)
+		}
+		return i, nil
 	case Varchar:
-		return v
+		return v, nil
+	case Bool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, invalidValue(t, v)
+		}
+		return b, nil
+	case Double:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(f) {
+			return nil, invalidValue(t, v)
+		}
+		return f, nil
+	case Decimal:
+		d, err := ParseDecimal(v)
+		if err == nil {
+			d, err = d.Round(t.Scale)
+		}
+		if err != nil || decimalDigits(d) > t.Precision {
+			return nil, invalidValue(t, v)
+		}
+		return d, nil
+	case Timestamp:
+		ts, err := parseTime(v)
+		if err != nil {
+			return nil, invalidValue(t, v)
+		}
+		return ts.UTC().Truncate(time.Microsecond), nil
+	case Date:
+		ts, err := parseTime(v)
+		if err != nil {
+			return nil, invalidValue(t, v)
+		}
+		return time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+	return nil, invalidValue(t, v)
+}
+
+// FormatText 将字段值转换为文本格式（NULL 为 "NULL"）
+func FormatText(t ColumnType, v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "NULL"
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case time.Time:
+		if t.Type == Date {
+			return val.Format(DateFormat)
+		}
+		return val.Format(TimestampFormat)
+	}
+	return fmt.Sprint(v)
+}
+
+func invalidValue(t ColumnType, v string) error {
+	return fmt.Errorf("%w for %s: %q", ErrInvalidValue, t, v)
+}
+
+// parseTime 解析时间，没有时区的时间使用 UTC
+func parseTime(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	for _, layout := range timeLayouts {
+		t, err := time.Parse(layout, v)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidValue, v)
+}
+
+// decimalDigits 定点数的有效数字的位数
+func decimalDigits(d Dec) int {
+	v := d.Value
+	if v < 0 {
+		v = -v
 	}
-	return nil
+	return len(strconv.FormatInt(v, 10))
 }
@@ -0,0 +1,90 @@
+package sql
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseColumnType(t *testing.T) {
+	for s, want := range map[string]string{
+		"INT":           "INT32",
+		"bigint":        "INT64",
+		"VARCHAR(255)":  "VARCHAR",
+		"BOOLEAN":       "BOOL",
+		"NUMERIC":       "DECIMAL(10,0)",
+		"DECIMAL(8)":    "DECIMAL(8,0)",
+		"DECIMAL(8, 3)": "DECIMAL(8,3)",
+		"DATETIME":      "TIMESTAMP",
+	} {
+		ct, err := ParseColumnType(s)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if ct.String() != want {
+			t.Fatalf("%s: got %s, want %s", s, ct, want)
+		}
+	}
+
+	for _, s := range []string{"FLOAT", "INT(3)", "DECIMAL(19,2)", "DECIMAL(2,3)", "VARCHAR(0)"} {
+		_, err := ParseColumnType(s)
+		if !errors.Is(err, ErrInvalidType) {
+			t.Fatalf("%s: err %v", s, err)
+		}
+	}
+}
+
+func TestFormatVal(t *testing.T) {
+	dec, _ := ParseColumnType("DECIMAL(5,2)")
+	for _, c := range []struct {
+		typ  ColumnType
+		in   string
+		want any
+	}{
+		{ColumnType{Type: Int32}, "-12", int32(-12)},
+		{ColumnType{Type: Int64}, "-9223372036854775808", int64(-9223372036854775808)},
+		{ColumnType{Type: Bool}, "true", true},
+		{ColumnType{Type: Double}, "-1.25", -1.25},
+		{dec, "1.005", Dec{Value: 101, Scale: 2}},
+		{dec, "-999.994", Dec{Value: -99999, Scale: 2}},
+		{ColumnType{Type: Timestamp}, "2024-01-02T03:04:05+08:00", time.Date(2024, 1, 1, 19, 4, 5, 0, time.UTC)},
+		{ColumnType{Type: Date}, "2024-01-02 03:04:05", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+	} {
+		v, err := FormatVal(c.typ, c.in)
+		if err != nil {
+			t.Fatalf("%s %s: %v", c.typ, c.in, err)
+		}
+		if v != c.want {
+			t.Fatalf("%s %s: got %v, want %v", c.typ, c.in, v, c.want)
+		}
+	}
+
+	for _, c := range []struct {
+		typ ColumnType
+		in  string
+	}{
+		{ColumnType{Type: Int32}, "2147483648"},
+		{ColumnType{Type: Int64}, "1.5"},
+		{ColumnType{Type: Bool}, "yes"},
+		{ColumnType{Type: Double}, "NaN"},
+		{dec, "999.995"},
+		{ColumnType{Type: Date}, "2024-13-01"},
+	} {
+		_, err := FormatVal(c.typ, c.in)
+		if !errors.Is(err, ErrInvalidValue) {
+			t.Fatalf("%s %s: err %v", c.typ, c.in, err)
+		}
+	}
+}
+
+func TestDec_Compare(t *testing.T) {
+	a, _ := ParseDecimal("1.50")
+	b, _ := ParseDecimal("1.5")
+	c, _ := ParseDecimal("-0.05")
+	if a.Compare(b) != 0 || c.Compare(b) != -1 || b.Compare(c) != 1 {
+		t.Fatalf("compare %s %s %s", a, b, c)
+	}
+	if a.String() != "1.50" || c.String() != "-0.05" {
+		t.Fatalf("string %s %s", a, c)
+	}
+}
@@ -0,0 +1,129 @@
+package sql
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidDecimal  = errors.New("invalid decimal")
+	ErrDecimalOverflow = errors.New("decimal out of range")
+)
+
+// Dec 定点数
+//
+// 值为 Value / 10^Scale，例如 Value = 12345，Scale = 2 表示 123.45
+// Value 使用 int64 保存，因此最多 18 位有效数字
+//
+// DECIMAL 字段中的值的 Scale 都等于字段的 Scale
+// 因此相同字段的值可以直接比较 Value（索引键也是按照 Value 编码）
+type Dec struct {
+	Value int64
+	Scale int
+}
+
+// ParseDecimal 解析定点数，Scale 为字面量中小数的位数
+func ParseDecimal(s string) (Dec, error) {
+	str := strings.TrimSpace(s)
+	neg := false
+	switch {
+	case strings.HasPrefix(str, "-"):
+		neg = true
+		str = str[1:]
+	case strings.HasPrefix(str, "+"):
+		str = str[1:]
+	}
+
+	ip, fp, _ := strings.Cut(str, ".")
+	if ip == "" && fp == "" {
+		return Dec{}, ErrInvalidDecimal
+	}
+	for _, c := range ip + fp {
+		if c < '0' || c > '9' {
+			return Dec{}, ErrInvalidDecimal
+		}
+	}
+
+	digits := strings.TrimLeft(ip+fp, "0")
+	if len(digits) > maxPrecision {
+		return Dec{}, ErrDecimalOverflow
+	}
+
+	var v int64
+	if digits != "" {
+		v, _ = strconv.ParseInt(digits, 10, 64)
+	}
+	if neg {
+		v = -v
+	}
+	return Dec{Value: v, Scale: len(fp)}, nil
+}
+
+// Round 四舍五入到 scale 位小数
+func (d Dec) Round(scale int) (Dec, error) {
+	if scale >= d.Scale {
+		v := new(big.Int).Mul(big.NewInt(d.Value), pow10(scale-d.Scale))
+		if !v.IsInt64() {
+			return Dec{}, ErrDecimalOverflow
+		}
+		return Dec{Value: v.Int64(), Scale: scale}, nil
+	}
+
+	// 远离零的方向舍入
+	div := pow10(d.Scale - scale).Int64()
+	v := d.Value / div
+	rem := d.Value % div
+	if rem < 0 {
+		rem = -rem
+	}
+	if rem*2 >= div {
+		if d.Value < 0 {
+			v--
+		} else {
+			v++
+		}
+	}
+	return Dec{Value: v, Scale: scale}, nil
+}
+
+// Compare 比较两个定点数的大小
+func (d Dec) Compare(o Dec) int {
+	if d.Scale == o.Scale {
+		switch {
+		case d.Value < o.Value:
+			return -1
+		case d.Value > o.Value:
+			return 1
+		}
+		return 0
+	}
+
+	// 统一小数的位数后比较
+	x := new(big.Int).Mul(big.NewInt(d.Value), pow10(max(d.Scale, o.Scale)-d.Scale))
+	y := new(big.Int).Mul(big.NewInt(o.Value), pow10(max(d.Scale, o.Scale)-o.Scale))
+	return x.Cmp(y)
+}
+
+func (d Dec) String() string {
+	str := strconv.FormatInt(d.Value, 10)
+	if d.Scale == 0 {
+		return str
+	}
+
+	sign := ""
+	if d.Value < 0 {
+		sign = "-"
+		str = str[1:]
+	}
+	if len(str) <= d.Scale {
+		str = strings.Repeat("0", d.Scale-len(str)+1) + str
+	}
+	n := len(str) - d.Scale
+	return sign + str[:n] + "." + str[n:]
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
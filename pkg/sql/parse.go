@@ -2,6 +2,7 @@ package sql
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -21,20 +22,35 @@ func init() {
 	}
 }
 
+// ParseSQL 解析不包含参数占位符的 sql 语句
 func ParseSQL(sql string) (Statement, error) {
+	p, err := Prepare(sql)
+	if err != nil {
+		return nil, err
+	}
+	if p.params > 0 {
+		return nil, ErrHasParams
+	}
+	return p.stmt, nil
+}
+
+func parse(sql string) (Statement, int, error) {
 	lex := &Lexer{
 		sql: sql,
 	}
 
 	r := yyParse(lex)
 	if r != 0 {
-		return nil, fmt.Errorf("parse sql error %v", lex.errs)
+		return nil, 0, fmt.Errorf("parse sql error %v", lex.errs)
 	}
 
 	if len(lex.stmts) == 0 {
-		return nil, fmt.Errorf("parse sql error")
+		return nil, 0, fmt.Errorf("parse sql error")
+	}
+	if lex.marker > 0 && lex.number > 0 {
+		return nil, 0, fmt.Errorf("parse sql error cannot mix ? and $n parameters")
 	}
-	return lex.stmts[0], nil
+	return lex.stmts[0], lex.params, nil
 }
 
 func TrimQuote(str string) (string, error) {
@@ -64,6 +80,10 @@ type Lexer struct {
 	stmts  []Statement
 	offset int
 	errs   []string
+
+	params int // 参数占位符的数量
+	marker int // ? 占位符的数量
+	number int // $n 占位符的数量
 }
 
 func (l *Lexer) Error(s string) {
@@ -114,7 +134,7 @@ func (l *Lexer) Lex(val *yySymType) int {
 						finish++
 					}
 				}
-			case '=', ',', ';', '(', ')':
+			case '=', ',', ';', '(', ')', '?':
 				finish = i
 				if start == finish {
 					finish++
@@ -122,7 +142,9 @@ func (l *Lexer) Lex(val *yySymType) int {
 			}
 		}
 
-		if i == finish {
+		// 已经确定 token 的结束位置
+		// 注意，运算符（例如 >=）的结束位置可能在 i 之后
+		if finish != len(l.sql) {
 			break
 		}
 	}
@@ -140,6 +162,24 @@ func (l *Lexer) Lex(val *yySymType) int {
 	token := l.sql[start:finish]
 	val.str = token
 
+	// 参数占位符
+	// ? 按照出现的顺序编号，$n 使用指定的编号
+	if token == "?" {
+		l.marker++
+		l.params = max(l.params, l.marker)
+		val.str = strconv.Itoa(l.marker)
+		return PARAM
+	}
+	if len(token) > 1 && token[0] == '$' {
+		n, err := strconv.Atoi(token[1:])
+		if err == nil && n > 0 {
+			l.number++
+			l.params = max(l.params, n)
+			val.str = strconv.Itoa(n)
+			return PARAM
+		}
+	}
+
 	num, ok := mapping[token]
 	if ok {
 		return num
@@ -154,3 +194,30 @@ func (l *Lexer) Reduced(_, state int, val *yySymType) bool {
 	}
 	return false
 }
+
+// newJoinOn 创建连接条件，left 和 right 为没有去除引号的原始值
+func newJoinOn(left string, op CompareOperate, right string) (*SelectJoinOn, error) {
+	on := &SelectJoinOn{
+		Operate: op,
+		Literal: isLiteral(left) || isLiteral(right),
+	}
+	var err error
+	on.Left, err = TrimQuote(left)
+	if err != nil {
+		return nil, err
+	}
+	on.Right, err = TrimQuote(right)
+	if err != nil {
+		return nil, err
+	}
+	return on, nil
+}
+
+// isLiteral 原始值是否为常量（使用单引号或者双引号的字符串，或者数字）
+func isLiteral(str string) bool {
+	if str[0] == '\'' || str[0] == '"' {
+		return true
+	}
+	_, err := strconv.ParseFloat(str, 64)
+	return err == nil
+}
@@ -4,6 +4,7 @@ import (
 	_ "embed"
 
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	"github.com/ggymm/db/test"
@@ -17,6 +18,20 @@ func TestParseSQL_Tx(t *testing.T) {
 	s, _ := json.MarshalIndent(stmt, "", "  ")
 	t.Logf("%s", s)
 
+	for sql, level := range map[string]string{
+		"BEGIN;":                 "",
+		"begin read committed;":  "read committed",
+		"BEGIN REPEATABLE READ;": "REPEATABLE READ",
+	} {
+		stmt, err = ParseSQL(sql)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		if stmt.(*BeginStmt).Level != level {
+			t.Fatalf("level %s", stmt.(*BeginStmt).Level)
+		}
+	}
+
 	stmt, err = ParseSQL("COMMIT;")
 	if err != nil {
 		t.Fatalf("%+v", err)
@@ -77,6 +92,124 @@ func TestParseSQL_Select(t *testing.T) {
 	t.Logf("%s", s)
 }
 
+func TestParseSQL_SelectField(t *testing.T) {
+	stmt, err := ParseSQL("SELECT *, name AS n, `age` as a FROM user;")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	want := []*SelectField{{Name: "*"}, {Name: "name", Alias: "n"}, {Name: "age", Alias: "a"}}
+	if got := stmt.(*SelectStmt).Field; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestParseSQL_SelectGroup(t *testing.T) {
+	stmt, err := ParseSQL("SELECT city, count(*) AS n, SUM(`age`) FROM user WHERE age > 1 GROUP BY city HAVING COUNT(*) > 2 ORDER BY sum(age) DESC LIMIT 5;")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	got := stmt.(*SelectStmt)
+	want := []*SelectField{{Name: "city"}, {Name: "*", Alias: "n", Func: FuncCount}, {Name: "age", Func: FuncSum}}
+	if !reflect.DeepEqual(got.Field, want) {
+		t.Fatalf("got field %+v", got.Field)
+	}
+	if !reflect.DeepEqual(got.Group, []string{"city"}) {
+		t.Fatalf("got group %+v", got.Group)
+	}
+	if len(got.Having) != 1 || got.Having[0].(*SelectWhereField).Field != "COUNT(*)" {
+		t.Fatalf("got having %+v", got.Having)
+	}
+	if !reflect.DeepEqual(got.Order, []*SelectOrder{{Field: "SUM(age)"}}) {
+		t.Fatalf("got order %+v", got.Order)
+	}
+
+	if _, err = ParseSQL("SELECT LEN(name) FROM user;"); err == nil {
+		t.Fatalf("unknown function should fail")
+	}
+}
+
+func TestParseSQL_SelectJoin(t *testing.T) {
+	stmt, err := ParseSQL("SELECT u.name, o.amount FROM user u JOIN `order` AS o ON u.id = o.uid AND o.day >= u.day LEFT OUTER JOIN city ON city.id = u.city INNER JOIN tag WHERE o.amount > 10;")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	got := stmt.(*SelectStmt)
+	if got.Table != "user" || got.Alias != "u" {
+		t.Fatalf("got table %s %s", got.Table, got.Alias)
+	}
+	want := []*SelectJoin{
+		{Table: "order", Alias: "o", On: []*SelectJoinOn{{Left: "u.id", Right: "o.uid"}, {Left: "o.day", Right: "u.day", Operate: GE}}},
+		{Left: true, Table: "city", On: []*SelectJoinOn{{Left: "city.id", Right: "u.city"}}},
+		{Table: "tag"},
+	}
+	if !reflect.DeepEqual(got.Join, want) {
+		s, _ := json.Marshal(got.Join)
+		t.Fatalf("got join %s", s)
+	}
+	if len(got.Where) != 1 || got.Where[0].(*SelectWhereField).Field != "o.amount" {
+		t.Fatalf("got where %+v", got.Where)
+	}
+
+	// 连接条件中的常量
+	for in, want := range map[string]bool{
+		"SELECT * FROM a JOIN b ON a.id = b.id AND b.y = 'y2';": true,
+		"SELECT * FROM a JOIN b ON a.id = 5;":                   true,
+		"SELECT * FROM a JOIN b ON a.id = b.id;":                false,
+	} {
+		stmt, err = ParseSQL(in)
+		if err != nil {
+			t.Fatalf("%s: %+v", in, err)
+		}
+		on := stmt.(*SelectStmt).Join[0].On
+		if on[len(on)-1].Literal != want {
+			t.Fatalf("%s: literal %v", in, on[len(on)-1].Literal)
+		}
+	}
+}
+
+func TestParseSQL_SelectOrder(t *testing.T) {
+	for str, want := range map[string]*SelectStmt{
+		"SELECT * FROM user ORDER BY age DESC, id;": {
+			Order: []*SelectOrder{{Field: "age"}, {Field: "id", Asc: true}},
+		},
+		"SELECT * FROM user ORDER BY age ASC LIMIT 10;": {
+			Order: []*SelectOrder{{Field: "age", Asc: true}},
+			Limit: &SelectLimit{Limit: 10},
+		},
+		"SELECT * FROM user LIMIT 10 OFFSET 20;": {Limit: &SelectLimit{Limit: 10, Offset: 20}},
+		"SELECT * FROM user LIMIT 20, 10;":       {Limit: &SelectLimit{Limit: 10, Offset: 20}},
+	} {
+		stmt, err := ParseSQL(str)
+		if err != nil {
+			t.Fatalf("%s: %+v", str, err)
+		}
+		got := stmt.(*SelectStmt)
+		if !reflect.DeepEqual(got.Order, want.Order) || !reflect.DeepEqual(got.Limit, want.Limit) {
+			t.Fatalf("%s: got %+v %+v", str, got.Order, got.Limit)
+		}
+	}
+}
+
+func TestParseSQL_SelectCompare(t *testing.T) {
+	stmt, err := ParseSQL(`select * from user where a >= 1 and b<=2 and c != 3 and d>4;`)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	s, _ := json.MarshalIndent(stmt, "", "  ")
+	t.Logf("%s", s)
+
+	ops := []CompareOperate{GE, LE, NE, GT}
+	where := stmt.(*SelectStmt).Where
+	if len(where) != len(ops) {
+		t.Fatalf("parse where error %d", len(where))
+	}
+	for i, w := range where {
+		if w.(*SelectWhereField).Operate != ops[i] {
+			t.Fatalf("parse operate error %d %v", i, w)
+		}
+	}
+}
+
 func TestParseSQL_SelectWhere(t *testing.T) {
 	stmt, err := ParseSQL(test.SelectSQL)
 	if err != nil {
@@ -115,3 +248,148 @@ func TestParseSQL_SelectWhere(t *testing.T) {
 
 	t.Logf("indexes: %+v", indexes)
 }
+
+func TestParseSQL_SelectNull(t *testing.T) {
+	row := map[string]any{"id": int64(1), "age": nil, "name": ""}
+	for where, want := range map[string]Truth{
+		"age = 1":                      Unknown,
+		"age != 1":                     Unknown,
+		"id = NULL":                    Unknown,
+		"age IS NULL":                  True,
+		"age is not null":              False,
+		"name IS NULL":                 False,
+		"age = 1 OR id = 1":            True,
+		"age = 1 OR id = 2":            Unknown,
+		"age = 1 AND id = 2":           False,
+		"id = 2 OR (age > 1 AND id=1)": Unknown,
+	} {
+		stmt, err := ParseSQL("SELECT * FROM user WHERE " + where + ";")
+		if err != nil {
+			t.Fatalf("%s: %+v", where, err)
+		}
+		got := True
+		for _, w := range stmt.(*SelectStmt).Where {
+			got = got.And(w.Eval(row))
+		}
+		if got != want {
+			t.Fatalf("%s: got %d, want %d", where, got, want)
+		}
+	}
+
+	p, err := Prepare("UPDATE user SET name = ? WHERE id = ?;")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	stmt, err := p.Bind(nil, 1)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if !stmt.(*UpdateStmt).Value["name"].Null {
+		t.Fatalf("nil should bind to NULL")
+	}
+}
+
+func TestParseSQL_Alter(t *testing.T) {
+	for str, want := range map[string]AlterStmt{
+		"ALTER TABLE user ADD COLUMN age INT32 NOT NULL DEFAULT '1';": {Table: "user", Action: AddColumn},
+		"alter table user add age int32;":                             {Table: "user", Action: AddColumn},
+		"ALTER TABLE user DROP COLUMN age;":                           {Table: "user", Action: DropColumn, Name: "age"},
+		"ALTER TABLE user DROP age;":                                  {Table: "user", Action: DropColumn, Name: "age"},
+		"ALTER TABLE user RENAME COLUMN age TO years;":                {Table: "user", Action: RenameColumn, Name: "age", NewName: "years"},
+		"ALTER TABLE user RENAME TO member;":                          {Table: "user", Action: RenameTable, NewName: "member"},
+	} {
+		stmt, err := ParseSQL(str)
+		if err != nil {
+			t.Fatalf("%s: %+v", str, err)
+		}
+		got := *stmt.(*AlterStmt)
+		if got.Action == AddColumn {
+			if got.Field == nil || got.Field.Name != "age" || got.Field.Type.Type != Int32 {
+				t.Fatalf("%s: field %+v", str, got.Field)
+			}
+			got.Field = nil
+		}
+		if got != want {
+			t.Fatalf("%s: got %+v, want %+v", str, got, want)
+		}
+	}
+}
+
+func TestParseSQL_Drop(t *testing.T) {
+	for str, want := range map[string]Statement{
+		"DROP TABLE user;":           &DropStmt{Table: "user"},
+		"drop table if exists user;": &DropStmt{Table: "user", IfExists: true},
+		"TRUNCATE TABLE user;":       &TruncateStmt{Table: "user"},
+
+		"CREATE INDEX age_idx ON user (age);":              &CreateIndexStmt{Name: "age_idx", Table: "user", Field: []string{"age"}},
+		"create unique index name_uni on user(name);":      &CreateIndexStmt{Name: "name_uni", Table: "user", Field: []string{"name"}, Unique: true},
+		"CREATE INDEX idx ON log (tenant_id, created_at);": &CreateIndexStmt{Name: "idx", Table: "log", Field: []string{"tenant_id", "created_at"}},
+		"DROP INDEX age_idx;":                              &DropIndexStmt{Name: "age_idx"},
+		"DROP INDEX age_idx ON user;":                      &DropIndexStmt{Name: "age_idx", Table: "user"},
+		"ANALYZE TABLE user;":                              &AnalyzeStmt{Table: "user"},
+	} {
+		stmt, err := ParseSQL(str)
+		if err != nil {
+			t.Fatalf("%s: %+v", str, err)
+		}
+		if !reflect.DeepEqual(stmt, want) {
+			t.Fatalf("%s: got %+v, want %+v", str, stmt, want)
+		}
+	}
+}
+
+func TestPrepare_Bind(t *testing.T) {
+	_, err := Prepare(`select * from user where id = ? and name != $2;`)
+	if err == nil {
+		t.Fatalf("mix ? and $n should fail")
+	}
+	p, err := Prepare(`select * from user where id = $1 and name != $2 or age > $1;`)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if p.NumParams() != 2 {
+		t.Fatalf("params %d", p.NumParams())
+	}
+	_, err = ParseSQL(`select * from user where id = ?;`)
+	if err == nil {
+		t.Fatalf("parse sql with params should fail")
+	}
+	_, err = p.Bind(1)
+	if err == nil {
+		t.Fatalf("bind should fail")
+	}
+
+	// 同一个预备语句可以重复绑定
+	for i := 0; i < 2; i++ {
+		stmt, err := p.Bind(int64(i), "a'b")
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		s, _ := json.MarshalIndent(stmt, "", "  ")
+		t.Logf("%s", s)
+
+		row := map[string]any{"id": int64(i), "name": "a'b", "age": int64(i)}
+		if stmt.(*SelectStmt).Where[0].Match(row) {
+			t.Fatalf("row %v should not match", row)
+		}
+		row["age"] = int64(i + 1)
+		if !stmt.(*SelectStmt).Where[0].Match(row) {
+			t.Fatalf("row %v should match", row)
+		}
+	}
+
+	p, err = Prepare(`insert into user (id, name) value (?, ?);`)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	stmt, err := p.Bind(1, "'; drop table user;")
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	if v := stmt.(*InsertStmt).Value; v[0].Str != "1" || v[1].Str != "'; drop table user;" {
+		t.Fatalf("value %v %v", v[0], v[1])
+	}
+	if p.Statement().(*InsertStmt).Value[0].Param != 1 {
+		t.Fatalf("prepared statement should not be modified")
+	}
+}
@@ -0,0 +1,176 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrHasParams     = errors.New("statement has parameters, use Prepare and Bind")
+	ErrParamMismatch = errors.New("mismatch between number of parameters and arguments")
+)
+
+// Prepared 预备语句
+//
+// 语句只会被解析一次，执行时通过 Bind 绑定参数得到新的语句
+// Bind 不会修改预备语句，同一个预备语句可以被重复绑定（并发安全）
+//
+// 参数占位符：
+// ?   按照出现的顺序编号
+// $n  第 n 个参数（从 1 开始）
+//
+// 参数只能出现在值的位置（插入的值、更新的值、查询条件的值）
+type Prepared struct {
+	stmt   Statement
+	params int
+}
+
+// Prepare 解析包含参数占位符的 sql 语句
+func Prepare(sql string) (*Prepared, error) {
+	stmt, params, err := parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	return &Prepared{
+		stmt:   stmt,
+		params: params,
+	}, nil
+}
+
+// NumParams 参数的数量
+func (p *Prepared) NumParams() int {
+	return p.params
+}
+
+// Statement 未绑定参数的语句，只能用于获取语句的类型和表名
+func (p *Prepared) Statement() Statement {
+	return p.stmt
+}
+
+// Bind 绑定参数，返回可以执行的语句
+//
+// 支持的参数类型：
+// 整数、浮点数、bool、string、[]byte、time.Time
+// nil 绑定为 NULL
+func (p *Prepared) Bind(args ...any) (Statement, error) {
+	if len(args) != p.params {
+		return nil, fmt.Errorf("%w: want %d, got %d", ErrParamMismatch, p.params, len(args))
+	}
+	if p.params == 0 {
+		return p.stmt, nil
+	}
+
+	vals := make([]*Value, 0, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			vals = append(vals, &Value{Null: true})
+			continue
+		}
+		v, err := formatArg(arg)
+		if err != nil {
+			return nil, fmt.Errorf("parameter $%d: %w", i+1, err)
+		}
+		vals = append(vals, &Value{Str: v})
+	}
+
+	switch s := p.stmt.(type) {
+	case *InsertStmt:
+		stmt := *s
+		stmt.Value = make([]*Value, 0, len(s.Value))
+		for _, v := range s.Value {
+			stmt.Value = append(stmt.Value, bindValue(v, vals))
+		}
+		return &stmt, nil
+	case *UpdateStmt:
+		stmt := *s
+		stmt.Value = make(map[string]*Value, len(s.Value))
+		for k, v := range s.Value {
+			stmt.Value[k] = bindValue(v, vals)
+		}
+		stmt.Where = bindWhere(s.Where, vals)
+		return &stmt, nil
+	case *DeleteStmt:
+		stmt := *s
+		stmt.Where = bindWhere(s.Where, vals)
+		return &stmt, nil
+	case *SelectStmt:
+		stmt := *s
+		stmt.Where = bindWhere(s.Where, vals)
+		stmt.Having = bindWhere(s.Having, vals)
+		return &stmt, nil
+	}
+	return p.stmt, nil
+}
+
+func bindValue(v *Value, vals []*Value) *Value {
+	if v.Param == 0 {
+		return v
+	}
+	return vals[v.Param-1]
+}
+
+// bindWhere 复制查询条件并绑定参数
+func bindWhere(where []SelectWhere, vals []*Value) []SelectWhere {
+	if where == nil {
+		return nil
+	}
+
+	ret := make([]SelectWhere, 0, len(where))
+	for _, w := range where {
+		switch cond := w.(type) {
+		case *SelectWhereExpr:
+			ret = append(ret, &SelectWhereExpr{
+				Negation: cond.Negation,
+				Cnf:      bindWhere(cond.Cnf, vals),
+			})
+		case *SelectWhereField:
+			field := *cond
+			field.Value = bindValue(cond.Value, vals)
+			ret = append(ret, &field)
+		default:
+			ret = append(ret, w)
+		}
+	}
+	return ret
+}
+
+// formatArg 将参数转换为字面量
+func formatArg(arg any) (string, error) {
+	switch v := arg.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	}
+	return "", fmt.Errorf("unsupported type %T", arg)
+}
@@ -11,9 +11,12 @@ import (
 	str string
 	strList []string
 	boolean bool
-	fieldType FieldType
+	fieldType ColumnType
 	compareOperate CompareOperate
 
+	value *Value
+	valueList []*Value
+
 	stmt Statement
 	stmtList []Statement
 
@@ -24,18 +27,32 @@ import (
 	createStmt *CreateStmt
 	createTable *CreateTable
 	createField *CreateField
+	createDefault *CreateDefault
 	createIndex *CreateIndex
 	createTableOption *CreateTableOption
 
+	alterStmt *AlterStmt
+	dropStmt *DropStmt
+	truncateStmt *TruncateStmt
+	createIndexStmt *CreateIndexStmt
+	dropIndexStmt *DropIndexStmt
+	analyzeStmt *AnalyzeStmt
+
 	insertStmt *InsertStmt
 
     updateStmt *UpdateStmt
-    updateValue map[string]string
+    updateValue map[string]*Value
     
     deleteStmt *DeleteStmt
 
 	selectStmt *SelectStmt
+	selectJoin *SelectJoin
+	selectJoinList []*SelectJoin
+	selectJoinOn *SelectJoinOn
+	selectJoinOnList []*SelectJoinOn
+	selectField *SelectField
 	selectFieldList []*SelectField
+	selectWhere SelectWhere
 	selectWhereList []SelectWhere
 	selectOrderList []*SelectOrder
 	selectLimit *SelectLimit
@@ -54,7 +71,25 @@ import (
 	NULL "NULL"
 	INDEX "INDEX"
 	DEFAULT "DEFAULT"
+	CURRENT_TIMESTAMP "CURRENT_TIMESTAMP"
+	AUTO_INCREMENT "AUTO_INCREMENT"
 	PRIMARY "PRIMARY"
+	// 关键字（修改表）
+	ALTER "ALTER"
+	ADD "ADD"
+	COLUMN "COLUMN"
+	DROP "DROP"
+	RENAME "RENAME"
+	TO "TO"
+	// 关键字（删除表）
+	IF "IF"
+	EXISTS "EXISTS"
+	TRUNCATE "TRUNCATE"
+	// 关键字（索引）
+	UNIQUE "UNIQUE"
+	ON "ON"
+	// 关键字（统计信息）
+	ANALYZE "ANALYZE"
 	// 关键字（插入数据）
 	INSERT "INSERT"
 	INTO "INTO"
@@ -66,10 +101,18 @@ import (
 	DELETE "DELETE"
 	// 关键字（查询表）
 	SELECT "SELECT"
+	AS "AS"
 	FROM "FROM"
+	JOIN "JOIN"
+	INNER "INNER"
+	LEFT "LEFT"
+	OUTER "OUTER"
 	WHERE "WHERE"
+	IS "IS"
 	OR "OR"
 	AND "AND"
+	GROUP "GROUP"
+	HAVING "HAVING"
 	ORDER "ORDER"
 	BY "BY"
 	ASC "ASC"
@@ -83,16 +126,19 @@ import (
 	COMP_GE ">="
 
 %token <str> VARIABLE
+%token <str> PARAM
 
 %type <str> Expr
 %type <strList> VaribleList
+%type <value> Value
+%type <valueList> ValueList
 
 %type <stmt> Stmt
 %type <stmtList> StmtList
 
 // 语法定义（创建表）
-%type <str> Default
-%type <boolean> Nullable
+%type <createDefault> Default
+%type <boolean> Nullable AutoIncrement
 %type <fieldType> FieldType
 
 %type <beginStmt> BeginStmt
@@ -106,10 +152,26 @@ import (
 %type <createIndex> CreatePrimary
 %type <createTableOption> CreateTableOption
 
+// 语法定义（修改表）
+%type <alterStmt> AlterStmt AlterAction
+
+// 语法定义（删除表）
+%type <boolean> IfExists
+%type <dropStmt> DropStmt
+%type <truncateStmt> TruncateStmt
+
+// 语法定义（索引）
+%type <boolean> Unique
+%type <createIndexStmt> CreateIndexStmt
+%type <dropIndexStmt> DropIndexStmt
+
+// 语法定义（统计信息）
+%type <analyzeStmt> AnalyzeStmt
+
 // 语法定义（插入数据）
 %type <insertStmt> InsertStmt
 %type <strList> InsertField InsertFieldList
-%type <strList> InsertValue InsertValueList
+%type <valueList> InsertValue InsertValueList
 
 // 语法定义（更新数据）
 %type <updateStmt> UpdateStmt
@@ -124,7 +186,17 @@ import (
 
 %type <selectStmt> SelectStmt
 %type <selectFieldList> SelectFieldList
-%type <selectWhereList> SelectWhere SelectWhereList
+%type <selectField> SelectField
+%type <selectWhere> SelectCond
+%type <selectWhereList> SelectWhere SelectWhereList SelectHaving
+%type <strList> SelectGroup
+%type <str> SelectColumn
+%type <strList> SelectTable
+%type <boolean> JoinType
+%type <selectJoin> SelectJoin
+%type <selectJoinList> SelectJoinList
+%type <selectJoinOn> JoinCond
+%type <selectJoinOnList> JoinOn JoinCondList
 %type <selectOrderList> SelectOrder SelectOrderList
 %type <selectLimit> SelectLimit
 
@@ -161,6 +233,31 @@ VaribleList:
 		$$ = append($1, $3)
 	}
 
+Value:
+	Expr
+	{
+		$$ = &Value{ Str: $1 }
+	}
+	| "NULL"
+	{
+		$$ = &Value{ Null: true }
+	}
+	| PARAM
+	{
+		n, _ := strconv.Atoi($1)
+		$$ = &Value{ Param: n }
+	}
+
+ValueList:
+	Value
+	{
+		$$ = []*Value{ $1 }
+	}
+	| ValueList ',' Value
+	{
+		$$ = append($1, $3)
+	}
+
 Stmt:
 	BeginStmt
 	{
@@ -178,6 +275,30 @@ Stmt:
 	{
 		$$ = Statement($1)
 	}
+	| AlterStmt
+	{
+		$$ = Statement($1)
+	}
+	| DropStmt
+	{
+		$$ = Statement($1)
+	}
+	| TruncateStmt
+	{
+		$$ = Statement($1)
+	}
+	| CreateIndexStmt
+	{
+		$$ = Statement($1)
+	}
+	| DropIndexStmt
+	{
+		$$ = Statement($1)
+	}
+	| AnalyzeStmt
+	{
+		$$ = Statement($1)
+	}
 	| SelectStmt
 	{
 		$$ = Statement($1)
@@ -208,20 +329,30 @@ StmtList:
 // 语法规则（创建表）
 Default:
 	{
-		$$ = ""
+		$$ = nil
 	}
 	| "DEFAULT"
 	{
-		$$ = ""
+		$$ = nil
 	}
 	| "DEFAULT" "NULL"
 	{
-		$$ = ""
+		$$ = &CreateDefault{
+			Value: &Value{ Null: true },
+		}
 	}
 	| "DEFAULT" Expr
-   	{
-	   	$$ = $2
-   	}
+	{
+		$$ = &CreateDefault{
+			Value: &Value{ Str: $2 },
+		}
+	}
+	| "DEFAULT" "CURRENT_TIMESTAMP"
+	{
+		$$ = &CreateDefault{
+			Expr: CurrentTimestamp,
+		}
+	}
 
 Nullable:
 	{
@@ -236,23 +367,57 @@ Nullable:
 		$$ = false
 	}
 
+AutoIncrement:
+	{
+		$$ = false
+	}
+	| "AUTO_INCREMENT"
+	{
+		$$ = true
+	}
+
 FieldType:
 	Expr
 	{
-		t, ok := typeMapping[$1]
-		if ok {
-			$$ = t
-		} else {
-			__yyfmt__.Printf("不支持的数据类型 %s",$1)
+		t, err := newColumnType($1)
+		if err != nil {
+			yylex.Error(err.Error())
+			goto ret1
+		}
+		$$ = t
+	}
+	| Expr '(' Expr ')'
+	{
+		t, err := newColumnType($1, $3)
+		if err != nil {
+			yylex.Error(err.Error())
 			goto ret1
 		}
+		$$ = t
+	}
+	| Expr '(' Expr ',' Expr ')'
+	{
+		t, err := newColumnType($1, $3, $5)
+		if err != nil {
+			yylex.Error(err.Error())
+			goto ret1
+		}
+		$$ = t
 	}
 
 BeginStmt:
-    "BEGIN" Expr ';'
+    "BEGIN" ';'
+    {
+        $$ = &BeginStmt{ "" }
+    }
+    | "BEGIN" Expr ';'
     {
         $$ = &BeginStmt{ $2 }
     }
+    | "BEGIN" Expr Expr ';'
+    {
+        $$ = &BeginStmt{ $2 + " " + $3 }
+    }
 
 CommitStmt:
     "COMMIT" ';'
@@ -318,18 +483,19 @@ CreateTable:
 	}
 
 CreateField:
-	Expr FieldType Nullable Default
+	Expr FieldType Nullable Default AutoIncrement
 	{
 		$$ = &CreateField{
 			Name: $1,
 			Type: $2,
 			Default: $4,
 			Nullable: $3,
+			AutoIncrement: $5,
 		}
 	}
 
 CreateIndex:
-	"INDEX" Expr '(' Expr ')'
+	"INDEX" Expr '(' VaribleList ')'
 	{
 		$$ = &CreateIndex{
 			Name: $2,
@@ -338,7 +504,7 @@ CreateIndex:
 	}
 
 CreatePrimary:
-	"PRIMARY" "KEY" '(' Expr ')'
+	"PRIMARY" "KEY" '(' VaribleList ')'
 	{
 		$$ = &CreateIndex{
 			Pk: true,
@@ -351,6 +517,131 @@ CreateTableOption:
 		$$ = nil
 	}
 
+// 语法规则（修改表）
+AlterStmt:
+	"ALTER" "TABLE" Expr AlterAction ';'
+	{
+		$$ = $4
+		$$.Table = $3
+	}
+
+AlterAction:
+	"ADD" CreateField
+	{
+		$$ = &AlterStmt{
+			Action: AddColumn,
+			Field: $2,
+		}
+	}
+	| "ADD" "COLUMN" CreateField
+	{
+		$$ = &AlterStmt{
+			Action: AddColumn,
+			Field: $3,
+		}
+	}
+	| "DROP" Expr
+	{
+		$$ = &AlterStmt{
+			Action: DropColumn,
+			Name: $2,
+		}
+	}
+	| "DROP" "COLUMN" Expr
+	{
+		$$ = &AlterStmt{
+			Action: DropColumn,
+			Name: $3,
+		}
+	}
+	| "RENAME" "COLUMN" Expr "TO" Expr
+	{
+		$$ = &AlterStmt{
+			Action: RenameColumn,
+			Name: $3,
+			NewName: $5,
+		}
+	}
+	| "RENAME" "TO" Expr
+	{
+		$$ = &AlterStmt{
+			Action: RenameTable,
+			NewName: $3,
+		}
+	}
+
+// 语法规则（删除表）
+IfExists:
+	{
+		$$ = false
+	}
+	| "IF" "EXISTS"
+	{
+		$$ = true
+	}
+
+DropStmt:
+	"DROP" "TABLE" IfExists Expr ';'
+	{
+		$$ = &DropStmt{
+			Table: $4,
+			IfExists: $3,
+		}
+	}
+
+TruncateStmt:
+	"TRUNCATE" "TABLE" Expr ';'
+	{
+		$$ = &TruncateStmt{
+			Table: $3,
+		}
+	}
+
+// 语法规则（索引）
+Unique:
+	{
+		$$ = false
+	}
+	| "UNIQUE"
+	{
+		$$ = true
+	}
+
+CreateIndexStmt:
+	"CREATE" Unique "INDEX" Expr "ON" Expr '(' VaribleList ')' ';'
+	{
+		$$ = &CreateIndexStmt{
+			Name: $4,
+			Table: $6,
+			Field: $8,
+			Unique: $2,
+		}
+	}
+
+DropIndexStmt:
+	"DROP" "INDEX" Expr ';'
+	{
+		$$ = &DropIndexStmt{
+			Name: $3,
+		}
+	}
+	| "DROP" "INDEX" Expr "ON" Expr ';'
+	{
+		$$ = &DropIndexStmt{
+			Name: $3,
+			Table: $5,
+		}
+	}
+
+// 语法规则（统计信息）
+AnalyzeStmt:
+	"ANALYZE" "TABLE" Expr ';'
+	{
+		$$ = &AnalyzeStmt{
+			Table: $3,
+		}
+	}
+
 // 语法规则（插入数据）
 InsertStmt:
 	"INSERT" "INTO" Expr InsertField InsertValue ';'
@@ -384,7 +675,7 @@ InsertValueList:
 	{
 		$$ = nil
 	}
-	| VaribleList
+	| ValueList
 
 // 语法规则（更新数据）
 UpdateStmt:
@@ -398,13 +689,13 @@ UpdateStmt:
 	}
 
 UpdateValue:
-	Expr '=' Expr
+	Expr '=' Value
 	{
-		$$ = map[string]string{
+		$$ = map[string]*Value{
 			$1: $3,
 		}
 	}
-	| UpdateValue ',' Expr '=' Expr
+	| UpdateValue ',' Expr '=' Value
 	{
 		$$[$3] = $5
 	}
@@ -466,33 +757,170 @@ SelectStmt:
         	Limit: $3,
         }
     }
-    |  "SELECT" SelectFieldList "FROM" Expr SelectWhere SelectOrder SelectLimit ';'
+    |  "SELECT" SelectFieldList "FROM" SelectTable SelectJoinList SelectWhere SelectGroup SelectHaving SelectOrder SelectLimit ';'
     {
         $$ = &SelectStmt{
-        	Table: $4,
+        	Table: $4[0],
+        	Alias: $4[1],
+        	Join: $5,
         	Field: $2,
-        	Where: $5,
-        	Order: $6,
-        	Limit: $7,
+        	Where: $6,
+        	Group: $7,
+        	Having: $8,
+        	Order: $9,
+        	Limit: $10,
         }
     }
 
+// 表名和别名
+SelectTable:
+	Expr
+	{
+		$$ = []string{$1, ""}
+	}
+	| Expr Expr
+	{
+		$$ = []string{$1, $2}
+	}
+	| Expr "AS" Expr
+	{
+		$$ = []string{$1, $3}
+	}
+
+SelectJoinList:
+	{
+		$$ = nil
+	}
+	| SelectJoinList SelectJoin
+	{
+		$$ = append($1, $2)
+	}
+
+SelectJoin:
+	JoinType "JOIN" SelectTable JoinOn
+	{
+		$$ = &SelectJoin{
+			Left: $1,
+			Table: $3[0],
+			Alias: $3[1],
+			On: $4,
+		}
+	}
+
+// LEFT JOIN 时为 true
+JoinType:
+	{
+		$$ = false
+	}
+	| "INNER"
+	{
+		$$ = false
+	}
+	| "LEFT"
+	{
+		$$ = true
+	}
+	| "LEFT" "OUTER"
+	{
+		$$ = true
+	}
+
+JoinOn:
+	{
+		$$ = nil
+	}
+	| "ON" JoinCondList
+	{
+		$$ = $2
+	}
+
+JoinCondList:
+	JoinCond
+	{
+		$$ = []*SelectJoinOn{$1}
+	}
+	| JoinCondList AND JoinCond
+	{
+		$$ = append($1, $3)
+	}
+
+JoinCond:
+	VARIABLE CompareOperate VARIABLE
+	{
+		on, err := newJoinOn($1, $2, $3)
+		if err != nil {
+			yylex.Error(err.Error())
+			goto ret1
+		}
+		$$ = on
+	}
+
 SelectFieldList:
+   SelectField
+   {
+	   $$ = []*SelectField{$1}
+   }
+   | SelectFieldList ',' SelectField
+   {
+	   $$ = append($1, $3)
+   }
+
+SelectField:
    Expr
    {
-	   $$ = []*SelectField{
-		   &SelectField{
-			   Name: $1,
-		   },
+	   $$ = &SelectField{
+		   Name: $1,
+	   }
+   }
+   | Expr "AS" Expr
+   {
+	   $$ = &SelectField{
+		   Name: $1,
+		   Alias: $3,
+	   }
+   }
+   | Expr '(' Expr ')'
+   {
+	   fn, err := ParseFunc($1)
+	   if err != nil {
+		   yylex.Error(err.Error())
+		   goto ret1
+	   }
+	   $$ = &SelectField{
+		   Name: $3,
+		   Func: fn,
 	   }
    }
-   | SelectFieldList ',' Expr
+   | Expr '(' Expr ')' "AS" Expr
    {
-	   $$ = append($1, &SelectField{
+	   fn, err := ParseFunc($1)
+	   if err != nil {
+		   yylex.Error(err.Error())
+		   goto ret1
+	   }
+	   $$ = &SelectField{
 		   Name: $3,
-	   })
+		   Alias: $6,
+		   Func: fn,
+	   }
    }
 
+// 条件和排序中的字段（聚合函数使用 COUNT(*) 形式的名称）
+SelectColumn:
+	Expr
+	{
+		$$ = $1
+	}
+	| Expr '(' Expr ')'
+	{
+		fn, err := ParseFunc($1)
+		if err != nil {
+			yylex.Error(err.Error())
+			goto ret1
+		}
+		$$ = FuncName(fn, $3)
+	}
+
 SelectWhere:
 	{
         $$ = nil
@@ -502,29 +930,41 @@ SelectWhere:
         $$ = $2
     }
 
-SelectWhereList:
-	Expr CompareOperate Expr
+SelectCond:
+	SelectColumn CompareOperate Value
 	{
-		$$ = []SelectWhere{
-			&SelectWhereField{
-				Field: $1,
-				Value: $3,
-				Operate: $2,
-			},
+		$$ = &SelectWhereField{
+			Field: $1,
+			Value: $3,
+			Operate: $2,
 		}
 	}
-	// A OR B == !(!A AND !B)
-	| SelectWhereList OR Expr CompareOperate Expr %prec OR
+	| SelectColumn "IS" "NULL"
 	{
-		$4.Negate()
-		field := &SelectWhereField{
-			Field: $3,
-			Value: $5,
-			Operate: $4,
+		$$ = &SelectWhereNull{
+			Field: $1,
+		}
+	}
+	| SelectColumn "IS" "NOT" "NULL"
+	{
+		$$ = &SelectWhereNull{
+			Field: $1,
+			Not: true,
 		}
+	}
+
+SelectWhereList:
+	SelectCond
+	{
+		$$ = []SelectWhere{ $1 }
+	}
+	// A OR B == !(!A AND !B)
+	| SelectWhereList OR SelectCond %prec OR
+	{
+		$3.Negate()
 		if len($$) == 1 {
 			$$[0].Negate()
-			$$ = append($$, field)
+			$$ = append($$, $3)
 			$$ = []SelectWhere{
 				&SelectWhereExpr{
 					Negation: true,
@@ -540,20 +980,16 @@ SelectWhereList:
 							Negation: true,
 							Cnf: $$,
 						},
-						field,
+						$3,
 					},
 				},
 			}
 		}
 	}
 	// A AND B
-	| SelectWhereList AND Expr CompareOperate Expr %prec AND
+	| SelectWhereList AND SelectCond %prec AND
 	{
-		$$ = append($$, &SelectWhereField{
-			Field: $3,
-			Value: $5,
-			Operate: $4,
-		})
+		$$ = append($$, $3)
 	}
 	// A OR (B...) == !(!A AND !(B...))
 	| SelectWhereList OR '(' SelectWhereList ')' %prec OR
@@ -592,6 +1028,24 @@ SelectWhereList:
 		$$ = append($$, $4...)
 	}
 
+SelectGroup:
+	{
+		$$ = nil
+	}
+	| "GROUP" "BY" VaribleList
+	{
+		$$ = $3
+	}
+
+SelectHaving:
+	{
+		$$ = nil
+	}
+	| "HAVING" SelectWhereList
+	{
+		$$ = $2
+	}
+
 SelectOrder:
 	{
 		$$ = nil
@@ -602,7 +1056,7 @@ SelectOrder:
 	}
 
 SelectOrderList:
-	Expr Ascend
+	SelectColumn Ascend
 	{
 		$$ = []*SelectOrder{
 			&SelectOrder{
@@ -611,7 +1065,7 @@ SelectOrderList:
 			},
 		}
 	}
-	| SelectOrderList ',' Expr Ascend
+	| SelectOrderList ',' SelectColumn Ascend
 	{
 		$$ = append($1, &SelectOrder{
 			Asc: $4,
@@ -634,14 +1088,15 @@ SelectLimit:
 			Limit: limit,
 		}
 	}
+	// LIMIT offset, limit
 	| "LIMIT" VARIABLE ',' VARIABLE
 	{
-		limit, err := strconv.Atoi($2)
+		limit, err := strconv.Atoi($4)
 		if err != nil {
 			yylex.Error(err.Error())
 			goto ret1
 		}
-		offset, err := strconv.Atoi($4)
+		offset, err := strconv.Atoi($2)
 		if err != nil {
 			yylex.Error(err.Error())
 			goto ret1
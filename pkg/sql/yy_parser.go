@@ -13,9 +13,12 @@ type yySymType struct {
 	str            string
 	strList        []string
 	boolean        bool
-	fieldType      FieldType
+	fieldType      ColumnType
 	compareOperate CompareOperate
 
+	value     *Value
+	valueList []*Value
+
 	stmt     Statement
 	stmtList []Statement
 
@@ -26,21 +29,35 @@ type yySymType struct {
 	createStmt        *CreateStmt
 	createTable       *CreateTable
 	createField       *CreateField
+	createDefault     *CreateDefault
 	createIndex       *CreateIndex
 	createTableOption *CreateTableOption
 
+	alterStmt       *AlterStmt
+	dropStmt        *DropStmt
+	truncateStmt    *TruncateStmt
+	createIndexStmt *CreateIndexStmt
+	dropIndexStmt   *DropIndexStmt
+	analyzeStmt     *AnalyzeStmt
+
 	insertStmt *InsertStmt
 
 	updateStmt  *UpdateStmt
-	updateValue map[string]string
+	updateValue map[string]*Value
 
 	deleteStmt *DeleteStmt
 
-	selectStmt      *SelectStmt
-	selectFieldList []*SelectField
-	selectWhereList []SelectWhere
-	selectOrderList []*SelectOrder
-	selectLimit     *SelectLimit
+	selectStmt       *SelectStmt
+	selectJoin       *SelectJoin
+	selectJoinList   []*SelectJoin
+	selectJoinOn     *SelectJoinOn
+	selectJoinOnList []*SelectJoinOn
+	selectField      *SelectField
+	selectFieldList  []*SelectField
+	selectWhere      SelectWhere
+	selectWhereList  []SelectWhere
+	selectOrderList  []*SelectOrder
+	selectLimit      *SelectLimit
 }
 
 type yyXError struct {
@@ -48,44 +65,67 @@ type yyXError struct {
 }
 
 const (
-	yyDefault = 57378
-	yyEofCode = 57344
-	AND       = 57367
-	ASC       = 57370
-	BEGIN     = 57346
-	BY        = 57369
-	COMMIT    = 57347
-	COMP_GE   = 57376
-	COMP_LE   = 57375
-	COMP_NE   = 57374
-	CREATE    = 57349
-	DEFAULT   = 57355
-	DELETE    = 57362
-	DESC      = 57371
-	FROM      = 57364
-	INDEX     = 57354
-	INSERT    = 57357
-	INTO      = 57358
-	KEY       = 57351
-	LIMIT     = 57372
-	NOT       = 57352
-	NULL      = 57353
-	OFFSET    = 57373
-	OR        = 57366
-	ORDER     = 57368
-	PRIMARY   = 57356
-	ROLLBACK  = 57348
-	SELECT    = 57363
-	SET       = 57361
-	TABLE     = 57350
-	UPDATE    = 57360
-	VALUE     = 57359
-	VARIABLE  = 57377
-	WHERE     = 57365
-	yyErrCode = 57345
+	yyDefault         = 57401
+	yyEofCode         = 57344
+	ADD               = 57360
+	ALTER             = 57359
+	ANALYZE           = 57370
+	AND               = 57387
+	AS                = 57378
+	ASC               = 57392
+	AUTO_INCREMENT    = 57357
+	BEGIN             = 57346
+	BY                = 57391
+	COLUMN            = 57361
+	COMMIT            = 57347
+	COMP_GE           = 57398
+	COMP_LE           = 57397
+	COMP_NE           = 57396
+	CREATE            = 57349
+	CURRENT_TIMESTAMP = 57356
+	DEFAULT           = 57355
+	DELETE            = 57376
+	DESC              = 57393
+	DROP              = 57362
+	EXISTS            = 57366
+	FROM              = 57379
+	GROUP             = 57388
+	HAVING            = 57389
+	IF                = 57365
+	INDEX             = 57354
+	INNER             = 57381
+	INSERT            = 57371
+	INTO              = 57372
+	IS                = 57385
+	JOIN              = 57380
+	KEY               = 57351
+	LEFT              = 57382
+	LIMIT             = 57394
+	NOT               = 57352
+	NULL              = 57353
+	OFFSET            = 57395
+	ON                = 57369
+	OR                = 57386
+	ORDER             = 57390
+	OUTER             = 57383
+	PARAM             = 57400
+	PRIMARY           = 57358
+	RENAME            = 57363
+	ROLLBACK          = 57348
+	SELECT            = 57377
+	SET               = 57375
+	TABLE             = 57350
+	TO                = 57364
+	TRUNCATE          = 57367
+	UNIQUE            = 57368
+	UPDATE            = 57374
+	VALUE             = 57373
+	VARIABLE          = 57399
+	WHERE             = 57384
+	yyErrCode         = 57345
 
 	yyMaxDepth = 200
-	yyTabOfs   = -76
+	yyTabOfs   = -139
 )
 
 var (
@@ -99,95 +139,146 @@ var (
 	}
 
 	yyXLAT = map[int]int{
-		57377: 0,  // VARIABLE (42x)
-		59:    1,  // ';' (38x)
-		44:    2,  // ',' (37x)
-		41:    3,  // ')' (36x)
-		57391: 4,  // Expr (32x)
-		57344: 5,  // $end (21x)
-		57346: 6,  // BEGIN (21x)
-		57347: 7,  // COMMIT (21x)
-		57349: 8,  // CREATE (21x)
-		57362: 9,  // DELETE (21x)
-		57357: 10, // INSERT (21x)
-		57348: 11, // ROLLBACK (21x)
-		57363: 12, // SELECT (21x)
-		57360: 13, // UPDATE (21x)
-		57372: 14, // LIMIT (20x)
-		57367: 15, // AND (9x)
-		57366: 16, // OR (9x)
-		57368: 17, // ORDER (9x)
-		40:    18, // '(' (8x)
-		61:    19, // '=' (6x)
-		57355: 20, // DEFAULT (6x)
-		57365: 21, // WHERE (6x)
-		57364: 22, // FROM (5x)
-		57353: 23, // NULL (5x)
-		60:    24, // '<' (4x)
-		62:    25, // '>' (4x)
-		57376: 26, // COMP_GE (4x)
-		57375: 27, // COMP_LE (4x)
-		57374: 28, // COMP_NE (4x)
-		57370: 29, // ASC (3x)
-		57382: 30, // CompareOperate (3x)
-		57371: 31, // DESC (3x)
-		57352: 32, // NOT (3x)
-		57405: 33, // SelectWhere (3x)
-		57406: 34, // SelectWhereList (3x)
-		57379: 35, // Ascend (2x)
-		57380: 36, // BeginStmt (2x)
-		57381: 37, // CommitStmt (2x)
-		57383: 38, // CreateField (2x)
-		57384: 39, // CreateIndex (2x)
-		57385: 40, // CreatePrimary (2x)
-		57386: 41, // CreateStmt (2x)
-		57390: 42, // DeleteStmt (2x)
-		57354: 43, // INDEX (2x)
-		57395: 44, // InsertStmt (2x)
-		57356: 45, // PRIMARY (2x)
-		57399: 46, // RollbackStmt (2x)
-		57401: 47, // SelectLimit (2x)
-		57404: 48, // SelectStmt (2x)
-		57361: 49, // SET (2x)
-		57407: 50, // Stmt (2x)
-		57409: 51, // UpdateStmt (2x)
-		57359: 52, // VALUE (2x)
-		57411: 53, // VaribleList (2x)
-		57369: 54, // BY (1x)
-		57387: 55, // CreateTable (1x)
-		57388: 56, // CreateTableOption (1x)
-		57389: 57, // Default (1x)
-		57392: 58, // FieldType (1x)
-		57393: 59, // InsertField (1x)
-		57394: 60, // InsertFieldList (1x)
-		57396: 61, // InsertValue (1x)
-		57397: 62, // InsertValueList (1x)
-		57358: 63, // INTO (1x)
-		57351: 64, // KEY (1x)
-		57398: 65, // Nullable (1x)
-		57373: 66, // OFFSET (1x)
-		57400: 67, // SelectFieldList (1x)
-		57402: 68, // SelectOrder (1x)
-		57403: 69, // SelectOrderList (1x)
-		57412: 70, // start (1x)
-		57408: 71, // StmtList (1x)
-		57350: 72, // TABLE (1x)
-		57410: 73, // UpdateValue (1x)
-		57378: 74, // $default (0x)
-		42:    75, // '*' (0x)
-		43:    76, // '+' (0x)
-		45:    77, // '-' (0x)
-		47:    78, // '/' (0x)
-		57345: 79, // error (0x)
+		59:    0,   // ';' (96x)
+		57399: 1,   // VARIABLE (74x)
+		57421: 2,   // Expr (59x)
+		44:    3,   // ',' (58x)
+		41:    4,   // ')' (54x)
+		57394: 5,   // LIMIT (49x)
+		57362: 6,   // DROP (38x)
+		57344: 7,   // $end (36x)
+		57359: 8,   // ALTER (36x)
+		57370: 9,   // ANALYZE (36x)
+		57346: 10,  // BEGIN (36x)
+		57347: 11,  // COMMIT (36x)
+		57349: 12,  // CREATE (36x)
+		57376: 13,  // DELETE (36x)
+		57371: 14,  // INSERT (36x)
+		57348: 15,  // ROLLBACK (36x)
+		57377: 16,  // SELECT (36x)
+		57367: 17,  // TRUNCATE (36x)
+		57374: 18,  // UPDATE (36x)
+		57390: 19,  // ORDER (32x)
+		57389: 20,  // HAVING (30x)
+		57388: 21,  // GROUP (26x)
+		57387: 22,  // AND (20x)
+		57353: 23,  // NULL (20x)
+		57384: 24,  // WHERE (20x)
+		57380: 25,  // JOIN (17x)
+		57386: 26,  // OR (16x)
+		57357: 27,  // AUTO_INCREMENT (13x)
+		57381: 28,  // INNER (13x)
+		57382: 29,  // LEFT (13x)
+		40:    30,  // '(' (12x)
+		57400: 31,  // PARAM (11x)
+		57379: 32,  // FROM (9x)
+		57355: 33,  // DEFAULT (8x)
+		57435: 34,  // SelectColumn (8x)
+		61:    35,  // '=' (7x)
+		57369: 36,  // ON (7x)
+		57354: 37,  // INDEX (6x)
+		57352: 38,  // NOT (6x)
+		57436: 39,  // SelectCond (6x)
+		60:    40,  // '<' (5x)
+		62:    41,  // '>' (5x)
+		57392: 42,  // ASC (5x)
+		57398: 43,  // COMP_GE (5x)
+		57397: 44,  // COMP_LE (5x)
+		57396: 45,  // COMP_NE (5x)
+		57393: 46,  // DESC (5x)
+		57350: 47,  // TABLE (5x)
+		57456: 48,  // Value (5x)
+		57458: 49,  // VaribleList (5x)
+		57378: 50,  // AS (4x)
+		57410: 51,  // CreateField (4x)
+		57385: 52,  // IS (4x)
+		57449: 53,  // SelectWhereList (4x)
+		57361: 54,  // COLUMN (3x)
+		57448: 55,  // SelectWhere (3x)
+		57364: 56,  // TO (3x)
+		57360: 57,  // ADD (2x)
+		57403: 58,  // AlterStmt (2x)
+		57404: 59,  // AnalyzeStmt (2x)
+		57405: 60,  // Ascend (2x)
+		57407: 61,  // BeginStmt (2x)
+		57391: 62,  // BY (2x)
+		57408: 63,  // CommitStmt (2x)
+		57409: 64,  // CompareOperate (2x)
+		57411: 65,  // CreateIndex (2x)
+		57412: 66,  // CreateIndexStmt (2x)
+		57413: 67,  // CreatePrimary (2x)
+		57414: 68,  // CreateStmt (2x)
+		57418: 69,  // DeleteStmt (2x)
+		57419: 70,  // DropIndexStmt (2x)
+		57420: 71,  // DropStmt (2x)
+		57426: 72,  // InsertStmt (2x)
+		57429: 73,  // JoinCond (2x)
+		57358: 74,  // PRIMARY (2x)
+		57363: 75,  // RENAME (2x)
+		57434: 76,  // RollbackStmt (2x)
+		57437: 77,  // SelectField (2x)
+		57443: 78,  // SelectLimit (2x)
+		57446: 79,  // SelectStmt (2x)
+		57447: 80,  // SelectTable (2x)
+		57375: 81,  // SET (2x)
+		57450: 82,  // Stmt (2x)
+		57452: 83,  // TruncateStmt (2x)
+		57454: 84,  // UpdateStmt (2x)
+		57373: 85,  // VALUE (2x)
+		57402: 86,  // AlterAction (1x)
+		57406: 87,  // AutoIncrement (1x)
+		57415: 88,  // CreateTable (1x)
+		57416: 89,  // CreateTableOption (1x)
+		57356: 90,  // CURRENT_TIMESTAMP (1x)
+		57417: 91,  // Default (1x)
+		57366: 92,  // EXISTS (1x)
+		57422: 93,  // FieldType (1x)
+		57365: 94,  // IF (1x)
+		57423: 95,  // IfExists (1x)
+		57424: 96,  // InsertField (1x)
+		57425: 97,  // InsertFieldList (1x)
+		57427: 98,  // InsertValue (1x)
+		57428: 99,  // InsertValueList (1x)
+		57372: 100, // INTO (1x)
+		57430: 101, // JoinCondList (1x)
+		57431: 102, // JoinOn (1x)
+		57432: 103, // JoinType (1x)
+		57351: 104, // KEY (1x)
+		57433: 105, // Nullable (1x)
+		57395: 106, // OFFSET (1x)
+		57383: 107, // OUTER (1x)
+		57438: 108, // SelectFieldList (1x)
+		57439: 109, // SelectGroup (1x)
+		57440: 110, // SelectHaving (1x)
+		57441: 111, // SelectJoin (1x)
+		57442: 112, // SelectJoinList (1x)
+		57444: 113, // SelectOrder (1x)
+		57445: 114, // SelectOrderList (1x)
+		57459: 115, // start (1x)
+		57451: 116, // StmtList (1x)
+		57368: 117, // UNIQUE (1x)
+		57453: 118, // Unique (1x)
+		57455: 119, // UpdateValue (1x)
+		57457: 120, // ValueList (1x)
+		57401: 121, // $default (0x)
+		42:    122, // '*' (0x)
+		43:    123, // '+' (0x)
+		45:    124, // '-' (0x)
+		47:    125, // '/' (0x)
+		57345: 126, // error (0x)
 	}
 
 	yySymNames = []string{
-		"VARIABLE",
 		"';'",
+		"VARIABLE",
+		"Expr",
 		"','",
 		"')'",
-		"Expr",
+		"LIMIT",
+		"DROP",
 		"$end",
+		"ALTER",
+		"ANALYZE",
 		"BEGIN",
 		"COMMIT",
 		"CREATE",
@@ -195,67 +286,110 @@ var (
 		"INSERT",
 		"ROLLBACK",
 		"SELECT",
+		"TRUNCATE",
 		"UPDATE",
-		"LIMIT",
+		"ORDER",
+		"HAVING",
+		"GROUP",
 		"AND",
+		"NULL",
+		"WHERE",
+		"JOIN",
 		"OR",
-		"ORDER",
+		"AUTO_INCREMENT",
+		"INNER",
+		"LEFT",
 		"'('",
-		"'='",
-		"DEFAULT",
-		"WHERE",
+		"PARAM",
 		"FROM",
-		"NULL",
+		"DEFAULT",
+		"SelectColumn",
+		"'='",
+		"ON",
+		"INDEX",
+		"NOT",
+		"SelectCond",
 		"'<'",
 		"'>'",
+		"ASC",
 		"COMP_GE",
 		"COMP_LE",
 		"COMP_NE",
-		"ASC",
-		"CompareOperate",
 		"DESC",
-		"NOT",
-		"SelectWhere",
+		"TABLE",
+		"Value",
+		"VaribleList",
+		"AS",
+		"CreateField",
+		"IS",
 		"SelectWhereList",
+		"COLUMN",
+		"SelectWhere",
+		"TO",
+		"ADD",
+		"AlterStmt",
+		"AnalyzeStmt",
 		"Ascend",
 		"BeginStmt",
+		"BY",
 		"CommitStmt",
-		"CreateField",
+		"CompareOperate",
 		"CreateIndex",
+		"CreateIndexStmt",
 		"CreatePrimary",
 		"CreateStmt",
 		"DeleteStmt",
-		"INDEX",
+		"DropIndexStmt",
+		"DropStmt",
 		"InsertStmt",
+		"JoinCond",
 		"PRIMARY",
+		"RENAME",
 		"RollbackStmt",
+		"SelectField",
 		"SelectLimit",
 		"SelectStmt",
+		"SelectTable",
 		"SET",
 		"Stmt",
+		"TruncateStmt",
 		"UpdateStmt",
 		"VALUE",
-		"VaribleList",
-		"BY",
+		"AlterAction",
+		"AutoIncrement",
 		"CreateTable",
 		"CreateTableOption",
+		"CURRENT_TIMESTAMP",
 		"Default",
+		"EXISTS",
 		"FieldType",
+		"IF",
+		"IfExists",
 		"InsertField",
 		"InsertFieldList",
 		"InsertValue",
 		"InsertValueList",
 		"INTO",
+		"JoinCondList",
+		"JoinOn",
+		"JoinType",
 		"KEY",
 		"Nullable",
 		"OFFSET",
+		"OUTER",
 		"SelectFieldList",
+		"SelectGroup",
+		"SelectHaving",
+		"SelectJoin",
+		"SelectJoinList",
 		"SelectOrder",
 		"SelectOrderList",
 		"start",
 		"StmtList",
-		"TABLE",
+		"UNIQUE",
+		"Unique",
 		"UpdateValue",
+		"ValueList",
 		"$default",
 		"'*'",
 		"'+'",
@@ -265,298 +399,522 @@ var (
 	}
 
 	yyTokenLiteralStrings = map[int]string{
+		57394: "LIMIT",
+		57362: "DROP",
+		57359: "ALTER",
+		57370: "ANALYZE",
 		57346: "BEGIN",
 		57347: "COMMIT",
 		57349: "CREATE",
-		57362: "DELETE",
-		57357: "INSERT",
+		57376: "DELETE",
+		57371: "INSERT",
 		57348: "ROLLBACK",
-		57363: "SELECT",
-		57360: "UPDATE",
-		57372: "LIMIT",
-		57367: "AND",
-		57366: "OR",
-		57368: "ORDER",
-		57355: "DEFAULT",
-		57365: "WHERE",
-		57364: "FROM",
+		57377: "SELECT",
+		57367: "TRUNCATE",
+		57374: "UPDATE",
+		57390: "ORDER",
+		57389: "HAVING",
+		57388: "GROUP",
+		57387: "AND",
 		57353: "NULL",
-		57376: ">=",
-		57375: "<=",
-		57374: "!=",
-		57370: "ASC",
-		57371: "DESC",
-		57352: "NOT",
+		57384: "WHERE",
+		57380: "JOIN",
+		57386: "OR",
+		57357: "AUTO_INCREMENT",
+		57381: "INNER",
+		57382: "LEFT",
+		57379: "FROM",
+		57355: "DEFAULT",
+		57369: "ON",
 		57354: "INDEX",
-		57356: "PRIMARY",
-		57361: "SET",
-		57359: "VALUE",
-		57369: "BY",
-		57358: "INTO",
-		57351: "KEY",
-		57373: "OFFSET",
+		57352: "NOT",
+		57392: "ASC",
+		57398: ">=",
+		57397: "<=",
+		57396: "!=",
+		57393: "DESC",
 		57350: "TABLE",
+		57378: "AS",
+		57385: "IS",
+		57361: "COLUMN",
+		57364: "TO",
+		57360: "ADD",
+		57391: "BY",
+		57358: "PRIMARY",
+		57363: "RENAME",
+		57375: "SET",
+		57373: "VALUE",
+		57356: "CURRENT_TIMESTAMP",
+		57366: "EXISTS",
+		57365: "IF",
+		57372: "INTO",
+		57351: "KEY",
+		57395: "OFFSET",
+		57383: "OUTER",
+		57368: "UNIQUE",
 	}
 
 	yyReductions = map[int]struct{ xsym, components int }{
-		0:  {0, 1},
-		1:  {70, 1},
-		2:  {4, 1},
-		3:  {53, 1},
-		4:  {53, 3},
-		5:  {50, 1},
-		6:  {50, 1},
-		7:  {50, 1},
-		8:  {50, 1},
-		9:  {50, 1},
-		10: {50, 1},
-		11: {50, 1},
-		12: {50, 1},
-		13: {71, 1},
-		14: {71, 2},
-		15: {57, 0},
-		16: {57, 1},
-		17: {57, 2},
-		18: {57, 2},
-		19: {65, 0},
-		20: {65, 1},
-		21: {65, 2},
-		22: {58, 1},
-		23: {36, 3},
-		24: {37, 2},
-		25: {46, 2},
-		26: {41, 8},
-		27: {55, 1},
-		28: {55, 1},
-		29: {55, 1},
-		30: {55, 3},
-		31: {55, 3},
-		32: {55, 3},
-		33: {38, 4},
-		34: {39, 5},
-		35: {40, 5},
-		36: {56, 0},
-		37: {44, 6},
-		38: {59, 3},
-		39: {60, 0},
-		40: {60, 1},
-		41: {61, 4},
-		42: {62, 0},
-		43: {62, 1},
-		44: {51, 6},
-		45: {73, 3},
-		46: {73, 5},
-		47: {42, 5},
-		48: {35, 0},
-		49: {35, 1},
-		50: {35, 1},
-		51: {30, 1},
-		52: {30, 1},
-		53: {30, 1},
-		54: {30, 1},
-		55: {30, 1},
-		56: {30, 1},
-		57: {48, 4},
-		58: {48, 8},
-		59: {67, 1},
-		60: {67, 3},
-		61: {33, 0},
-		62: {33, 2},
-		63: {34, 3},
-		64: {34, 5},
-		65: {34, 5},
-		66: {34, 5},
-		67: {34, 5},
-		68: {68, 0},
-		69: {68, 3},
-		70: {69, 2},
-		71: {69, 4},
-		72: {47, 0},
-		73: {47, 2},
-		74: {47, 4},
-		75: {47, 4},
+		0:   {0, 1},
+		1:   {115, 1},
+		2:   {2, 1},
+		3:   {49, 1},
+		4:   {49, 3},
+		5:   {48, 1},
+		6:   {48, 1},
+		7:   {48, 1},
+		8:   {120, 1},
+		9:   {120, 3},
+		10:  {82, 1},
+		11:  {82, 1},
+		12:  {82, 1},
+		13:  {82, 1},
+		14:  {82, 1},
+		15:  {82, 1},
+		16:  {82, 1},
+		17:  {82, 1},
+		18:  {82, 1},
+		19:  {82, 1},
+		20:  {82, 1},
+		21:  {82, 1},
+		22:  {82, 1},
+		23:  {82, 1},
+		24:  {116, 1},
+		25:  {116, 2},
+		26:  {91, 0},
+		27:  {91, 1},
+		28:  {91, 2},
+		29:  {91, 2},
+		30:  {91, 2},
+		31:  {105, 0},
+		32:  {105, 1},
+		33:  {105, 2},
+		34:  {87, 0},
+		35:  {87, 1},
+		36:  {93, 1},
+		37:  {93, 4},
+		38:  {93, 6},
+		39:  {61, 2},
+		40:  {61, 3},
+		41:  {61, 4},
+		42:  {63, 2},
+		43:  {76, 2},
+		44:  {68, 8},
+		45:  {88, 1},
+		46:  {88, 1},
+		47:  {88, 1},
+		48:  {88, 3},
+		49:  {88, 3},
+		50:  {88, 3},
+		51:  {51, 5},
+		52:  {65, 5},
+		53:  {67, 5},
+		54:  {89, 0},
+		55:  {58, 5},
+		56:  {86, 2},
+		57:  {86, 3},
+		58:  {86, 2},
+		59:  {86, 3},
+		60:  {86, 5},
+		61:  {86, 3},
+		62:  {95, 0},
+		63:  {95, 2},
+		64:  {71, 5},
+		65:  {83, 4},
+		66:  {118, 0},
+		67:  {118, 1},
+		68:  {66, 10},
+		69:  {70, 4},
+		70:  {70, 6},
+		71:  {59, 4},
+		72:  {72, 6},
+		73:  {96, 3},
+		74:  {97, 0},
+		75:  {97, 1},
+		76:  {98, 4},
+		77:  {99, 0},
+		78:  {99, 1},
+		79:  {84, 6},
+		80:  {119, 3},
+		81:  {119, 5},
+		82:  {69, 5},
+		83:  {60, 0},
+		84:  {60, 1},
+		85:  {60, 1},
+		86:  {64, 1},
+		87:  {64, 1},
+		88:  {64, 1},
+		89:  {64, 1},
+		90:  {64, 1},
+		91:  {64, 1},
+		92:  {79, 4},
+		93:  {79, 11},
+		94:  {80, 1},
+		95:  {80, 2},
+		96:  {80, 3},
+		97:  {112, 0},
+		98:  {112, 2},
+		99:  {111, 4},
+		100: {103, 0},
+		101: {103, 1},
+		102: {103, 1},
+		103: {103, 2},
+		104: {102, 0},
+		105: {102, 2},
+		106: {101, 1},
+		107: {101, 3},
+		108: {73, 3},
+		109: {108, 1},
+		110: {108, 3},
+		111: {77, 1},
+		112: {77, 3},
+		113: {77, 4},
+		114: {77, 6},
+		115: {34, 1},
+		116: {34, 4},
+		117: {55, 0},
+		118: {55, 2},
+		119: {39, 3},
+		120: {39, 3},
+		121: {39, 4},
+		122: {53, 1},
+		123: {53, 3},
+		124: {53, 3},
+		125: {53, 5},
+		126: {53, 5},
+		127: {109, 0},
+		128: {109, 3},
+		129: {110, 0},
+		130: {110, 2},
+		131: {113, 0},
+		132: {113, 3},
+		133: {114, 2},
+		134: {114, 4},
+		135: {78, 0},
+		136: {78, 2},
+		137: {78, 4},
+		138: {78, 4},
 	}
 
 	yyXErrors = map[yyXError]string{}
 
-	yyParseTab = [147][]uint16{
+	yyParseTab = [263][]uint16{
 		// 0
-		{6: 88, 89, 91, 94, 92, 90, 95, 93, 36: 79, 80, 41: 82, 86, 44: 84, 46: 81, 48: 83, 50: 87, 85, 70: 77, 78},
-		{5: 76},
-		{5: 75, 88, 89, 91, 94, 92, 90, 95, 93, 36: 79, 80, 41: 82, 86, 44: 84, 46: 81, 48: 83, 50: 222, 85},
-		{5: 71, 71, 71, 71, 71, 71, 71, 71, 71},
-		{5: 70, 70, 70, 70, 70, 70, 70, 70, 70},
+		{6: 162, 8: 161, 164, 157, 158, 160, 167, 165, 159, 168, 163, 166, 58: 146, 151, 61: 142, 63: 143, 66: 149, 68: 145, 155, 150, 147, 153, 76: 144, 79: 152, 82: 156, 148, 154, 115: 140, 141},
+		{7: 139},
+		{6: 162, 138, 161, 164, 157, 158, 160, 167, 165, 159, 168, 163, 166, 58: 146, 151, 61: 142, 63: 143, 66: 149, 68: 145, 155, 150, 147, 153, 76: 144, 79: 152, 82: 401, 148, 154},
+		{6: 129, 129, 129, 129, 129, 129, 129, 129, 129, 129, 129, 129, 129},
+		{6: 128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128, 128},
 		// 5
-		{5: 69, 69, 69, 69, 69, 69, 69, 69, 69},
-		{5: 68, 68, 68, 68, 68, 68, 68, 68, 68},
-		{5: 67, 67, 67, 67, 67, 67, 67, 67, 67},
-		{5: 66, 66, 66, 66, 66, 66, 66, 66, 66},
-		{5: 65, 65, 65, 65, 65, 65, 65, 65, 65},
+		{6: 127, 127, 127, 127, 127, 127, 127, 127, 127, 127, 127, 127, 127},
+		{6: 126, 126, 126, 126, 126, 126, 126, 126, 126, 126, 126, 126, 126},
+		{6: 125, 125, 125, 125, 125, 125, 125, 125, 125, 125, 125, 125, 125},
+		{6: 124, 124, 124, 124, 124, 124, 124, 124, 124, 124, 124, 124, 124},
+		{6: 123, 123, 123, 123, 123, 123, 123, 123, 123, 123, 123, 123, 123},
 		// 10
-		{5: 64, 64, 64, 64, 64, 64, 64, 64, 64},
-		{5: 63, 63, 63, 63, 63, 63, 63, 63, 63},
-		{96, 4: 220},
-		{1: 219},
-		{1: 218},
+		{6: 122, 122, 122, 122, 122, 122, 122, 122, 122, 122, 122, 122, 122},
+		{6: 121, 121, 121, 121, 121, 121, 121, 121, 121, 121, 121, 121, 121},
+		{6: 120, 120, 120, 120, 120, 120, 120, 120, 120, 120, 120, 120, 120},
+		{6: 119, 119, 119, 119, 119, 119, 119, 119, 119, 119, 119, 119, 119},
+		{6: 118, 118, 118, 118, 118, 118, 118, 118, 118, 118, 118, 118, 118},
 		// 15
-		{72: 183},
-		{63: 166},
-		{96, 4: 154},
-		{22: 150},
-		{96, 4: 98, 67: 97},
+		{6: 117, 117, 117, 117, 117, 117, 117, 117, 117, 117, 117, 117, 117},
+		{6: 116, 116, 116, 116, 116, 116, 116, 116, 116, 116, 116, 116, 116},
+		{6: 115, 115, 115, 115, 115, 115, 115, 115, 115, 115, 115, 115, 115},
+		{396, 169, 397},
+		{395},
 		// 20
-		{74, 74, 74, 74, 14: 74, 74, 74, 74, 74, 74, 74, 74, 74, 74, 74, 74, 74, 74, 74, 74, 31: 74, 74, 49: 74},
-		{1: 4, 101, 14: 102, 22: 100, 47: 99},
-		{1: 17, 17, 14: 17, 22: 17},
-		{1: 149},
-		{96, 4: 109},
+		{394},
+		{37: 73, 47: 360, 117: 361, 362},
+		{47: 321},
+		{37: 310, 47: 309},
+		{47: 306},
 		// 25
-		{96, 4: 108},
-		{103},
-		{1: 3, 104, 66: 105},
-		{107},
-		{106},
+		{47: 303},
+		{100: 286},
+		{1: 169, 274},
+		{32: 270},
+		{1: 169, 172, 77: 171, 108: 170},
 		// 30
-		{1: 1},
-		{1: 2},
-		{1: 16, 16, 14: 16, 22: 16},
-		{1: 15, 14: 15, 17: 15, 21: 111, 33: 110},
-		{1: 8, 14: 8, 17: 137, 68: 136},
+		{137, 137, 3: 137, 137, 137, 137, 19: 137, 137, 137, 137, 137, 137, 137, 137, 137, 137, 137, 137, 32: 137, 137, 35: 137, 137, 38: 137, 40: 137, 137, 137, 137, 137, 137, 137, 50: 137, 52: 137, 56: 137, 137, 75: 137, 81: 137},
+		{4, 3: 182, 5: 183, 32: 181, 78: 180},
+		{30, 3: 30, 5: 30, 32: 30},
+		{28, 3: 28, 5: 28, 30: 174, 32: 28, 50: 173},
+		{1: 169, 179},
 		// 35
-		{96, 4: 113, 34: 112},
-		{1: 14, 14: 14, 123, 122, 14},
-		{19: 114, 24: 115, 116, 118, 117, 119, 30: 120},
-		{25},
-		{24},
+		{1: 169, 175},
+		{4: 176},
+		{26, 3: 26, 5: 26, 32: 26, 50: 177},
+		{1: 169, 178},
+		{25, 3: 25, 5: 25, 32: 25},
 		// 40
-		{23},
-		{22},
-		{21},
-		{20},
-		{96, 4: 121},
+		{27, 3: 27, 5: 27, 32: 27},
+		{269},
+		{1: 169, 191, 80: 190},
+		{1: 169, 172, 77: 189},
+		{1: 184},
 		// 45
-		{1: 13, 3: 13, 14: 13, 13, 13, 13},
-		{96, 4: 130, 18: 131},
-		{96, 4: 124, 18: 125},
-		{19: 114, 24: 115, 116, 118, 117, 119, 30: 128},
-		{96, 4: 113, 34: 126},
+		{3, 3: 185, 106: 186},
+		{1: 188},
+		{1: 187},
+		{1},
+		{2},
 		// 50
-		{3: 127, 15: 123, 122},
-		{1: 9, 3: 9, 14: 9, 9, 9, 9},
-		{96, 4: 129},
-		{1: 11, 3: 11, 14: 11, 11, 11, 11},
-		{19: 114, 24: 115, 116, 118, 117, 119, 30: 134},
+		{29, 3: 29, 5: 29, 32: 29},
+		{42, 5: 42, 19: 42, 42, 42, 24: 42, 42, 28: 42, 42, 112: 195},
+		{45, 169, 192, 5: 45, 19: 45, 45, 45, 24: 45, 45, 28: 45, 45, 36: 45, 50: 193},
+		{44, 5: 44, 19: 44, 44, 44, 24: 44, 44, 28: 44, 44, 36: 44},
+		{1: 169, 194},
 		// 55
-		{96, 4: 113, 34: 132},
-		{3: 133, 15: 123, 122},
-		{1: 10, 3: 10, 14: 10, 10, 10, 10},
-		{96, 4: 135},
-		{1: 12, 3: 12, 14: 12, 12, 12, 12},
+		{43, 5: 43, 19: 43, 43, 43, 24: 43, 43, 28: 43, 43, 36: 43},
+		{22, 5: 22, 19: 22, 22, 22, 24: 201, 39, 28: 199, 200, 55: 196, 103: 198, 111: 197},
+		{12, 5: 12, 19: 12, 12, 247, 109: 246},
+		{41, 5: 41, 19: 41, 41, 41, 24: 41, 41, 28: 41, 41},
+		{25: 235},
 		// 60
-		{1: 4, 14: 102, 47: 147},
-		{54: 138},
-		{96, 4: 140, 69: 139},
-		{1: 7, 144, 14: 7},
-		{1: 28, 28, 14: 28, 29: 141, 31: 142, 35: 143},
+		{25: 38},
+		{25: 37, 107: 234},
+		{1: 169, 202, 34: 204, 39: 205, 53: 203},
+		{24, 3: 24, 5: 24, 30: 231, 35: 24, 40: 24, 24, 24, 24, 24, 24, 24, 52: 24},
+		{21, 5: 21, 19: 21, 21, 21, 222, 26: 221},
 		// 65
-		{1: 27, 27, 14: 27},
-		{1: 26, 26, 14: 26},
-		{1: 6, 6, 14: 6},
-		{96, 4: 145},
-		{1: 28, 28, 14: 28, 29: 141, 31: 142, 35: 146},
+		{35: 206, 40: 207, 208, 43: 210, 209, 211, 52: 213, 64: 212},
+		{17, 4: 17, 17, 19: 17, 17, 17, 17, 26: 17},
+		{1: 53, 23: 53, 31: 53},
+		{1: 52, 23: 52, 31: 52},
+		{1: 51, 23: 51, 31: 51},
 		// 70
-		{1: 5, 5, 14: 5},
-		{1: 148},
-		{5: 18, 18, 18, 18, 18, 18, 18, 18, 18},
-		{5: 19, 19, 19, 19, 19, 19, 19, 19, 19},
-		{96, 4: 151},
+		{1: 50, 23: 50, 31: 50},
+		{1: 49, 23: 49, 31: 49},
+		{1: 48, 23: 48, 31: 48},
+		{1: 169, 217, 23: 218, 31: 219, 48: 220},
+		{23: 214, 38: 215},
 		// 75
-		{1: 15, 21: 111, 33: 152},
-		{1: 153},
-		{5: 29, 29, 29, 29, 29, 29, 29, 29, 29},
-		{49: 155},
-		{96, 4: 157, 73: 156},
+		{19, 4: 19, 19, 19: 19, 19, 19, 19, 26: 19},
+		{23: 216},
+		{18, 4: 18, 18, 19: 18, 18, 18, 18, 26: 18},
+		{134, 3: 134, 134, 134, 19: 134, 134, 134, 134, 24: 134, 26: 134},
+		{133, 3: 133, 133, 133, 19: 133, 133, 133, 133, 24: 133, 26: 133},
 		// 80
-		{1: 15, 161, 21: 111, 33: 160},
-		{19: 158},
-		{96, 4: 159},
-		{1: 31, 31, 21: 31},
-		{1: 165},
+		{132, 3: 132, 132, 132, 19: 132, 132, 132, 132, 24: 132, 26: 132},
+		{20, 4: 20, 20, 19: 20, 20, 20, 20, 26: 20},
+		{1: 169, 202, 30: 228, 34: 204, 39: 227},
+		{1: 169, 202, 30: 224, 34: 204, 39: 223},
+		{15, 4: 15, 15, 19: 15, 15, 15, 15, 26: 15},
 		// 85
-		{96, 4: 162},
-		{19: 163},
-		{96, 4: 164},
-		{1: 30, 30, 21: 30},
-		{5: 32, 32, 32, 32, 32, 32, 32, 32, 32},
+		{1: 169, 202, 34: 204, 39: 205, 53: 225},
+		{4: 226, 22: 222, 26: 221},
+		{13, 4: 13, 13, 19: 13, 13, 13, 13, 26: 13},
+		{16, 4: 16, 16, 19: 16, 16, 16, 16, 26: 16},
+		{1: 169, 202, 34: 204, 39: 205, 53: 229},
 		// 90
-		{96, 4: 167},
-		{18: 169, 59: 168},
-		{52: 177, 61: 176},
-		{96, 3: 37, 170, 53: 171, 60: 172},
-		{2: 73, 73},
+		{4: 230, 22: 222, 26: 221},
+		{14, 4: 14, 14, 19: 14, 14, 14, 14, 26: 14},
+		{1: 169, 232},
+		{4: 233},
+		{23, 3: 23, 5: 23, 35: 23, 40: 23, 23, 23, 23, 23, 23, 23, 52: 23},
 		// 95
-		{2: 174, 36},
-		{3: 173},
-		{52: 38},
-		{96, 4: 175},
-		{2: 72, 72},
+		{25: 36},
+		{1: 169, 191, 80: 236},
+		{35, 5: 35, 19: 35, 35, 35, 24: 35, 35, 28: 35, 35, 36: 238, 102: 237},
+		{40, 5: 40, 19: 40, 40, 40, 24: 40, 40, 28: 40, 40},
+		{1: 241, 73: 240, 101: 239},
 		// 100
-		{1: 182},
-		{18: 178},
-		{96, 3: 34, 170, 53: 179, 62: 180},
-		{2: 174, 33},
-		{3: 181},
+		{34, 5: 34, 19: 34, 34, 34, 244, 24: 34, 34, 28: 34, 34},
+		{33, 5: 33, 19: 33, 33, 33, 33, 24: 33, 33, 28: 33, 33},
+		{35: 206, 40: 207, 208, 43: 210, 209, 211, 64: 242},
+		{1: 243},
+		{31, 5: 31, 19: 31, 31, 31, 31, 24: 31, 31, 28: 31, 31},
 		// 105
-		{1: 35},
-		{5: 39, 39, 39, 39, 39, 39, 39, 39, 39},
-		{96, 4: 184},
-		{18: 185},
-		{96, 4: 190, 38: 187, 188, 189, 43: 191, 45: 192, 55: 186},
+		{1: 241, 73: 245},
+		{32, 5: 32, 19: 32, 32, 32, 32, 24: 32, 32, 28: 32, 32},
+		{10, 5: 10, 19: 10, 254, 110: 253},
+		{62: 248},
+		{1: 169, 249, 49: 250},
 		// 110
-		{2: 212, 211},
-		{2: 49, 49},
-		{2: 48, 48},
-		{2: 47, 47},
-		{96, 4: 201, 58: 202},
+		{136, 3: 136, 136, 136, 19: 136, 136},
+		{11, 3: 251, 5: 11, 19: 11, 11},
+		{1: 169, 252},
+		{135, 3: 135, 135, 135, 19: 135, 135},
+		{8, 5: 8, 19: 257, 113: 256},
 		// 115
-		{96, 4: 197},
-		{64: 193},
-		{18: 194},
-		{96, 4: 195},
-		{3: 196},
+		{1: 169, 202, 34: 204, 39: 205, 53: 255},
+		{9, 5: 9, 19: 9, 22: 222, 26: 221},
+		{4, 5: 183, 78: 267},
+		{62: 258},
+		{1: 169, 202, 34: 260, 114: 259},
 		// 120
-		{2: 41, 41},
-		{18: 198},
-		{96, 4: 199},
-		{3: 200},
-		{2: 42, 42},
+		{7, 3: 264, 5: 7},
+		{56, 3: 56, 5: 56, 42: 261, 46: 262, 60: 263},
+		{55, 3: 55, 5: 55},
+		{54, 3: 54, 5: 54},
+		{6, 3: 6, 5: 6},
 		// 125
-		{2: 54, 54, 20: 54, 23: 54, 32: 54},
-		{2: 57, 57, 20: 57, 23: 203, 32: 204, 65: 205},
-		{2: 56, 56, 20: 56},
-		{23: 210},
-		{2: 61, 61, 20: 206, 57: 207},
+		{1: 169, 202, 34: 265},
+		{56, 3: 56, 5: 56, 42: 261, 46: 262, 60: 266},
+		{5, 3: 5, 5: 5},
+		{268},
+		{6: 46, 46, 46, 46, 46, 46, 46, 46, 46, 46, 46, 46, 46},
 		// 130
-		{96, 2: 60, 60, 209, 23: 208},
-		{2: 43, 43},
-		{2: 59, 59},
-		{2: 58, 58},
-		{2: 55, 55, 20: 55},
+		{6: 47, 47, 47, 47, 47, 47, 47, 47, 47, 47, 47, 47, 47},
+		{1: 169, 271},
+		{22, 24: 201, 55: 272},
+		{273},
+		{6: 57, 57, 57, 57, 57, 57, 57, 57, 57, 57, 57, 57, 57},
 		// 135
-		{1: 40, 56: 216},
-		{96, 4: 190, 38: 213, 214, 215, 43: 191, 45: 192},
-		{2: 46, 46},
-		{2: 45, 45},
-		{2: 44, 44},
+		{81: 275},
+		{1: 169, 277, 119: 276},
+		{22, 3: 281, 24: 201, 55: 280},
+		{35: 278},
+		{1: 169, 217, 23: 218, 31: 219, 48: 279},
 		// 140
-		{1: 217},
-		{5: 50, 50, 50, 50, 50, 50, 50, 50, 50},
-		{5: 51, 51, 51, 51, 51, 51, 51, 51, 51},
-		{5: 52, 52, 52, 52, 52, 52, 52, 52, 52},
-		{1: 221},
+		{59, 3: 59, 24: 59},
+		{285},
+		{1: 169, 282},
+		{35: 283},
+		{1: 169, 217, 23: 218, 31: 219, 48: 284},
 		// 145
-		{5: 53, 53, 53, 53, 53, 53, 53, 53, 53},
-		{5: 62, 62, 62, 62, 62, 62, 62, 62, 62},
+		{58, 3: 58, 24: 58},
+		{6: 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60, 60},
+		{1: 169, 287},
+		{30: 289, 96: 288},
+		{85: 294, 98: 293},
+		// 150
+		{1: 169, 249, 4: 65, 49: 290, 97: 291},
+		{3: 251, 64},
+		{4: 292},
+		{85: 66},
+		{302},
+		// 155
+		{30: 295},
+		{1: 169, 217, 4: 62, 23: 218, 31: 219, 48: 296, 99: 298, 120: 297},
+		{3: 131, 131},
+		{3: 300, 61},
+		{4: 299},
+		// 160
+		{63},
+		{1: 169, 217, 23: 218, 31: 219, 48: 301},
+		{3: 130, 130},
+		{6: 67, 67, 67, 67, 67, 67, 67, 67, 67, 67, 67, 67, 67},
+		{1: 169, 304},
+		// 165
+		{305},
+		{6: 68, 68, 68, 68, 68, 68, 68, 68, 68, 68, 68, 68, 68},
+		{1: 169, 307},
+		{308},
+		{6: 74, 74, 74, 74, 74, 74, 74, 74, 74, 74, 74, 74, 74},
+		// 170
+		{1: 77, 94: 316, 317},
+		{1: 169, 311},
+		{312, 36: 313},
+		{6: 70, 70, 70, 70, 70, 70, 70, 70, 70, 70, 70, 70, 70},
+		{1: 169, 314},
+		// 175
+		{315},
+		{6: 69, 69, 69, 69, 69, 69, 69, 69, 69, 69, 69, 69, 69},
+		{92: 320},
+		{1: 169, 318},
+		{319},
+		// 180
+		{6: 75, 75, 75, 75, 75, 75, 75, 75, 75, 75, 75, 75, 75},
+		{1: 76},
+		{1: 169, 322},
+		{6: 325, 57: 324, 75: 326, 86: 323},
+		{359},
+		// 185
+		{1: 169, 336, 51: 337, 54: 338},
+		{1: 169, 333, 54: 334},
+		{54: 327, 56: 328},
+		{1: 169, 330},
+		{1: 169, 329},
+		// 190
+		{78},
+		{56: 331},
+		{1: 169, 332},
+		{79},
+		{81},
+		// 195
+		{1: 169, 335},
+		{80},
+		{1: 169, 340, 93: 341},
+		{83},
+		{1: 169, 336, 51: 339},
+		// 200
+		{82},
+		{103, 3: 103, 103, 23: 103, 27: 103, 30: 353, 33: 103, 38: 103},
+		{108, 3: 108, 108, 23: 342, 27: 108, 33: 108, 38: 343, 105: 344},
+		{107, 3: 107, 107, 27: 107, 33: 107},
+		{23: 352},
+		// 205
+		{113, 3: 113, 113, 27: 113, 33: 345, 91: 346},
+		{112, 169, 350, 112, 112, 23: 349, 27: 112, 90: 351},
+		{105, 3: 105, 105, 27: 347, 87: 348},
+		{104, 3: 104, 104},
+		{88, 3: 88, 88},
+		// 210
+		{111, 3: 111, 111, 27: 111},
+		{110, 3: 110, 110, 27: 110},
+		{109, 3: 109, 109, 27: 109},
+		{106, 3: 106, 106, 27: 106, 33: 106},
+		{1: 169, 354},
+		// 215
+		{3: 356, 355},
+		{102, 3: 102, 102, 23: 102, 27: 102, 33: 102, 38: 102},
+		{1: 169, 357},
+		{4: 358},
+		{101, 3: 101, 101, 23: 101, 27: 101, 33: 101, 38: 101},
+		// 220
+		{6: 84, 84, 84, 84, 84, 84, 84, 84, 84, 84, 84, 84, 84},
+		{1: 169, 371},
+		{37: 72},
+		{37: 363},
+		{1: 169, 364},
+		// 225
+		{36: 365},
+		{1: 169, 366},
+		{30: 367},
+		{1: 169, 249, 49: 368},
+		{3: 251, 369},
+		// 230
+		{370},
+		{6: 71, 71, 71, 71, 71, 71, 71, 71, 71, 71, 71, 71, 71},
+		{30: 372},
+		{1: 169, 336, 37: 377, 51: 374, 65: 375, 67: 376, 74: 378, 88: 373},
+		{3: 388, 387},
+		// 235
+		{3: 94, 94},
+		{3: 93, 93},
+		{3: 92, 92},
+		{1: 169, 383},
+		{104: 379},
+		// 240
+		{30: 380},
+		{1: 169, 249, 49: 381},
+		{3: 251, 382},
+		{3: 86, 86},
+		{30: 384},
+		// 245
+		{1: 169, 249, 49: 385},
+		{3: 251, 386},
+		{3: 87, 87},
+		{85, 89: 392},
+		{1: 169, 336, 37: 377, 51: 389, 65: 390, 67: 391, 74: 378},
+		// 250
+		{3: 91, 91},
+		{3: 90, 90},
+		{3: 89, 89},
+		{393},
+		{6: 95, 95, 95, 95, 95, 95, 95, 95, 95, 95, 95, 95, 95},
+		// 255
+		{6: 96, 96, 96, 96, 96, 96, 96, 96, 96, 96, 96, 96, 96},
+		{6: 97, 97, 97, 97, 97, 97, 97, 97, 97, 97, 97, 97, 97},
+		{6: 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100, 100},
+		{398, 169, 399},
+		{6: 99, 99, 99, 99, 99, 99, 99, 99, 99, 99, 99, 99, 99},
+		// 260
+		{400},
+		{6: 98, 98, 98, 98, 98, 98, 98, 98, 98, 98, 98, 98, 98},
+		{6: 114, 114, 114, 114, 114, 114, 114, 114, 114, 114, 114, 114, 114},
 	}
 )
 
@@ -597,7 +955,7 @@ func yylex1(yylex yyLexer, lval *yySymType) (n int) {
 }
 
 func yyParse(yylex yyLexer) int {
-	const yyError = 79
+	const yyError = 126
 
 	yyEx, _ := yylex.(yyLexerEx)
 	var yyn int
@@ -804,95 +1162,183 @@ yynewstate:
 		}
 	case 5:
 		{
-			yyVAL.stmt = Statement(yyS[yypt-0].beginStmt)
+			yyVAL.value = &Value{Str: yyS[yypt-0].str}
 		}
 	case 6:
 		{
-			yyVAL.stmt = Statement(yyS[yypt-0].commitStmt)
+			yyVAL.value = &Value{Null: true}
 		}
 	case 7:
 		{
-			yyVAL.stmt = Statement(yyS[yypt-0].rollbackStmt)
+			n, _ := strconv.Atoi(yyS[yypt-0].str)
+			yyVAL.value = &Value{Param: n}
 		}
 	case 8:
 		{
-			yyVAL.stmt = Statement(yyS[yypt-0].createStmt)
+			yyVAL.valueList = []*Value{yyS[yypt-0].value}
 		}
 	case 9:
 		{
-			yyVAL.stmt = Statement(yyS[yypt-0].selectStmt)
+			yyVAL.valueList = append(yyS[yypt-2].valueList, yyS[yypt-0].value)
 		}
 	case 10:
 		{
-			yyVAL.stmt = Statement(yyS[yypt-0].insertStmt)
+			yyVAL.stmt = Statement(yyS[yypt-0].beginStmt)
 		}
 	case 11:
 		{
-			yyVAL.stmt = Statement(yyS[yypt-0].updateStmt)
+			yyVAL.stmt = Statement(yyS[yypt-0].commitStmt)
 		}
 	case 12:
 		{
-			yyVAL.stmt = Statement(yyS[yypt-0].deleteStmt)
+			yyVAL.stmt = Statement(yyS[yypt-0].rollbackStmt)
 		}
 	case 13:
 		{
-			yyVAL.stmtList = append(yyVAL.stmtList, yyS[yypt-0].stmt)
+			yyVAL.stmt = Statement(yyS[yypt-0].createStmt)
 		}
 	case 14:
 		{
-			yyVAL.stmtList = append(yyVAL.stmtList, yyS[yypt-0].stmt)
+			yyVAL.stmt = Statement(yyS[yypt-0].alterStmt)
 		}
 	case 15:
 		{
-			yyVAL.str = ""
+			yyVAL.stmt = Statement(yyS[yypt-0].dropStmt)
 		}
 	case 16:
 		{
-			yyVAL.str = ""
+			yyVAL.stmt = Statement(yyS[yypt-0].truncateStmt)
 		}
 	case 17:
 		{
-			yyVAL.str = ""
+			yyVAL.stmt = Statement(yyS[yypt-0].createIndexStmt)
 		}
 	case 18:
 		{
-			yyVAL.str = yyS[yypt-0].str
+			yyVAL.stmt = Statement(yyS[yypt-0].dropIndexStmt)
 		}
 	case 19:
 		{
-			yyVAL.boolean = true
+			yyVAL.stmt = Statement(yyS[yypt-0].analyzeStmt)
 		}
 	case 20:
 		{
-			yyVAL.boolean = true
+			yyVAL.stmt = Statement(yyS[yypt-0].selectStmt)
 		}
 	case 21:
 		{
-			yyVAL.boolean = false
+			yyVAL.stmt = Statement(yyS[yypt-0].insertStmt)
 		}
 	case 22:
 		{
-			t, ok := typeMapping[yyS[yypt-0].str]
-			if ok {
-				yyVAL.fieldType = t
-			} else {
-				__yyfmt__.Printf("不支持的数据类型 %s", yyS[yypt-0].str)
+			yyVAL.stmt = Statement(yyS[yypt-0].updateStmt)
+		}
+	case 23:
+		{
+			yyVAL.stmt = Statement(yyS[yypt-0].deleteStmt)
+		}
+	case 24:
+		{
+			yyVAL.stmtList = append(yyVAL.stmtList, yyS[yypt-0].stmt)
+		}
+	case 25:
+		{
+			yyVAL.stmtList = append(yyVAL.stmtList, yyS[yypt-0].stmt)
+		}
+	case 26:
+		{
+			yyVAL.createDefault = nil
+		}
+	case 27:
+		{
+			yyVAL.createDefault = nil
+		}
+	case 28:
+		{
+			yyVAL.createDefault = &CreateDefault{
+				Value: &Value{Null: true},
+			}
+		}
+	case 29:
+		{
+			yyVAL.createDefault = &CreateDefault{
+				Value: &Value{Str: yyS[yypt-0].str},
+			}
+		}
+	case 30:
+		{
+			yyVAL.createDefault = &CreateDefault{
+				Expr: CurrentTimestamp,
+			}
+		}
+	case 31:
+		{
+			yyVAL.boolean = true
+		}
+	case 32:
+		{
+			yyVAL.boolean = true
+		}
+	case 33:
+		{
+			yyVAL.boolean = false
+		}
+	case 34:
+		{
+			yyVAL.boolean = false
+		}
+	case 35:
+		{
+			yyVAL.boolean = true
+		}
+	case 36:
+		{
+			t, err := newColumnType(yyS[yypt-0].str)
+			if err != nil {
+				yylex.Error(err.Error())
 				goto ret1
 			}
+			yyVAL.fieldType = t
 		}
-	case 23:
+	case 37:
+		{
+			t, err := newColumnType(yyS[yypt-3].str, yyS[yypt-1].str)
+			if err != nil {
+				yylex.Error(err.Error())
+				goto ret1
+			}
+			yyVAL.fieldType = t
+		}
+	case 38:
+		{
+			t, err := newColumnType(yyS[yypt-5].str, yyS[yypt-3].str, yyS[yypt-1].str)
+			if err != nil {
+				yylex.Error(err.Error())
+				goto ret1
+			}
+			yyVAL.fieldType = t
+		}
+	case 39:
+		{
+			yyVAL.beginStmt = &BeginStmt{""}
+		}
+	case 40:
 		{
 			yyVAL.beginStmt = &BeginStmt{yyS[yypt-1].str}
 		}
-	case 24:
+	case 41:
+		{
+			yyVAL.beginStmt = &BeginStmt{yyS[yypt-2].str + " " + yyS[yypt-1].str}
+		}
+	case 42:
 		{
 			yyVAL.commitStmt = &CommitStmt{}
 		}
-	case 25:
+	case 43:
 		{
 			yyVAL.rollbackStmt = &RollbackStmt{}
 		}
-	case 26:
+	case 44:
 		{
 			yyVAL.createStmt = &CreateStmt{
 				Name:   yyS[yypt-5].str,
@@ -900,21 +1346,21 @@ yynewstate:
 				Option: yyS[yypt-1].createTableOption,
 			}
 		}
-	case 27:
+	case 45:
 		{
 			yyVAL.createTable = &CreateTable{
 				Field: []*CreateField{yyS[yypt-0].createField},
 				Index: []*CreateIndex{},
 			}
 		}
-	case 28:
+	case 46:
 		{
 			yyVAL.createTable = &CreateTable{
 				Field: []*CreateField{},
 				Index: []*CreateIndex{yyS[yypt-0].createIndex},
 			}
 		}
-	case 29:
+	case 47:
 		{
 			yyVAL.createTable = &CreateTable{
 				Pk:    yyS[yypt-0].createIndex,
@@ -922,15 +1368,15 @@ yynewstate:
 				Index: []*CreateIndex{},
 			}
 		}
-	case 30:
+	case 48:
 		{
 			yyVAL.createTable.Field = append(yyVAL.createTable.Field, yyS[yypt-0].createField)
 		}
-	case 31:
+	case 49:
 		{
 			yyVAL.createTable.Index = append(yyVAL.createTable.Index, yyS[yypt-0].createIndex)
 		}
-	case 32:
+	case 50:
 		{
 			if yyVAL.createTable.Pk == nil {
 				yyVAL.createTable.Pk = yyS[yypt-0].createIndex
@@ -939,58 +1385,164 @@ yynewstate:
 				goto ret1
 			}
 		}
-	case 33:
+	case 51:
 		{
 			yyVAL.createField = &CreateField{
-				Name:     yyS[yypt-3].str,
-				Type:     yyS[yypt-2].fieldType,
-				Default:  yyS[yypt-0].str,
-				Nullable: yyS[yypt-1].boolean,
+				Name:          yyS[yypt-4].str,
+				Type:          yyS[yypt-3].fieldType,
+				Default:       yyS[yypt-1].createDefault,
+				Nullable:      yyS[yypt-2].boolean,
+				AutoIncrement: yyS[yypt-0].boolean,
 			}
 		}
-	case 34:
+	case 52:
 		{
 			yyVAL.createIndex = &CreateIndex{
 				Name:  yyS[yypt-3].str,
-				Field: yyS[yypt-1].str,
+				Field: yyS[yypt-1].strList,
 			}
 		}
-	case 35:
+	case 53:
 		{
 			yyVAL.createIndex = &CreateIndex{
 				Pk:    true,
-				Field: yyS[yypt-1].str,
+				Field: yyS[yypt-1].strList,
 			}
 		}
-	case 36:
+	case 54:
 		{
 			yyVAL.createTableOption = nil
 		}
-	case 37:
+	case 55:
+		{
+			yyVAL.alterStmt = yyS[yypt-1].alterStmt
+			yyVAL.alterStmt.Table = yyS[yypt-2].str
+		}
+	case 56:
+		{
+			yyVAL.alterStmt = &AlterStmt{
+				Action: AddColumn,
+				Field:  yyS[yypt-0].createField,
+			}
+		}
+	case 57:
+		{
+			yyVAL.alterStmt = &AlterStmt{
+				Action: AddColumn,
+				Field:  yyS[yypt-0].createField,
+			}
+		}
+	case 58:
+		{
+			yyVAL.alterStmt = &AlterStmt{
+				Action: DropColumn,
+				Name:   yyS[yypt-0].str,
+			}
+		}
+	case 59:
+		{
+			yyVAL.alterStmt = &AlterStmt{
+				Action: DropColumn,
+				Name:   yyS[yypt-0].str,
+			}
+		}
+	case 60:
+		{
+			yyVAL.alterStmt = &AlterStmt{
+				Action:  RenameColumn,
+				Name:    yyS[yypt-2].str,
+				NewName: yyS[yypt-0].str,
+			}
+		}
+	case 61:
+		{
+			yyVAL.alterStmt = &AlterStmt{
+				Action:  RenameTable,
+				NewName: yyS[yypt-0].str,
+			}
+		}
+	case 62:
+		{
+			yyVAL.boolean = false
+		}
+	case 63:
+		{
+			yyVAL.boolean = true
+		}
+	case 64:
+		{
+			yyVAL.dropStmt = &DropStmt{
+				Table:    yyS[yypt-1].str,
+				IfExists: yyS[yypt-2].boolean,
+			}
+		}
+	case 65:
+		{
+			yyVAL.truncateStmt = &TruncateStmt{
+				Table: yyS[yypt-1].str,
+			}
+		}
+	case 66:
+		{
+			yyVAL.boolean = false
+		}
+	case 67:
+		{
+			yyVAL.boolean = true
+		}
+	case 68:
+		{
+			yyVAL.createIndexStmt = &CreateIndexStmt{
+				Name:   yyS[yypt-6].str,
+				Table:  yyS[yypt-4].str,
+				Field:  yyS[yypt-2].strList,
+				Unique: yyS[yypt-8].boolean,
+			}
+		}
+	case 69:
+		{
+			yyVAL.dropIndexStmt = &DropIndexStmt{
+				Name: yyS[yypt-1].str,
+			}
+		}
+	case 70:
+		{
+			yyVAL.dropIndexStmt = &DropIndexStmt{
+				Name:  yyS[yypt-3].str,
+				Table: yyS[yypt-1].str,
+			}
+		}
+	case 71:
+		{
+			yyVAL.analyzeStmt = &AnalyzeStmt{
+				Table: yyS[yypt-1].str,
+			}
+		}
+	case 72:
 		{
 			yyVAL.insertStmt = &InsertStmt{
 				Table: yyS[yypt-3].str,
 				Field: yyS[yypt-2].strList,
-				Value: yyS[yypt-1].strList,
+				Value: yyS[yypt-1].valueList,
 			}
 		}
-	case 38:
+	case 73:
 		{
 			yyVAL.strList = yyS[yypt-1].strList
 		}
-	case 39:
+	case 74:
 		{
 			yyVAL.strList = nil
 		}
-	case 41:
+	case 76:
 		{
-			yyVAL.strList = yyS[yypt-1].strList
+			yyVAL.valueList = yyS[yypt-1].valueList
 		}
-	case 42:
+	case 77:
 		{
-			yyVAL.strList = nil
+			yyVAL.valueList = nil
 		}
-	case 44:
+	case 79:
 		{
 			yyVAL.updateStmt = &UpdateStmt{
 				Table: yyS[yypt-4].str,
@@ -998,119 +1550,248 @@ yynewstate:
 				Where: yyS[yypt-1].selectWhereList,
 			}
 		}
-	case 45:
+	case 80:
 		{
-			yyVAL.updateValue = map[string]string{
-				yyS[yypt-2].str: yyS[yypt-0].str,
+			yyVAL.updateValue = map[string]*Value{
+				yyS[yypt-2].str: yyS[yypt-0].value,
 			}
 		}
-	case 46:
+	case 81:
 		{
-			yyVAL.updateValue[yyS[yypt-2].str] = yyS[yypt-0].str
+			yyVAL.updateValue[yyS[yypt-2].str] = yyS[yypt-0].value
 		}
-	case 47:
+	case 82:
 		{
 			yyVAL.deleteStmt = &DeleteStmt{
 				Table: yyS[yypt-2].str,
 				Where: yyS[yypt-1].selectWhereList,
 			}
 		}
-	case 48:
+	case 83:
 		{
 			yyVAL.boolean = true
 		}
-	case 49:
+	case 84:
 		{
 			yyVAL.boolean = true
 		}
-	case 50:
+	case 85:
 		{
 			yyVAL.boolean = false
 		}
-	case 51:
+	case 86:
 		{
 			yyVAL.compareOperate = EQ
 		}
-	case 52:
+	case 87:
 		{
 			yyVAL.compareOperate = LT
 		}
-	case 53:
+	case 88:
 		{
 			yyVAL.compareOperate = GT
 		}
-	case 54:
+	case 89:
 		{
 			yyVAL.compareOperate = LE
 		}
-	case 55:
+	case 90:
 		{
 			yyVAL.compareOperate = GE
 		}
-	case 56:
+	case 91:
 		{
 			yyVAL.compareOperate = NE
 		}
-	case 57:
+	case 92:
 		{
 			yyVAL.selectStmt = &SelectStmt{
 				Field: yyS[yypt-2].selectFieldList,
 				Limit: yyS[yypt-1].selectLimit,
 			}
 		}
-	case 58:
+	case 93:
 		{
 			yyVAL.selectStmt = &SelectStmt{
-				Table: yyS[yypt-4].str,
-				Field: yyS[yypt-6].selectFieldList,
-				Where: yyS[yypt-3].selectWhereList,
-				Order: yyS[yypt-2].selectOrderList,
-				Limit: yyS[yypt-1].selectLimit,
+				Table:  yyS[yypt-7].strList[0],
+				Alias:  yyS[yypt-7].strList[1],
+				Join:   yyS[yypt-6].selectJoinList,
+				Field:  yyS[yypt-9].selectFieldList,
+				Where:  yyS[yypt-5].selectWhereList,
+				Group:  yyS[yypt-4].strList,
+				Having: yyS[yypt-3].selectWhereList,
+				Order:  yyS[yypt-2].selectOrderList,
+				Limit:  yyS[yypt-1].selectLimit,
 			}
 		}
-	case 59:
+	case 94:
 		{
-			yyVAL.selectFieldList = []*SelectField{
-				&SelectField{
-					Name: yyS[yypt-0].str,
-				},
+			yyVAL.strList = []string{yyS[yypt-0].str, ""}
+		}
+	case 95:
+		{
+			yyVAL.strList = []string{yyS[yypt-1].str, yyS[yypt-0].str}
+		}
+	case 96:
+		{
+			yyVAL.strList = []string{yyS[yypt-2].str, yyS[yypt-0].str}
+		}
+	case 97:
+		{
+			yyVAL.selectJoinList = nil
+		}
+	case 98:
+		{
+			yyVAL.selectJoinList = append(yyS[yypt-1].selectJoinList, yyS[yypt-0].selectJoin)
+		}
+	case 99:
+		{
+			yyVAL.selectJoin = &SelectJoin{
+				Left:  yyS[yypt-3].boolean,
+				Table: yyS[yypt-1].strList[0],
+				Alias: yyS[yypt-1].strList[1],
+				On:    yyS[yypt-0].selectJoinOnList,
 			}
 		}
-	case 60:
+	case 100:
+		{
+			yyVAL.boolean = false
+		}
+	case 101:
+		{
+			yyVAL.boolean = false
+		}
+	case 102:
+		{
+			yyVAL.boolean = true
+		}
+	case 103:
+		{
+			yyVAL.boolean = true
+		}
+	case 104:
+		{
+			yyVAL.selectJoinOnList = nil
+		}
+	case 105:
+		{
+			yyVAL.selectJoinOnList = yyS[yypt-0].selectJoinOnList
+		}
+	case 106:
+		{
+			yyVAL.selectJoinOnList = []*SelectJoinOn{yyS[yypt-0].selectJoinOn}
+		}
+	case 107:
+		{
+			yyVAL.selectJoinOnList = append(yyS[yypt-2].selectJoinOnList, yyS[yypt-0].selectJoinOn)
+		}
+	case 108:
 		{
-			yyVAL.selectFieldList = append(yyS[yypt-2].selectFieldList, &SelectField{
+			on, err := newJoinOn(yyS[yypt-2].str, yyS[yypt-1].compareOperate, yyS[yypt-0].str)
+			if err != nil {
+				yylex.Error(err.Error())
+				goto ret1
+			}
+			yyVAL.selectJoinOn = on
+		}
+	case 109:
+		{
+			yyVAL.selectFieldList = []*SelectField{yyS[yypt-0].selectField}
+		}
+	case 110:
+		{
+			yyVAL.selectFieldList = append(yyS[yypt-2].selectFieldList, yyS[yypt-0].selectField)
+		}
+	case 111:
+		{
+			yyVAL.selectField = &SelectField{
 				Name: yyS[yypt-0].str,
-			})
+			}
 		}
-	case 61:
+	case 112:
 		{
-			yyVAL.selectWhereList = nil
+			yyVAL.selectField = &SelectField{
+				Name:  yyS[yypt-2].str,
+				Alias: yyS[yypt-0].str,
+			}
 		}
-	case 62:
+	case 113:
 		{
-			yyVAL.selectWhereList = yyS[yypt-0].selectWhereList
+			fn, err := ParseFunc(yyS[yypt-3].str)
+			if err != nil {
+				yylex.Error(err.Error())
+				goto ret1
+			}
+			yyVAL.selectField = &SelectField{
+				Name: yyS[yypt-1].str,
+				Func: fn,
+			}
 		}
-	case 63:
+	case 114:
 		{
-			yyVAL.selectWhereList = []SelectWhere{
-				&SelectWhereField{
-					Field:   yyS[yypt-2].str,
-					Value:   yyS[yypt-0].str,
-					Operate: yyS[yypt-1].compareOperate,
-				},
+			fn, err := ParseFunc(yyS[yypt-5].str)
+			if err != nil {
+				yylex.Error(err.Error())
+				goto ret1
+			}
+			yyVAL.selectField = &SelectField{
+				Name:  yyS[yypt-3].str,
+				Alias: yyS[yypt-0].str,
+				Func:  fn,
 			}
 		}
-	case 64:
+	case 115:
+		{
+			yyVAL.str = yyS[yypt-0].str
+		}
+	case 116:
+		{
+			fn, err := ParseFunc(yyS[yypt-3].str)
+			if err != nil {
+				yylex.Error(err.Error())
+				goto ret1
+			}
+			yyVAL.str = FuncName(fn, yyS[yypt-1].str)
+		}
+	case 117:
+		{
+			yyVAL.selectWhereList = nil
+		}
+	case 118:
+		{
+			yyVAL.selectWhereList = yyS[yypt-0].selectWhereList
+		}
+	case 119:
 		{
-			yyS[yypt-1].compareOperate.Negate()
-			field := &SelectWhereField{
+			yyVAL.selectWhere = &SelectWhereField{
 				Field:   yyS[yypt-2].str,
-				Value:   yyS[yypt-0].str,
+				Value:   yyS[yypt-0].value,
 				Operate: yyS[yypt-1].compareOperate,
 			}
+		}
+	case 120:
+		{
+			yyVAL.selectWhere = &SelectWhereNull{
+				Field: yyS[yypt-2].str,
+			}
+		}
+	case 121:
+		{
+			yyVAL.selectWhere = &SelectWhereNull{
+				Field: yyS[yypt-3].str,
+				Not:   true,
+			}
+		}
+	case 122:
+		{
+			yyVAL.selectWhereList = []SelectWhere{yyS[yypt-0].selectWhere}
+		}
+	case 123:
+		{
+			yyS[yypt-0].selectWhere.Negate()
 			if len(yyVAL.selectWhereList) == 1 {
 				yyVAL.selectWhereList[0].Negate()
-				yyVAL.selectWhereList = append(yyVAL.selectWhereList, field)
+				yyVAL.selectWhereList = append(yyVAL.selectWhereList, yyS[yypt-0].selectWhere)
 				yyVAL.selectWhereList = []SelectWhere{
 					&SelectWhereExpr{
 						Negation: true,
@@ -1126,21 +1807,17 @@ yynewstate:
 								Negation: true,
 								Cnf:      yyVAL.selectWhereList,
 							},
-							field,
+							yyS[yypt-0].selectWhere,
 						},
 					},
 				}
 			}
 		}
-	case 65:
+	case 124:
 		{
-			yyVAL.selectWhereList = append(yyVAL.selectWhereList, &SelectWhereField{
-				Field:   yyS[yypt-2].str,
-				Value:   yyS[yypt-0].str,
-				Operate: yyS[yypt-1].compareOperate,
-			})
+			yyVAL.selectWhereList = append(yyVAL.selectWhereList, yyS[yypt-0].selectWhere)
 		}
-	case 66:
+	case 125:
 		{
 			expr := &SelectWhereExpr{
 				Negation: true,
@@ -1170,19 +1847,35 @@ yynewstate:
 				}
 			}
 		}
-	case 67:
+	case 126:
 		{
 			yyVAL.selectWhereList = append(yyVAL.selectWhereList, yyS[yypt-1].selectWhereList...)
 		}
-	case 68:
+	case 127:
+		{
+			yyVAL.strList = nil
+		}
+	case 128:
+		{
+			yyVAL.strList = yyS[yypt-0].strList
+		}
+	case 129:
+		{
+			yyVAL.selectWhereList = nil
+		}
+	case 130:
+		{
+			yyVAL.selectWhereList = yyS[yypt-0].selectWhereList
+		}
+	case 131:
 		{
 			yyVAL.selectOrderList = nil
 		}
-	case 69:
+	case 132:
 		{
 			yyVAL.selectOrderList = yyS[yypt-0].selectOrderList
 		}
-	case 70:
+	case 133:
 		{
 			yyVAL.selectOrderList = []*SelectOrder{
 				&SelectOrder{
@@ -1191,18 +1884,18 @@ yynewstate:
 				},
 			}
 		}
-	case 71:
+	case 134:
 		{
 			yyVAL.selectOrderList = append(yyS[yypt-3].selectOrderList, &SelectOrder{
 				Asc:   yyS[yypt-0].boolean,
 				Field: yyS[yypt-1].str,
 			})
 		}
-	case 72:
+	case 135:
 		{
 			yyVAL.selectLimit = nil
 		}
-	case 73:
+	case 136:
 		{
 			limit, err := strconv.Atoi(yyS[yypt-0].str)
 			if err != nil {
@@ -1213,14 +1906,14 @@ yynewstate:
 				Limit: limit,
 			}
 		}
-	case 74:
+	case 137:
 		{
-			limit, err := strconv.Atoi(yyS[yypt-2].str)
+			limit, err := strconv.Atoi(yyS[yypt-0].str)
 			if err != nil {
 				yylex.Error(err.Error())
 				goto ret1
 			}
-			offset, err := strconv.Atoi(yyS[yypt-0].str)
+			offset, err := strconv.Atoi(yyS[yypt-2].str)
 			if err != nil {
 				yylex.Error(err.Error())
 				goto ret1
@@ -1230,7 +1923,7 @@ yynewstate:
 				Offset: offset,
 			}
 		}
-	case 75:
+	case 138:
 		{
 			limit, err := strconv.Atoi(yyS[yypt-2].str)
 			if err != nil {
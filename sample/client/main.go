@@ -1,5 +1,59 @@
 package main
 
+import (
+	"bufio"
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/ggymm/db/pkg/view"
+	"github.com/ggymm/db/server"
+)
+
 func main() {
+	addr := flag.String("addr", "127.0.0.1:3306", "server address")
+	flag.Parse()
+
+	c, err := server.Dial(*addr)
+	if err != nil {
+		println("Error connect:", err.Error())
+		os.Exit(1)
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		print("db> ")
+		in, err := reader.ReadString(';')
+		if err != nil {
+			println("Error reading input:", err.Error())
+			return
+		}
+
+		in = strings.TrimSpace(in)
+		if in == "exit;" {
+			return
+		}
+
+		in = strings.Replace(in, "\r", " ", -1)
+		in = strings.Replace(in, "\n", " ", -1)
+
+		res, err := c.Query(in)
+		if err != nil {
+			println("Error exec sql:", err.Error())
+			continue
+		}
+		if res.Columns == nil {
+			println("OK, affected rows:", res.Affected)
+			continue
+		}
 
+		// 表格形式输出
+		vt := view.NewTable()
+		vt.SetHead(res.Columns)
+		vt.SetBody(res.Rows)
+		println(vt.String())
+	}
 }
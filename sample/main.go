@@ -8,7 +8,8 @@ import (
 	"github.com/ggymm/db"
 	"github.com/ggymm/db/boot"
 	"github.com/ggymm/db/data"
-	"github.com/ggymm/db/pkg/sql"
+	"github.com/ggymm/db/pkg/view"
+	"github.com/ggymm/db/session"
 	"github.com/ggymm/db/table"
 	"github.com/ggymm/db/tx"
 	"github.com/ggymm/db/ver"
@@ -18,6 +19,8 @@ var (
 	tm  tx.Manage
 	dm  data.Manage
 	tbm table.Manage
+
+	sess session.Session
 )
 
 // 创建基础数据库
@@ -28,10 +31,12 @@ func init() {
 	tm = tx.NewManager(opt)
 	dm = data.NewManage(tm, opt)
 	tbm = table.NewManage(boot.New(opt), ver.NewManage(tm, dm), dm)
+	sess = session.New(tbm)
 }
 
-// 同步数据到磁盘
+// 回滚未提交的事务，同步数据到磁盘
 func exit() {
+	sess.Close()
 	tm.Close()
 	dm.Close()
 
@@ -43,7 +48,14 @@ func main() {
 	reader := bufio.NewReader(os.Stdin)
 
 	for {
-		print("db> ")
+		switch sess.Status() {
+		case session.StatusInTx:
+			print("db*> ")
+		case session.StatusFailed:
+			print("db!> ")
+		default:
+			print("db> ")
+		}
 		in, err := reader.ReadString(';')
 		if err != nil {
 			println("Error reading input:", err.Error())
@@ -63,42 +75,20 @@ func main() {
 			continue
 		}
 
-		// 解析 sql 语句
-		stmt, err := sql.ParseSQL(in)
-		if err != nil {
-			println("Error parsing input sql:", err.Error())
-			continue
-		}
-
-		// 默认事务
-		// 可重复读
-		tid := tbm.Begin(1)
-		switch stmt.StmtType() {
-		case sql.Create:
-			err = tbm.Create(tid, stmt.(*sql.CreateStmt))
-		case sql.Insert:
-			err = tbm.Insert(tid, stmt.(*sql.InsertStmt))
-		case sql.Update:
-			err = tbm.Update(tid, stmt.(*sql.UpdateStmt))
-		case sql.Delete:
-			err = tbm.Delete(tid, stmt.(*sql.DeleteStmt))
-		case sql.Select:
-			var entries []table.Entry
-			entries, err = tbm.Select(tid, stmt.(*sql.SelectStmt))
-			println(tbm.ShowResult(stmt.TableName(), entries))
-		default:
-			println("Error Unsupported stmt type:", stmt.StmtType())
-		}
+		res, err := sess.Execute(in)
 		if err != nil {
-			tbm.Rollback(tid)
 			println("Error exec sql:", err.Error())
 			continue
 		}
-		err = tbm.Commit(tid)
-		if err != nil {
-			println("Error exec sql:", err.Error())
+		if res.Columns == nil {
+			println("OK, affected rows:", res.Affected)
 			continue
 		}
-		println("OK")
+
+		// 表格形式输出
+		vt := view.NewTable()
+		vt.SetHead(res.Columns)
+		vt.SetBody(res.Rows)
+		println(vt.String())
 	}
 }
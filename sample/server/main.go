@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/ggymm/db"
+	"github.com/ggymm/db/boot"
+	"github.com/ggymm/db/data"
+	"github.com/ggymm/db/server"
+	"github.com/ggymm/db/table"
+	"github.com/ggymm/db/tx"
+	"github.com/ggymm/db/ver"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:3306", "listen address")
+	path := flag.String("path", "temp", "database path")
+	pg := flag.Bool("pg", false, "use PostgreSQL protocol")
+	flag.Parse()
+
+	opt := db.NewOption(*path)
+	opt.Memory = (1 << 20) * 64
+
+	tm := tx.NewManager(opt)
+	dm := data.NewManage(tm, opt)
+	tbm := table.NewManage(boot.New(opt), ver.NewManage(tm, dm), dm)
+
+	newServer := server.NewServer
+	if *pg {
+		newServer = server.NewPgServer
+	}
+	s, err := newServer(*addr, tbm)
+	if err != nil {
+		println("Error listen:", err.Error())
+		os.Exit(1)
+	}
+
+	// 收到退出信号时，关闭服务并同步数据到磁盘
+	go func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+		<-ch
+		_ = s.Close()
+	}()
+
+	println("Listen on", s.Addr().String())
+	_ = s.Serve()
+
+	tm.Close()
+	dm.Close()
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/ggymm/db/pkg/bin"
+	"github.com/ggymm/db/session"
+)
+
+type Client interface {
+	Query(sql string) (*session.Result, error)
+	Close() error
+}
+
+type client struct {
+	sync.Mutex
+	conn net.Conn
+}
+
+func Dial(addr string) (Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &client{
+		conn: conn,
+	}, nil
+}
+
+// Query 发送 sql 语句并等待响应
+// 执行失败时，返回服务端的错误信息
+func (c *client) Query(sql string) (*session.Result, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	err := writePacket(c.conn, MsgQuery, []byte(sql))
+	if err != nil {
+		return nil, err
+	}
+
+	typ, data, err := readPacket(c.conn)
+	if err != nil {
+		return nil, err
+	}
+	switch typ {
+	case MsgResult:
+		return parseResult(data)
+	case MsgOK:
+		if len(data) < 8 {
+			return nil, ErrPacketInvalid
+		}
+		return &session.Result{Affected: int(bin.Uint64(data))}, nil
+	case MsgError:
+		return nil, errors.New(string(data))
+	}
+	return nil, ErrPacketInvalid
+}
+
+func (c *client) Close() error {
+	c.Lock()
+	defer c.Unlock()
+
+	_ = writePacket(c.conn, MsgClose, nil)
+	return c.conn.Close()
+}
@@ -0,0 +1,886 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ggymm/db/pkg/sql"
+	"github.com/ggymm/db/session"
+	"github.com/ggymm/db/table"
+	"github.com/ggymm/db/ver"
+)
+
+// PostgreSQL 协议（v3）
+//
+// 实现了 PostgreSQL 前后端协议的一个子集，可以使用 psql 或者 pgx 等客户端连接
+// 协议中的整数都使用大端序
+//
+// 启动消息（没有类型）：
+// +----------------+----------------+----------------+
+// |     length     |    protocol    |     params     |
+// +----------------+----------------+----------------+
+// |     4 bytes    |     4 bytes    |       ...      |
+// +----------------+----------------+----------------+
+//
+// 其他消息：
+// +----------------+----------------+----------------+
+// |      type      |     length     |      data      |
+// +----------------+----------------+----------------+
+// |     1 byte     |     4 bytes    |       ...      |
+// +----------------+----------------+----------------+
+//
+// length: 包含自身的消息长度，不包含 type
+//
+// 连接流程：
+// 1. 客户端发送 SSLRequest 时，回复 'N'（不支持 SSL）
+// 2. 客户端发送 StartupMessage，回复 AuthenticationOk、ParameterStatus、BackendKeyData、ReadyForQuery
+//
+// 简单查询（'Q'）：
+// 回复 RowDescription、DataRow（查询语句），CommandComplete 或者 ErrorResponse，最后回复 ReadyForQuery
+//
+// 扩展查询（'P' 'B' 'D' 'E' 'S' 'C' 'H'）：
+// 参数和结果支持文本格式和二进制格式（int4、int8）
+// 发生错误后，忽略 Sync 之前的全部消息
+//
+// ReadyForQuery 中的事务状态：'I' 不在事务中，'T' 在事务中，'E' 在失败的事务中
+//
+// 处理消息时出现 panic，回复 ErrorResponse 之后关闭连接（参考 panicError）
+
+const (
+	pgProtocolVersion = 196608   // 3.0
+	pgSSLRequest      = 80877103 // 1234 5679
+	pgGSSRequest      = 80877104 // 1234 5680
+	pgCancelRequest   = 80877102 // 1234 5678
+
+	pgMaxMessageSize = 1 << 26
+)
+
+// 字段类型的 oid
+const (
+	pgTypeBool        = 16
+	pgTypeInt8        = 20
+	pgTypeInt4        = 23
+	pgTypeText        = 25
+	pgTypeFloat4      = 700
+	pgTypeFloat8      = 701
+	pgTypeVarchar     = 1043
+	pgTypeDate        = 1082
+	pgTypeTimestamp   = 1114
+	pgTypeTimestamptz = 1184
+	pgTypeNumeric     = 1700
+)
+
+// 二进制格式的时间从 2000-01-01 开始计算
+var pgEpoch = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// 错误码
+const (
+	pgCodeSyntaxError         = "42601"
+	pgCodeUndefinedTable      = "42P01"
+	pgCodeFeatureNotSupported = "0A000"
+	pgCodeProtocolViolation   = "08P01"
+	pgCodeSerializeFailure    = "40001"
+	pgCodeTxAborted           = "25P02"
+	pgCodeObjectInUse         = "55006"
+	pgCodeUniqueViolation     = "23505"
+	pgCodeLimitExceeded       = "54000"
+	pgCodeInternalError       = "XX000"
+)
+
+// pgStmt 扩展查询中的预备语句
+type pgStmt struct {
+	prepared *sql.Prepared // 空查询时为 nil
+	oids     []uint32      // 参数类型的 oid
+}
+
+// pgPortal 扩展查询中的门户（已经绑定参数的语句）
+type pgPortal struct {
+	stmt    sql.Statement // 空查询时为 nil
+	formats []int16       // 结果的格式，0：文本，1：二进制
+}
+
+type pgConn struct {
+	conn net.Conn
+	sess session.Session
+
+	r *bufio.Reader
+	w *bufio.Writer
+
+	stmts   map[string]*pgStmt
+	portals map[string]*pgPortal
+}
+
+// servePg 处理 PostgreSQL 协议的连接
+func servePg(conn net.Conn, sess session.Session) {
+	c := &pgConn{
+		conn: conn,
+		sess: sess,
+
+		r: bufio.NewReader(conn),
+		w: bufio.NewWriter(conn),
+
+		stmts:   make(map[string]*pgStmt),
+		portals: make(map[string]*pgPortal),
+	}
+	if !c.startup() {
+		return
+	}
+
+	// 扩展查询发生错误后，忽略 Sync 之前的全部消息
+	ignore := false
+	for {
+		typ, data, err := c.readMessage()
+		if err != nil {
+			return
+		}
+
+		if ignore && typ != 'S' && typ != 'X' {
+			continue
+		}
+
+		switch typ {
+		case 'S':
+			ignore = false
+			c.readyForQuery()
+		case 'X':
+			return
+		default:
+			err = recoverPanic(func() error {
+				return c.handle(typ, data)
+			})
+		}
+
+		var pe *panicError
+		if errors.As(err, &pe) {
+			c.writeError(pgCodeInternalError, pe.Error())
+			_ = c.w.Flush()
+			return
+		}
+		if err != nil {
+			c.writeError(pgErrorCode(err), err.Error())
+			ignore = true
+		}
+
+		if c.w.Flush() != nil {
+			return
+		}
+	}
+}
+
+// handle 处理简单查询和扩展查询中除了 Sync 和 Terminate 之外的消息
+func (c *pgConn) handle(typ byte, data []byte) error {
+	switch typ {
+	case 'Q':
+		c.simpleQuery(cstring(data))
+		c.readyForQuery()
+	case 'P':
+		return c.parse(data)
+	case 'B':
+		return c.bind(data)
+	case 'D':
+		return c.describe(data)
+	case 'E':
+		return c.execute(data)
+	case 'C':
+		return c.close(data)
+	case 'H':
+	default:
+		c.writeError(pgCodeProtocolViolation, fmt.Sprintf("unsupported message type %c", typ))
+		c.readyForQuery()
+	}
+	return nil
+}
+
+// startup 处理启动消息
+func (c *pgConn) startup() bool {
+	for {
+		head := make([]byte, 8)
+		_, err := io.ReadFull(c.r, head)
+		if err != nil {
+			return false
+		}
+		size := int(binary.BigEndian.Uint32(head))
+		if size < 8 || size > pgMaxMessageSize {
+			return false
+		}
+		data := make([]byte, size-8)
+		_, err = io.ReadFull(c.r, data)
+		if err != nil {
+			return false
+		}
+
+		switch binary.BigEndian.Uint32(head[4:]) {
+		case pgSSLRequest, pgGSSRequest:
+			_, err = c.conn.Write([]byte{'N'})
+			if err != nil {
+				return false
+			}
+			continue
+		case pgCancelRequest:
+			return false
+		case pgProtocolVersion:
+		default:
+			c.writeError(pgCodeFeatureNotSupported, "unsupported protocol version")
+			_ = c.w.Flush()
+			return false
+		}
+		break
+	}
+
+	// AuthenticationOk
+	c.writeMessage('R', binary.BigEndian.AppendUint32(nil, 0))
+
+	// ParameterStatus
+	for _, kv := range [][2]string{
+		{"server_version", "14.0"},
+		{"server_encoding", "UTF8"},
+		{"client_encoding", "UTF8"},
+		{"DateStyle", "ISO, MDY"},
+		{"integer_datetimes", "on"},
+		{"standard_conforming_strings", "on"},
+	} {
+		buf := append([]byte(kv[0]), 0)
+		buf = append(buf, kv[1]...)
+		buf = append(buf, 0)
+		c.writeMessage('S', buf)
+	}
+
+	// BackendKeyData（不支持取消请求）
+	c.writeMessage('K', make([]byte, 8))
+
+	c.readyForQuery()
+	return c.w.Flush() == nil
+}
+
+func (c *pgConn) readMessage() (byte, []byte, error) {
+	head := make([]byte, 5)
+	_, err := io.ReadFull(c.r, head)
+	if err != nil {
+		return 0, nil, err
+	}
+	size := int(binary.BigEndian.Uint32(head[1:]))
+	if size < 4 || size > pgMaxMessageSize {
+		return 0, nil, ErrPacketTooLarge
+	}
+
+	data := make([]byte, size-4)
+	_, err = io.ReadFull(c.r, data)
+	if err != nil {
+		return 0, nil, err
+	}
+	return head[0], data, nil
+}
+
+func (c *pgConn) writeMessage(typ byte, data []byte) {
+	buf := make([]byte, 5, 5+len(data))
+	buf[0] = typ
+	binary.BigEndian.PutUint32(buf[1:], uint32(4+len(data)))
+	buf = append(buf, data...)
+
+	// 写入失败时，会在 Flush 时返回错误
+	_, _ = c.w.Write(buf)
+}
+
+func (c *pgConn) readyForQuery() {
+	c.writeMessage('Z', []byte{c.sess.Status()})
+}
+
+func (c *pgConn) writeError(code, msg string) {
+	buf := make([]byte, 0)
+	for _, f := range []struct {
+		typ byte
+		val string
+	}{
+		{'S', "ERROR"},
+		{'V', "ERROR"},
+		{'C', code},
+		{'M', msg},
+	} {
+		buf = append(buf, f.typ)
+		buf = append(buf, f.val...)
+		buf = append(buf, 0)
+	}
+	buf = append(buf, 0)
+	c.writeMessage('E', buf)
+}
+
+// simpleQuery 处理简单查询
+func (c *pgConn) simpleQuery(query string) {
+	query = pgQuery(query)
+	if query == "" {
+		c.writeMessage('I', nil) // EmptyQueryResponse
+		return
+	}
+
+	p, err := c.sess.Prepare(query)
+	if err != nil {
+		c.writeError(pgErrorCode(err), err.Error())
+		return
+	}
+	stmt, err := p.Bind()
+	if err != nil {
+		c.writeError(pgErrorCode(err), err.Error())
+		return
+	}
+	res, err := c.sess.Run(stmt)
+	if err != nil {
+		c.writeError(pgErrorCode(err), err.Error())
+		return
+	}
+	if res.Columns != nil {
+		c.writeRowDescription(res, nil)
+	}
+	err = c.writeResult(stmt, res, nil)
+	if err != nil {
+		c.writeError(pgErrorCode(err), err.Error())
+	}
+}
+
+// parse 处理 Parse 消息
+func (c *pgConn) parse(data []byte) error {
+	r := bytes.NewBuffer(data)
+	name := readCString(r)
+	query := pgQuery(readCString(r))
+
+	// 参数类型，0 表示未指定
+	ps := &pgStmt{}
+	n := readInt16(r)
+	for i := 0; i < int(n); i++ {
+		ps.oids = append(ps.oids, uint32(readInt32(r)))
+	}
+
+	if query != "" {
+		p, err := c.sess.Prepare(query)
+		if err != nil {
+			return err
+		}
+		ps.prepared = p
+	}
+	c.stmts[name] = ps
+	c.writeMessage('1', nil) // ParseComplete
+	return nil
+}
+
+// bind 处理 Bind 消息
+func (c *pgConn) bind(data []byte) error {
+	r := bytes.NewBuffer(data)
+	portal := readCString(r)
+	name := readCString(r)
+
+	ps, ok := c.stmts[name]
+	if !ok {
+		return fmt.Errorf("prepared statement %q does not exist", name)
+	}
+
+	// 参数格式
+	n := readInt16(r)
+	paramFormats := make([]int16, 0, n)
+	for i := 0; i < int(n); i++ {
+		paramFormats = append(paramFormats, readInt16(r))
+	}
+
+	// 参数
+	n = readInt16(r)
+	args := make([]any, 0, n)
+	for i := 0; i < int(n); i++ {
+		size := readInt32(r)
+		if size < 0 {
+			args = append(args, nil)
+			continue
+		}
+		val := r.Next(int(size))
+		if pgFormat(paramFormats, i) == 1 {
+			args = append(args, pgParseBinary(ps.oid(i), val))
+		} else {
+			args = append(args, string(val))
+		}
+	}
+
+	// 结果格式
+	n = readInt16(r)
+	formats := make([]int16, 0, n)
+	for i := 0; i < int(n); i++ {
+		formats = append(formats, readInt16(r))
+	}
+
+	p := &pgPortal{
+		formats: formats,
+	}
+	if ps.prepared != nil {
+		stmt, err := ps.prepared.Bind(args...)
+		if err != nil {
+			return err
+		}
+		p.stmt = stmt
+	}
+	c.portals[portal] = p
+	c.writeMessage('2', nil) // BindComplete
+	return nil
+}
+
+// describe 处理 Describe 消息
+func (c *pgConn) describe(data []byte) error {
+	r := bytes.NewBuffer(data)
+	kind, _ := r.ReadByte()
+	name := readCString(r)
+
+	var (
+		stmt    sql.Statement
+		formats []int16
+	)
+	switch kind {
+	case 'S':
+		ps, ok := c.stmts[name]
+		if !ok {
+			return fmt.Errorf("prepared statement %q does not exist", name)
+		}
+
+		// ParameterDescription
+		n := 0
+		if ps.prepared != nil {
+			stmt = ps.prepared.Statement()
+			n = ps.prepared.NumParams()
+		}
+		buf := binary.BigEndian.AppendUint16(nil, uint16(n))
+		for i := 0; i < n; i++ {
+			buf = binary.BigEndian.AppendUint32(buf, ps.oid(i))
+		}
+		c.writeMessage('t', buf)
+	case 'P':
+		p, ok := c.portals[name]
+		if !ok {
+			return fmt.Errorf("portal %q does not exist", name)
+		}
+		stmt = p.stmt
+		formats = p.formats
+	default:
+		return fmt.Errorf("invalid describe kind %c", kind)
+	}
+
+	res, err := c.sess.Describe(stmt)
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		c.writeMessage('n', nil) // NoData
+		return nil
+	}
+	c.writeRowDescription(res, formats)
+	return nil
+}
+
+// execute 处理 Execute 消息
+// 不支持限制返回的行数，总是返回全部数据
+func (c *pgConn) execute(data []byte) error {
+	r := bytes.NewBuffer(data)
+	name := readCString(r)
+
+	p, ok := c.portals[name]
+	if !ok {
+		return fmt.Errorf("portal %q does not exist", name)
+	}
+	if p.stmt == nil {
+		c.writeMessage('I', nil) // EmptyQueryResponse
+		return nil
+	}
+
+	res, err := c.sess.Run(p.stmt)
+	if err != nil {
+		return err
+	}
+	return c.writeResult(p.stmt, res, p.formats)
+}
+
+// close 处理 Close 消息
+func (c *pgConn) close(data []byte) error {
+	r := bytes.NewBuffer(data)
+	kind, _ := r.ReadByte()
+	name := readCString(r)
+
+	switch kind {
+	case 'S':
+		delete(c.stmts, name)
+	case 'P':
+		delete(c.portals, name)
+	default:
+		return fmt.Errorf("invalid close kind %c", kind)
+	}
+	c.writeMessage('3', nil) // CloseComplete
+	return nil
+}
+
+func (c *pgConn) writeRowDescription(res *session.Result, formats []int16) {
+	buf := binary.BigEndian.AppendUint16(nil, uint16(len(res.Columns)))
+	for i, col := range res.Columns {
+		oid, size := pgType(res.Types[i])
+
+		buf = append(buf, col...)
+		buf = append(buf, 0)
+		buf = binary.BigEndian.AppendUint32(buf, 0) // 表的 oid
+		buf = binary.BigEndian.AppendUint16(buf, 0) // 字段的编号
+		buf = binary.BigEndian.AppendUint32(buf, oid)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(size))
+		buf = binary.BigEndian.AppendUint32(buf, 0xffffffff) // 类型修饰符
+		buf = binary.BigEndian.AppendUint16(buf, uint16(pgFormat(formats, i)))
+	}
+	c.writeMessage('T', buf)
+}
+
+// writeResult 回复 DataRow 和 CommandComplete
+//
+// DataRow 超过消息的最大长度时返回 ErrResultTooLarge（由调用方回复 ErrorResponse，已经回复的 DataRow 不会撤回）
+func (c *pgConn) writeResult(stmt sql.Statement, res *session.Result, formats []int16) error {
+	for n, row := range res.Rows {
+		buf := binary.BigEndian.AppendUint16(nil, uint16(len(row)))
+		for i, val := range row {
+			if res.IsNull(n, i) {
+				buf = binary.BigEndian.AppendUint32(buf, 0xffffffff) // NULL
+				continue
+			}
+			v := []byte(pgText(res.Types[i], val))
+			if pgFormat(formats, i) == 1 {
+				v = pgBinary(res.Types[i], val)
+			}
+			buf = binary.BigEndian.AppendUint32(buf, uint32(len(v)))
+			buf = append(buf, v...)
+		}
+		if 4+len(buf) > pgMaxMessageSize {
+			return ErrResultTooLarge
+		}
+		c.writeMessage('D', buf)
+	}
+
+	tag := pgCommandTag(stmt, res)
+	c.writeMessage('C', append([]byte(tag), 0))
+	return nil
+}
+
+// pgQuery 去除首尾的空白字符，并补全语句结尾的分号
+func pgQuery(query string) string {
+	query = strings.TrimSpace(query)
+	if query == "" || query == ";" {
+		return ""
+	}
+	if !strings.HasSuffix(query, ";") {
+		query += ";"
+	}
+	return query
+}
+
+func pgType(typ string) (oid uint32, size int16) {
+	ct, _ := sql.ParseColumnType(typ)
+	switch ct.Type {
+	case sql.Int32:
+		return pgTypeInt4, 4
+	case sql.Int64:
+		return pgTypeInt8, 8
+	case sql.Bool:
+		return pgTypeBool, 1
+	case sql.Double:
+		return pgTypeFloat8, 8
+	case sql.Decimal:
+		return pgTypeNumeric, -1
+	case sql.Timestamp:
+		return pgTypeTimestamp, 8
+	case sql.Date:
+		return pgTypeDate, 4
+	case sql.Varchar:
+		return pgTypeVarchar, -1
+	}
+	return pgTypeText, -1
+}
+
+// pgFormat 获取第 i 个字段的格式
+// 没有指定时为文本格式，只指定一个时应用到全部字段
+func pgFormat(formats []int16, i int) int16 {
+	switch len(formats) {
+	case 0:
+		return 0
+	case 1:
+		return formats[0]
+	}
+	if i < len(formats) {
+		return formats[i]
+	}
+	return 0
+}
+
+// oid 第 i 个参数类型的 oid，未指定时为 text
+func (ps *pgStmt) oid(i int) uint32 {
+	if i < len(ps.oids) && ps.oids[i] != 0 {
+		return ps.oids[i]
+	}
+	return pgTypeText
+}
+
+// pgParseBinary 解析二进制格式的参数
+func pgParseBinary(oid uint32, val []byte) any {
+	switch {
+	case oid == pgTypeBool && len(val) == 1:
+		return val[0] != 0
+	case oid == pgTypeInt4 && len(val) == 4:
+		return int32(binary.BigEndian.Uint32(val))
+	case oid == pgTypeInt8 && len(val) == 8:
+		return int64(binary.BigEndian.Uint64(val))
+	case oid == pgTypeFloat4 && len(val) == 4:
+		return math.Float32frombits(binary.BigEndian.Uint32(val))
+	case oid == pgTypeFloat8 && len(val) == 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(val))
+	case oid == pgTypeNumeric && len(val) >= 8:
+		return pgParseNumeric(val)
+	case (oid == pgTypeTimestamp || oid == pgTypeTimestamptz) && len(val) == 8:
+		us := int64(binary.BigEndian.Uint64(val))
+		return time.UnixMicro(pgEpoch.UnixMicro() + us).UTC()
+	case oid == pgTypeDate && len(val) == 4:
+		days := int32(binary.BigEndian.Uint32(val))
+		return pgEpoch.AddDate(0, 0, int(days))
+	}
+	return string(val)
+}
+
+// pgText 将值转换为 PostgreSQL 的文本格式
+func pgText(typ, val string) string {
+	ct, _ := sql.ParseColumnType(typ)
+	if ct.Type == sql.Bool {
+		b, _ := strconv.ParseBool(val)
+		if b {
+			return "t"
+		}
+		return "f"
+	}
+	return val
+}
+
+// pgBinary 将文本格式的值转换为二进制格式
+func pgBinary(typ, val string) []byte {
+	ct, _ := sql.ParseColumnType(typ)
+	switch ct.Type {
+	case sql.Int32:
+		v, _ := strconv.ParseInt(val, 10, 32)
+		return binary.BigEndian.AppendUint32(nil, uint32(v))
+	case sql.Int64:
+		v, _ := strconv.ParseInt(val, 10, 64)
+		return binary.BigEndian.AppendUint64(nil, uint64(v))
+	case sql.Bool:
+		v, _ := strconv.ParseBool(val)
+		if v {
+			return []byte{1}
+		}
+		return []byte{0}
+	case sql.Double:
+		v, _ := strconv.ParseFloat(val, 64)
+		return binary.BigEndian.AppendUint64(nil, math.Float64bits(v))
+	case sql.Decimal:
+		return pgNumeric(val)
+	case sql.Timestamp:
+		v, _ := time.Parse(sql.TimestampFormat, val)
+		return binary.BigEndian.AppendUint64(nil, uint64(v.UnixMicro()-pgEpoch.UnixMicro()))
+	case sql.Date:
+		v, _ := time.Parse(sql.DateFormat, val)
+		days := (v.Unix() - pgEpoch.Unix()) / (24 * 60 * 60)
+		return binary.BigEndian.AppendUint32(nil, uint32(int32(days)))
+	}
+	return []byte(val)
+}
+
+// numeric 的二进制格式
+//
+// +----------------+----------------+----------------+----------------+----------------+
+// |    ndigits     |     weight     |      sign      |     dscale     |     digits     |
+// +----------------+----------------+----------------+----------------+----------------+
+// |     2 bytes    |     2 bytes    |     2 bytes    |     2 bytes    | ndigits * 2    |
+// +----------------+----------------+----------------+----------------+----------------+
+//
+// digits: 万进制的每一位（0 - 9999），从高位到低位
+// weight: 第一位的权重，值为 digits[0] * 10000^weight + ...
+// sign: 0x0000 正数，0x4000 负数，0xC000 NaN
+// dscale: 小数的位数
+const (
+	pgNumericPos = 0x0000
+	pgNumericNeg = 0x4000
+	pgNumericNaN = 0xC000
+)
+
+// pgNumeric 将十进制文本编码为 numeric 的二进制格式
+func pgNumeric(val string) []byte {
+	neg := strings.HasPrefix(val, "-")
+	ip, fp, _ := strings.Cut(strings.TrimLeft(val, "+-"), ".")
+
+	// 补齐为 4 的倍数
+	ip = strings.Repeat("0", (4-len(ip)%4)%4) + ip
+	dscale := len(fp)
+	fp += strings.Repeat("0", (4-len(fp)%4)%4)
+
+	weight := len(ip)/4 - 1
+	digits := make([]uint16, 0, (len(ip)+len(fp))/4)
+	for str := ip + fp; len(str) > 0; str = str[4:] {
+		d, _ := strconv.Atoi(str[:4])
+		digits = append(digits, uint16(d))
+	}
+
+	// 去除首尾的 0
+	for len(digits) > 0 && digits[0] == 0 {
+		digits = digits[1:]
+		weight--
+	}
+	for len(digits) > 0 && digits[len(digits)-1] == 0 {
+		digits = digits[:len(digits)-1]
+	}
+	sign := uint16(pgNumericPos)
+	if len(digits) == 0 {
+		weight = 0
+	} else if neg {
+		sign = pgNumericNeg
+	}
+
+	buf := binary.BigEndian.AppendUint16(nil, uint16(len(digits)))
+	buf = binary.BigEndian.AppendUint16(buf, uint16(int16(weight)))
+	buf = binary.BigEndian.AppendUint16(buf, sign)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(dscale))
+	for _, d := range digits {
+		buf = binary.BigEndian.AppendUint16(buf, d)
+	}
+	return buf
+}
+
+// pgParseNumeric 将 numeric 的二进制格式解码为十进制文本
+func pgParseNumeric(val []byte) string {
+	n := int(binary.BigEndian.Uint16(val))
+	weight := int(int16(binary.BigEndian.Uint16(val[2:])))
+	sign := binary.BigEndian.Uint16(val[4:])
+	dscale := int(binary.BigEndian.Uint16(val[6:]))
+	if sign == pgNumericNaN || len(val) < 8+2*n {
+		return "NaN"
+	}
+
+	digit := func(i int) int {
+		if i < 0 || i >= n {
+			return 0
+		}
+		return int(binary.BigEndian.Uint16(val[8+2*i:]))
+	}
+
+	var sb strings.Builder
+	if sign == pgNumericNeg {
+		sb.WriteByte('-')
+	}
+	if weight < 0 {
+		sb.WriteByte('0')
+	}
+	for i := 0; i <= weight; i++ {
+		if i == 0 {
+			sb.WriteString(strconv.Itoa(digit(i)))
+		} else {
+			sb.WriteString(fmt.Sprintf("%04d", digit(i)))
+		}
+	}
+	if dscale > 0 {
+		var frac strings.Builder
+		for i := weight + 1; frac.Len() < dscale; i++ {
+			frac.WriteString(fmt.Sprintf("%04d", digit(i)))
+		}
+		sb.WriteByte('.')
+		sb.WriteString(frac.String()[:dscale])
+	}
+	return sb.String()
+}
+
+func pgCommandTag(stmt sql.Statement, res *session.Result) string {
+	switch stmt.StmtType() {
+	case sql.Begin:
+		return "BEGIN"
+	case sql.Commit:
+		return "COMMIT"
+	case sql.Rollback:
+		return "ROLLBACK"
+	case sql.Create:
+		return "CREATE TABLE"
+	case sql.Alter:
+		return "ALTER TABLE"
+	case sql.Drop:
+		return "DROP TABLE"
+	case sql.Truncate:
+		return "TRUNCATE TABLE"
+	case sql.IndexCreate:
+		return "CREATE INDEX"
+	case sql.IndexDrop:
+		return "DROP INDEX"
+	case sql.Analyze:
+		return "ANALYZE"
+	case sql.Insert:
+		return fmt.Sprintf("INSERT 0 %d", res.Affected)
+	case sql.Update:
+		return fmt.Sprintf("UPDATE %d", res.Affected)
+	case sql.Delete:
+		return fmt.Sprintf("DELETE %d", res.Affected)
+	case sql.Select:
+		return fmt.Sprintf("SELECT %d", len(res.Rows))
+	}
+	return ""
+}
+
+func pgErrorCode(err error) string {
+	var dup *table.DuplicateKeyError
+	switch {
+	case errors.As(err, &dup):
+		return pgCodeUniqueViolation
+	case errors.Is(err, table.ErrNoSuchTable):
+		return pgCodeUndefinedTable
+	case errors.Is(err, ver.ErrCannotHandle):
+		return pgCodeSerializeFailure
+	case errors.Is(err, session.ErrTxAborted):
+		return pgCodeTxAborted
+	case errors.Is(err, table.ErrSchemaBusy):
+		return pgCodeObjectInUse
+	case errors.Is(err, session.ErrUnsupportedStmt):
+		return pgCodeFeatureNotSupported
+	case errors.Is(err, ErrResultTooLarge):
+		return pgCodeLimitExceeded
+	case errors.Is(err, sql.ErrParamMismatch), errors.Is(err, sql.ErrHasParams):
+		return pgCodeProtocolViolation
+	case strings.HasPrefix(err.Error(), "parse sql error"):
+		return pgCodeSyntaxError
+	}
+	return pgCodeInternalError
+}
+
+func cstring(data []byte) string {
+	i := bytes.IndexByte(data, 0)
+	if i < 0 {
+		return string(data)
+	}
+	return string(data[:i])
+}
+
+func readCString(r *bytes.Buffer) string {
+	str, err := r.ReadString(0)
+	if err != nil {
+		return str
+	}
+	return str[:len(str)-1]
+}
+
+func readInt32(r *bytes.Buffer) int32 {
+	buf := r.Next(4)
+	if len(buf) < 4 {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(buf))
+}
+
+func readInt16(r *bytes.Buffer) int16 {
+	buf := r.Next(2)
+	if len(buf) < 2 {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(buf))
+}
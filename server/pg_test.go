@@ -0,0 +1,444 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ggymm/db"
+	"github.com/ggymm/db/boot"
+	"github.com/ggymm/db/data"
+	"github.com/ggymm/db/pkg/sql"
+	"github.com/ggymm/db/session"
+	"github.com/ggymm/db/table"
+	"github.com/ggymm/db/tx"
+	"github.com/ggymm/db/ver"
+)
+
+// pgClient 用于测试的 PostgreSQL 客户端
+type pgClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+type pgMessage struct {
+	typ  byte
+	data []byte
+}
+
+func openPgServer(t *testing.T) (Server, func()) {
+	path := db.RunPath()
+	err := os.RemoveAll(path + "/temp/pg")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	opt := db.NewOption(path, "temp/pg")
+	opt.Memory = (1 << 20) * 64
+
+	tm := tx.NewManager(opt)
+	dm := data.NewManage(tm, opt)
+	tbm := table.NewManage(boot.New(opt), ver.NewManage(tm, dm), dm)
+
+	s, err := NewPgServer("127.0.0.1:0", tbm)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	go func() {
+		_ = s.Serve()
+	}()
+	return s, func() {
+		_ = s.Close()
+		dm.Close()
+		tm.Close()
+	}
+}
+
+func dialPg(t *testing.T, addr string) *pgClient {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	return startPg(t, conn)
+}
+
+// startPg 发送启动消息，等待服务端就绪
+func startPg(t *testing.T, conn net.Conn) *pgClient {
+	c := &pgClient{
+		t:    t,
+		conn: conn,
+		r:    bufio.NewReader(conn),
+	}
+
+	// SSLRequest
+	c.write(binary.BigEndian.AppendUint32([]byte{0, 0, 0, 8}, pgSSLRequest))
+	b, err := c.r.ReadByte()
+	if err != nil || b != 'N' {
+		t.Fatalf("ssl response %c %v", b, err)
+	}
+
+	// StartupMessage
+	body := binary.BigEndian.AppendUint32(nil, pgProtocolVersion)
+	body = append(body, "user\x00test\x00database\x00test\x00\x00"...)
+	c.write(append(binary.BigEndian.AppendUint32(nil, uint32(len(body)+4)), body...))
+
+	msgs := c.readUntilReady()
+	if msgs[0].typ != 'R' || binary.BigEndian.Uint32(msgs[0].data) != 0 {
+		t.Fatalf("authentication failed %v", msgs[0])
+	}
+	return c
+}
+
+func (c *pgClient) write(buf []byte) {
+	_, err := c.conn.Write(buf)
+	if err != nil {
+		c.t.Fatalf("write err %v", err)
+	}
+}
+
+func (c *pgClient) send(typ byte, data []byte) {
+	buf := []byte{typ}
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(data)+4))
+	c.write(append(buf, data...))
+}
+
+func (c *pgClient) read() pgMessage {
+	head := make([]byte, 5)
+	_, err := io.ReadFull(c.r, head)
+	if err != nil {
+		c.t.Fatalf("read err %v", err)
+	}
+	data := make([]byte, binary.BigEndian.Uint32(head[1:])-4)
+	_, err = io.ReadFull(c.r, data)
+	if err != nil {
+		c.t.Fatalf("read err %v", err)
+	}
+	return pgMessage{head[0], data}
+}
+
+func (c *pgClient) readUntilReady() []pgMessage {
+	msgs := make([]pgMessage, 0)
+	for {
+		msg := c.read()
+		msgs = append(msgs, msg)
+		if msg.typ == 'Z' {
+			return msgs
+		}
+	}
+}
+
+// query 执行简单查询，返回消息类型序列、数据行、命令标签和事务状态
+func (c *pgClient) query(sql string) (string, [][]string, string, byte) {
+	c.send('Q', append([]byte(sql), 0))
+
+	var (
+		types string
+		rows  [][]string
+		tag   string
+	)
+	msgs := c.readUntilReady()
+	for _, msg := range msgs {
+		types += string(msg.typ)
+		switch msg.typ {
+		case 'D':
+			rows = append(rows, parseDataRow(msg.data))
+		case 'C':
+			tag = cstring(msg.data)
+		case 'E':
+			tag = errorField(msg.data, 'C')
+		}
+	}
+	return types, rows, tag, msgs[len(msgs)-1].data[0]
+}
+
+func (c *pgClient) close() {
+	c.send('X', nil)
+	_ = c.conn.Close()
+}
+
+func parseDataRow(data []byte) []string {
+	n := int(binary.BigEndian.Uint16(data))
+	pos := 2
+	row := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		size := int(int32(binary.BigEndian.Uint32(data[pos:])))
+		pos += 4
+		if size < 0 {
+			row = append(row, "<null>")
+			continue
+		}
+		row = append(row, string(data[pos:pos+size]))
+		pos += size
+	}
+	return row
+}
+
+func errorField(data []byte, field byte) string {
+	for _, f := range bytes.Split(data, []byte{0}) {
+		if len(f) > 0 && f[0] == field {
+			return string(f[1:])
+		}
+	}
+	return ""
+}
+
+func TestPgServer_SimpleQuery(t *testing.T) {
+	s, closeFn := openPgServer(t)
+	defer closeFn()
+
+	c := dialPg(t, s.Addr().String())
+	defer c.close()
+
+	types, _, tag, status := c.query("create table user (id INT64, name VARCHAR, PRIMARY KEY (id))")
+	if types != "CZ" || tag != "CREATE TABLE" || status != 'I' {
+		t.Fatalf("create %s %s %c", types, tag, status)
+	}
+	_, _, tag, _ = c.query("insert into user (id, name) value (1, 'a');")
+	if tag != "INSERT 0 1" {
+		t.Fatalf("insert %s", tag)
+	}
+	_, _, _, _ = c.query("insert into user (id, name) value (2, 'b');")
+
+	types, rows, tag, _ := c.query("select * from user where id >= 1")
+	if types != "TDDCZ" || tag != "SELECT 2" {
+		t.Fatalf("select %s %s", types, tag)
+	}
+	if len(rows) != 2 || len(rows[0]) != 2 {
+		t.Fatalf("rows %v", rows)
+	}
+
+	types, _, tag, status = c.query("select * from nothing where id = 1")
+	if types != "EZ" || tag != pgCodeUndefinedTable || status != 'I' {
+		t.Fatalf("error %s %s %c", types, tag, status)
+	}
+
+	types, _, _, _ = c.query("")
+	if types != "IZ" {
+		t.Fatalf("empty %s", types)
+	}
+}
+
+func TestPgServer_Null(t *testing.T) {
+	s, closeFn := openPgServer(t)
+	defer closeFn()
+
+	c := dialPg(t, s.Addr().String())
+	defer c.close()
+
+	c.query("create table user (id INT64, name VARCHAR, PRIMARY KEY (id))")
+	c.query("insert into user (id, name) value (1, NULL)")
+	c.query("insert into user (id, name) value (2, '')")
+
+	_, rows, tag, _ := c.query("select * from user where name is null")
+	if tag != "SELECT 1" || rows[0][1] != "<null>" {
+		t.Fatalf("rows %v %s", rows, tag)
+	}
+	_, rows, _, _ = c.query("select * from user where name is not null")
+	if len(rows) != 1 || rows[0][1] != "" {
+		t.Fatalf("rows %v", rows)
+	}
+}
+
+func TestPgServer_Transaction(t *testing.T) {
+	s, closeFn := openPgServer(t)
+	defer closeFn()
+
+	c := dialPg(t, s.Addr().String())
+	defer c.close()
+
+	c.query("create table user (id INT64, name VARCHAR, PRIMARY KEY (id));")
+
+	_, _, tag, status := c.query("begin 1;")
+	if tag != "BEGIN" || status != 'T' {
+		t.Fatalf("begin %s %c", tag, status)
+	}
+	c.query("insert into user (id, name) value (1, 'a');")
+
+	// 执行失败后，事务进入失败状态
+	_, _, tag, status = c.query("select * from nothing where id = 1;")
+	if tag != pgCodeUndefinedTable || status != 'E' {
+		t.Fatalf("error %s %c", tag, status)
+	}
+	_, _, tag, status = c.query("select * from user where id = 1;")
+	if tag != pgCodeTxAborted || status != 'E' {
+		t.Fatalf("aborted %s %c", tag, status)
+	}
+
+	_, _, tag, status = c.query("rollback;")
+	if tag != "ROLLBACK" || status != 'I' {
+		t.Fatalf("rollback %s %c", tag, status)
+	}
+	_, rows, _, _ := c.query("select * from user where id = 1;")
+	if len(rows) != 0 {
+		t.Fatalf("rolled back rows visible %v", rows)
+	}
+}
+
+func TestPgServer_ExtendedQuery(t *testing.T) {
+	s, closeFn := openPgServer(t)
+	defer closeFn()
+
+	c := dialPg(t, s.Addr().String())
+	defer c.close()
+
+	c.query("create table user (id INT64, age INT32, name VARCHAR, PRIMARY KEY (id));")
+	c.query("insert into user (id, age, name) value (1, 18, 'a');")
+
+	// Parse、Describe（语句）、Sync
+	c.send('P', []byte("s1\x00select * from user where id = 1\x00\x00\x00"))
+	c.send('D', []byte("Ss1\x00"))
+	c.send('S', nil)
+	msgs := c.readUntilReady()
+	types := ""
+	for _, msg := range msgs {
+		types += string(msg.typ)
+	}
+	if types != "1tTZ" {
+		t.Fatalf("describe %s", types)
+	}
+
+	// Bind（二进制结果）、Describe（门户）、Execute、Sync
+	c.send('B', []byte("\x00s1\x00\x00\x00\x00\x00\x00\x01\x00\x01"))
+	c.send('D', []byte("P\x00"))
+	c.send('E', []byte("\x00\x00\x00\x00\x00"))
+	c.send('S', nil)
+	msgs = c.readUntilReady()
+	types = ""
+	var row []string
+	for _, msg := range msgs {
+		types += string(msg.typ)
+		if msg.typ == 'D' {
+			row = parseDataRow(msg.data)
+		}
+	}
+	if types != "2TDCZ" {
+		t.Fatalf("execute %s", types)
+	}
+	if binary.BigEndian.Uint64([]byte(row[0])) != 1 ||
+		binary.BigEndian.Uint32([]byte(row[1])) != 18 ||
+		row[2] != "a" {
+		t.Fatalf("row %v", row)
+	}
+
+	// 发生错误后，忽略 Sync 之前的消息
+	c.send('B', []byte("\x00nothing\x00\x00\x00\x00\x00\x00\x00"))
+	c.send('E', []byte("\x00\x00\x00\x00\x00"))
+	c.send('S', nil)
+	msgs = c.readUntilReady()
+	if len(msgs) != 2 || msgs[0].typ != 'E' {
+		t.Fatalf("error %v", msgs)
+	}
+}
+
+func TestPgServer_BindParams(t *testing.T) {
+	s, closeFn := openPgServer(t)
+	defer closeFn()
+
+	c := dialPg(t, s.Addr().String())
+	defer c.close()
+
+	c.query("create table user (id INT64, name VARCHAR, PRIMARY KEY (id));")
+
+	// Parse（第一个参数为 int8）、Describe（语句）
+	parse := []byte("ins\x00insert into user (id, name) value ($1, $2)\x00")
+	parse = binary.BigEndian.AppendUint16(parse, 1)
+	parse = binary.BigEndian.AppendUint32(parse, pgTypeInt8)
+	c.send('P', parse)
+	c.send('D', []byte("Sins\x00"))
+	c.send('S', nil)
+	msgs := c.readUntilReady()
+	if msgs[1].typ != 't' || binary.BigEndian.Uint16(msgs[1].data) != 2 ||
+		binary.BigEndian.Uint32(msgs[1].data[2:]) != pgTypeInt8 ||
+		binary.BigEndian.Uint32(msgs[1].data[6:]) != pgTypeText {
+		t.Fatalf("parameter description %v", msgs[1])
+	}
+
+	// Bind（第一个参数为二进制格式）、Execute
+	for i, name := range []string{"a", "b"} {
+		bind := []byte("\x00ins\x00")
+		bind = binary.BigEndian.AppendUint16(bind, 2)
+		bind = binary.BigEndian.AppendUint16(bind, 1)
+		bind = binary.BigEndian.AppendUint16(bind, 0)
+		bind = binary.BigEndian.AppendUint16(bind, 2)
+		bind = binary.BigEndian.AppendUint32(bind, 8)
+		bind = binary.BigEndian.AppendUint64(bind, uint64(i+1))
+		bind = binary.BigEndian.AppendUint32(bind, uint32(len(name)))
+		bind = append(bind, name...)
+		bind = binary.BigEndian.AppendUint16(bind, 0)
+		c.send('B', bind)
+		c.send('E', []byte("\x00\x00\x00\x00\x00"))
+	}
+	c.send('S', nil)
+	msgs = c.readUntilReady()
+	if len(msgs) != 5 || cstring(msgs[1].data) != "INSERT 0 1" {
+		t.Fatalf("execute %v", msgs)
+	}
+
+	_, rows, _, _ := c.query("select * from user where id >= 1;")
+	if len(rows) != 2 || rows[1][0] != "2" || rows[1][1] != "b" {
+		t.Fatalf("rows %v", rows)
+	}
+
+	// 简单查询不能包含参数
+	types, _, tag, _ := c.query("select * from user where id = $1;")
+	if types != "EZ" || tag != pgCodeProtocolViolation {
+		t.Fatalf("simple query with params %s %s", types, tag)
+	}
+}
+
+func TestPgNumeric(t *testing.T) {
+	for _, s := range []string{"0", "0.00", "1", "-1.5", "12345.6789", "0.0001", "-100000000.01", "99999999"} {
+		got := pgParseNumeric(pgNumeric(s))
+		if got != s {
+			t.Fatalf("numeric %s -> %s", s, got)
+		}
+	}
+}
+
+func TestPgServer_Error(t *testing.T) {
+	sess := &fakeSession{run: func(stmt sql.Statement) (*session.Result, error) {
+		switch stmt.StmtType() {
+		case sql.Select:
+			val := strings.Repeat("a", pgMaxMessageSize)
+			return &session.Result{Columns: []string{"v"}, Types: []string{"VARCHAR"}, Rows: [][]string{{val}}}, nil
+		case sql.Delete:
+			panic("boom")
+		}
+		return &session.Result{Affected: 1}, nil
+	}}
+	server, conn := net.Pipe()
+	go func() {
+		servePg(server, sess)
+		_ = server.Close()
+	}()
+	c := startPg(t, conn)
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	// 数据行过大时回复 ErrorResponse，连接仍然可以使用
+	types, _, tag, _ := c.query("select * from user where id = 1;")
+	if types != "TEZ" || tag != pgCodeLimitExceeded {
+		t.Fatalf("large result %s %s", types, tag)
+	}
+	types, _, tag, _ = c.query("insert into user (id) value (1);")
+	if types != "CZ" || tag != "INSERT 0 1" {
+		t.Fatalf("insert %s %s", types, tag)
+	}
+
+	// 出现 panic 时回复 ErrorResponse，然后关闭连接
+	c.send('Q', []byte("delete from user where id = 1;\x00"))
+	msg := c.read()
+	if msg.typ != 'E' || errorField(msg.data, 'C') != pgCodeInternalError || errorField(msg.data, 'M') != "internal error: boom" {
+		t.Fatalf("panic message %c %s", msg.typ, msg.data)
+	}
+	_, err := c.r.ReadByte()
+	if err == nil {
+		t.Fatalf("connection should be closed")
+	}
+}
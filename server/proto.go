@@ -1 +1,193 @@
 package server
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ggymm/db/pkg/bin"
+	"github.com/ggymm/db/session"
+)
+
+// 通信协议
+//
+// 客户端和服务端之间使用 TCP 连接，所有消息都使用相同的格式：
+// +----------------+----------------+----------------+
+// |      type      |      size      |      data      |
+// +----------------+----------------+----------------+
+// |     1 byte     |     4 bytes    |   size bytes   |
+// +----------------+----------------+----------------+
+//
+// type: 消息类型
+// size: data 的长度（小端序）
+//
+// 请求消息：
+// 'Q' 执行 sql 语句，data 为 sql 语句
+// 'X' 关闭连接，data 为空
+//
+// 响应消息（每个请求对应一个响应）：
+// 'R' 查询结果，data 为结果集
+// 'K' 执行成功，data 为影响的行数（8 bytes）
+// 'E' 执行失败，data 为错误信息
+//
+// 结果集的格式如下：
+// +----------------+----------------+----------------+----------------+
+// |    columns     | name and type  |      rows      |     values     |
+// +----------------+----------------+----------------+----------------+
+// |     2 bytes    |       ...      |     4 bytes    |       ...      |
+// +----------------+----------------+----------------+----------------+
+//
+// columns: 字段数量
+// name and type: 依次为每个字段的名称和类型
+// rows: 数据行数
+// values: 依次为每行数据的每个字段值
+//
+// 结果集中的字符串使用 4 bytes 的长度（小端序）加上原始字节表示
+// 字段值为 NULL 时，长度为 0xFFFFFFFF，没有原始字节
+
+const (
+	MsgQuery  byte = 'Q'
+	MsgClose  byte = 'X'
+	MsgResult byte = 'R'
+	MsgOK     byte = 'K'
+	MsgError  byte = 'E'
+)
+
+const (
+	headerLen = 1 + 4
+
+	maxPacketSize = 1 << 26 // 单个消息最大 64MB
+)
+
+var (
+	ErrPacketTooLarge = errors.New("packet too large")
+	ErrPacketInvalid  = errors.New("packet invalid")
+)
+
+func readPacket(r io.Reader) (byte, []byte, error) {
+	header := make([]byte, headerLen)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	size := bin.Uint32(header[1:])
+	if size > maxPacketSize {
+		return 0, nil, ErrPacketTooLarge
+	}
+
+	data := make([]byte, size)
+	_, err = io.ReadFull(r, data)
+	if err != nil {
+		return 0, nil, err
+	}
+	return header[0], data, nil
+}
+
+func writePacket(w io.Writer, typ byte, data []byte) error {
+	if len(data) > maxPacketSize {
+		return ErrPacketTooLarge
+	}
+
+	buf := make([]byte, headerLen+len(data))
+	buf[0] = typ
+	bin.PutUint32(buf[1:], uint32(len(data)))
+	copy(buf[headerLen:], data)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+const nullSize = 0xffffffff
+
+func appendString(buf []byte, str string) []byte {
+	buf = append(buf, bin.Uint32Raw(uint32(len(str)))...)
+	return append(buf, str...)
+}
+
+func parseString(data []byte, pos int) (string, int, error) {
+	if pos+4 > len(data) {
+		return "", 0, ErrPacketInvalid
+	}
+	size := int(bin.Uint32(data[pos:]))
+	pos += 4
+	if pos+size > len(data) {
+		return "", 0, ErrPacketInvalid
+	}
+	return string(data[pos : pos+size]), pos + size, nil
+}
+
+// wrapResult 编码结果集
+func wrapResult(res *session.Result) []byte {
+	buf := bin.Uint16Raw(uint16(len(res.Columns)))
+	for i, col := range res.Columns {
+		buf = appendString(buf, col)
+		buf = appendString(buf, res.Types[i])
+	}
+
+	buf = append(buf, bin.Uint32Raw(uint32(len(res.Rows)))...)
+	for i, row := range res.Rows {
+		for j, val := range row {
+			if res.IsNull(i, j) {
+				buf = append(buf, bin.Uint32Raw(nullSize)...)
+				continue
+			}
+			buf = appendString(buf, val)
+		}
+	}
+	return buf
+}
+
+// parseResult 解码结果集
+func parseResult(data []byte) (*session.Result, error) {
+	if len(data) < 2 {
+		return nil, ErrPacketInvalid
+	}
+
+	var (
+		err error
+		pos = 2
+		num = int(bin.Uint16(data))
+		res = &session.Result{
+			Columns: make([]string, num),
+			Types:   make([]string, num),
+		}
+	)
+	for i := 0; i < num; i++ {
+		res.Columns[i], pos, err = parseString(data, pos)
+		if err != nil {
+			return nil, err
+		}
+		res.Types[i], pos, err = parseString(data, pos)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if pos+4 > len(data) {
+		return nil, ErrPacketInvalid
+	}
+	rows := int(bin.Uint32(data[pos:]))
+	pos += 4
+
+	res.Rows = make([][]string, 0, min(rows, len(data)))
+	res.Nulls = make([][]bool, 0, min(rows, len(data)))
+	for i := 0; i < rows; i++ {
+		row := make([]string, num)
+		nulls := make([]bool, num)
+		for j := 0; j < num; j++ {
+			if pos+4 <= len(data) && bin.Uint32(data[pos:]) == nullSize {
+				row[j] = "NULL"
+				nulls[j] = true
+				pos += 4
+				continue
+			}
+			row[j], pos, err = parseString(data, pos)
+			if err != nil {
+				return nil, err
+			}
+		}
+		res.Rows = append(res.Rows, row)
+		res.Nulls = append(res.Nulls, nulls)
+	}
+	return res, nil
+}
@@ -1 +1,199 @@
 package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/ggymm/db/pkg/bin"
+	"github.com/ggymm/db/session"
+	"github.com/ggymm/db/table"
+)
+
+var (
+	ErrServerClosed   = errors.New("server closed")
+	ErrResultTooLarge = errors.New("result too large")
+)
+
+// panicError 处理消息时出现的 panic
+//
+// 回复错误信息之后关闭连接（会话的状态可能已经损坏，关闭会话时回滚未提交的事务）
+type panicError struct {
+	val any
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("internal error: %v", e.val)
+}
+
+// recoverPanic 执行 fn，将出现的 panic 转换为 panicError
+func recoverPanic(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &panicError{val: r}
+		}
+	}()
+	return fn()
+}
+
+type Server interface {
+	Addr() net.Addr
+
+	Serve() error
+	Close() error
+}
+
+type server struct {
+	sync.Mutex
+	wg sync.WaitGroup
+
+	tbm      table.Manage
+	listener net.Listener
+
+	// 处理连接上的消息，不同的协议使用不同的实现
+	serve func(conn net.Conn, sess session.Session)
+
+	conns  map[net.Conn]bool
+	closed bool
+}
+
+// NewServer 创建使用自定义协议（见 proto.go）的服务
+func NewServer(addr string, tbm table.Manage) (Server, error) {
+	return newServer(addr, tbm, serveConn)
+}
+
+// NewPgServer 创建使用 PostgreSQL 协议（见 pg.go）的服务
+func NewPgServer(addr string, tbm table.Manage) (Server, error) {
+	return newServer(addr, tbm, servePg)
+}
+
+func newServer(addr string, tbm table.Manage, serve func(net.Conn, session.Session)) (Server, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &server{
+		tbm:      tbm,
+		listener: l,
+
+		serve: serve,
+
+		conns: make(map[net.Conn]bool),
+	}, nil
+}
+
+func (s *server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve 接收客户端连接，每个连接使用一个独立的协程处理
+// 服务关闭后返回 ErrServerClosed
+func (s *server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			s.Lock()
+			closed := s.closed
+			s.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		s.Lock()
+		if s.closed {
+			s.Unlock()
+			_ = conn.Close()
+			return ErrServerClosed
+		}
+		s.conns[conn] = true
+		s.wg.Add(1)
+		s.Unlock()
+
+		go s.handle(conn)
+	}
+}
+
+// Close 停止接收连接，关闭全部连接并等待处理结束
+// 未提交的事务会被回滚
+func (s *server) Close() error {
+	s.Lock()
+	if s.closed {
+		s.Unlock()
+		return nil
+	}
+	s.closed = true
+	err := s.listener.Close()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+func (s *server) handle(conn net.Conn) {
+	sess := session.New(s.tbm)
+	defer func() {
+		sess.Close()
+
+		s.Lock()
+		delete(s.conns, conn)
+		s.Unlock()
+		_ = conn.Close()
+		s.wg.Done()
+	}()
+
+	s.serve(conn, sess)
+}
+
+// serveConn 处理自定义协议的连接
+func serveConn(conn net.Conn, sess session.Session) {
+	for {
+		typ, data, err := readPacket(conn)
+		if err != nil {
+			if errors.Is(err, ErrPacketTooLarge) {
+				_ = writePacket(conn, MsgError, []byte(err.Error()))
+			}
+			return
+		}
+
+		switch typ {
+		case MsgQuery:
+			err = recoverPanic(func() error {
+				return reply(conn, sess, string(data))
+			})
+		case MsgClose:
+			return
+		default:
+			err = writePacket(conn, MsgError, []byte("unknown message type"))
+		}
+		if err != nil {
+			var pe *panicError
+			if errors.As(err, &pe) {
+				_ = writePacket(conn, MsgError, []byte(pe.Error()))
+			}
+			return
+		}
+	}
+}
+
+func reply(conn net.Conn, sess session.Session, in string) error {
+	res, err := sess.Execute(in)
+	if err != nil {
+		return writePacket(conn, MsgError, []byte(err.Error()))
+	}
+	if res.Columns != nil {
+		// 结果集超过消息的最大长度时回复错误信息（不关闭连接）
+		data := wrapResult(res)
+		if len(data) > maxPacketSize {
+			return writePacket(conn, MsgError, []byte(ErrResultTooLarge.Error()))
+		}
+		return writePacket(conn, MsgResult, data)
+	}
+	return writePacket(conn, MsgOK, bin.Uint64Raw(uint64(res.Affected)))
+}
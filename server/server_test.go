@@ -0,0 +1,247 @@
+package server
+
+import (
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ggymm/db"
+	"github.com/ggymm/db/boot"
+	"github.com/ggymm/db/data"
+	"github.com/ggymm/db/pkg/sql"
+	"github.com/ggymm/db/session"
+	"github.com/ggymm/db/table"
+	"github.com/ggymm/db/tx"
+	"github.com/ggymm/db/ver"
+)
+
+func openServer(t *testing.T) (Server, func()) {
+	path := db.RunPath()
+	err := os.RemoveAll(path + "/temp/server")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	opt := db.NewOption(path, "temp/server")
+	opt.Memory = (1 << 20) * 64
+
+	tm := tx.NewManager(opt)
+	dm := data.NewManage(tm, opt)
+	tbm := table.NewManage(boot.New(opt), ver.NewManage(tm, dm), dm)
+
+	s, err := NewServer("127.0.0.1:0", tbm)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	go func() {
+		_ = s.Serve()
+	}()
+	return s, func() {
+		_ = s.Close()
+		dm.Close()
+		tm.Close()
+	}
+}
+
+func mustQuery(t *testing.T, c Client, sql string) *session.Result {
+	res, err := c.Query(sql)
+	if err != nil {
+		t.Fatalf("query %s err %v", sql, err)
+	}
+	return res
+}
+
+func TestServer_Query(t *testing.T) {
+	s, closeFn := openServer(t)
+	defer closeFn()
+
+	c, err := Dial(s.Addr().String())
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	mustQuery(t, c, "create table user (id INT64, name VARCHAR, PRIMARY KEY (id));")
+	for _, sql := range []string{
+		"insert into user (id, name) value (1, 'a');",
+		"insert into user (id, name) value (2, 'b');",
+		"insert into user (id, name) value (3, 'c');",
+	} {
+		res := mustQuery(t, c, sql)
+		if res.Affected != 1 {
+			t.Fatalf("affected %d", res.Affected)
+		}
+	}
+
+	res := mustQuery(t, c, "update user set name = 'bb' where id = 2;")
+	if res.Affected != 1 {
+		t.Fatalf("update affected %d", res.Affected)
+	}
+	res = mustQuery(t, c, "delete from user where id > 2;")
+	if res.Affected != 1 {
+		t.Fatalf("delete affected %d", res.Affected)
+	}
+
+	res = mustQuery(t, c, "select * from user where id >= 1;")
+	if len(res.Columns) != 2 || res.Columns[0] != "id" || res.Types[1] != "VARCHAR" {
+		t.Fatalf("columns %v %v", res.Columns, res.Types)
+	}
+	if len(res.Rows) != 2 {
+		t.Fatalf("rows %v", res.Rows)
+	}
+	for _, row := range res.Rows {
+		if row[0] == "2" && row[1] != "bb" {
+			t.Fatalf("row %v", row)
+		}
+	}
+
+	// 执行失败
+	_, err = c.Query("select * from nothing where id = 1;")
+	if err == nil {
+		t.Fatalf("query should fail")
+	}
+}
+
+func TestServer_Null(t *testing.T) {
+	s, closeFn := openServer(t)
+	defer closeFn()
+
+	c, err := Dial(s.Addr().String())
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	mustQuery(t, c, "create table user (id INT64, name VARCHAR, PRIMARY KEY (id));")
+	mustQuery(t, c, "insert into user (id, name) value (1, NULL);")
+	mustQuery(t, c, "insert into user (id, name) value (2, 'NULL');")
+
+	res := mustQuery(t, c, "select * from user where id >= 1;")
+	if len(res.Rows) != 2 || !res.IsNull(0, 1) || res.IsNull(1, 1) || res.Rows[1][1] != "NULL" {
+		t.Fatalf("rows %v nulls %v", res.Rows, res.Nulls)
+	}
+}
+
+func TestServer_Transaction(t *testing.T) {
+	s, closeFn := openServer(t)
+	defer closeFn()
+
+	c1, err := Dial(s.Addr().String())
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	c2, err := Dial(s.Addr().String())
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	defer func() {
+		_ = c2.Close()
+	}()
+
+	mustQuery(t, c1, "create table user (id INT64, name VARCHAR, PRIMARY KEY (id));")
+
+	// 未提交的数据对其他会话不可见
+	mustQuery(t, c1, "begin 0;")
+	mustQuery(t, c1, "insert into user (id, name) value (1, 'a');")
+	res := mustQuery(t, c2, "select * from user where id = 1;")
+	if len(res.Rows) != 0 {
+		t.Fatalf("uncommitted rows visible %v", res.Rows)
+	}
+	mustQuery(t, c1, "commit;")
+	res = mustQuery(t, c2, "select * from user where id = 1;")
+	if len(res.Rows) != 1 {
+		t.Fatalf("committed rows invisible")
+	}
+
+	// 回滚
+	mustQuery(t, c1, "begin 1;")
+	mustQuery(t, c1, "insert into user (id, name) value (2, 'b');")
+	mustQuery(t, c1, "rollback;")
+	res = mustQuery(t, c2, "select * from user where id = 2;")
+	if len(res.Rows) != 0 {
+		t.Fatalf("rolled back rows visible %v", res.Rows)
+	}
+
+	// 断开连接时回滚未提交的事务
+	mustQuery(t, c1, "begin 1;")
+	mustQuery(t, c1, "insert into user (id, name) value (3, 'c');")
+	_ = c1.Close()
+	res = mustQuery(t, c2, "select * from user where id = 3;")
+	if len(res.Rows) != 0 {
+		t.Fatalf("rows of closed session visible %v", res.Rows)
+	}
+
+	_, err = c2.Query("commit;")
+	if err == nil {
+		t.Fatalf("commit without begin should fail")
+	}
+}
+
+// fakeSession 使用 execute 和 run 处理 sql 语句的会话
+type fakeSession struct {
+	session.Session
+	execute func(in string) (*session.Result, error)
+	run     func(stmt sql.Statement) (*session.Result, error)
+}
+
+func (s *fakeSession) Execute(in string, _ ...any) (*session.Result, error) {
+	return s.execute(in)
+}
+
+func (s *fakeSession) Prepare(in string) (*sql.Prepared, error) {
+	return sql.Prepare(in)
+}
+
+func (s *fakeSession) Run(stmt sql.Statement) (*session.Result, error) {
+	return s.run(stmt)
+}
+
+func (s *fakeSession) Status() byte {
+	return 'I'
+}
+
+func TestServer_Error(t *testing.T) {
+	sess := &fakeSession{execute: func(in string) (*session.Result, error) {
+		switch in {
+		case "large":
+			val := strings.Repeat("a", maxPacketSize)
+			return &session.Result{Columns: []string{"v"}, Types: []string{"VARCHAR"}, Rows: [][]string{{val}}}, nil
+		case "panic":
+			panic("boom")
+		}
+		return &session.Result{Affected: 1}, nil
+	}}
+	server, conn := net.Pipe()
+	go func() {
+		serveConn(server, sess)
+		_ = server.Close()
+	}()
+	c := &client{conn: conn}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	// 结果集过大时回复错误信息，连接仍然可以使用
+	_, err := c.Query("large")
+	if err == nil || err.Error() != ErrResultTooLarge.Error() {
+		t.Fatalf("large result err %v", err)
+	}
+	res := mustQuery(t, c, "ok")
+	if res.Affected != 1 {
+		t.Fatalf("affected %d", res.Affected)
+	}
+
+	// 出现 panic 时回复错误信息，然后关闭连接
+	_, err = c.Query("panic")
+	if err == nil || err.Error() != "internal error: boom" {
+		t.Fatalf("panic err %v", err)
+	}
+	_, err = c.Query("ok")
+	if err == nil {
+		t.Fatalf("connection should be closed")
+	}
+}
@@ -0,0 +1,334 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ggymm/db/pkg/sql"
+	"github.com/ggymm/db/table"
+	"github.com/ggymm/db/ver"
+)
+
+var (
+	ErrInTransaction    = errors.New("already in transaction")
+	ErrNotInTransaction = errors.New("not in transaction")
+	ErrUnsupportedStmt  = errors.New("unsupported statement")
+	ErrInvalidLevel     = errors.New("invalid isolation level")
+	ErrTxAborted        = errors.New("transaction aborted, ROLLBACK required")
+	ErrTxRolledBack     = errors.New("transaction rolled back due to previous error")
+)
+
+// 事务状态
+const (
+	StatusIdle   byte = 'I' // 不在事务中
+	StatusInTx   byte = 'T' // 在事务中
+	StatusFailed byte = 'E' // 在失败的事务中
+)
+
+// Result 执行结果
+//
+// 查询语句：Columns 和 Types 为字段信息，Rows 为数据（文本格式）
+// 其他语句：Columns 为 nil，Affected 为影响的行数
+//
+// Nulls 与 Rows 一一对应，为 true 时字段值为 NULL（Rows 中为 "NULL"）
+type Result struct {
+	Columns []string
+	Types   []string
+	Rows    [][]string
+	Nulls   [][]bool
+
+	Affected int
+}
+
+// IsNull 第 row 行第 col 列的字段值是否为 NULL
+func (r *Result) IsNull(row, col int) bool {
+	return row < len(r.Nulls) && col < len(r.Nulls[row]) && r.Nulls[row][col]
+}
+
+// Session 客户端会话
+//
+// 会话中保存当前的事务状态
+// 没有显式开启事务时，每条语句都在一个独立的事务中执行（自动提交，可重复读）
+//
+// 显式开启事务：
+// BEGIN;                  可重复读
+// BEGIN READ COMMITTED;   读已提交（ver 中的级别 0）
+// BEGIN REPEATABLE READ;  可重复读（ver 中的级别 1）
+//
+// 显式事务中的语句执行失败后，事务进入失败状态
+// 此时只能执行 COMMIT 或者 ROLLBACK 结束事务（两者都会回滚事务）
+type Session interface {
+	Execute(in string, args ...any) (*Result, error)
+
+	Prepare(in string) (*sql.Prepared, error)
+	Run(stmt sql.Statement) (*Result, error)
+	Describe(stmt sql.Statement) (*Result, error)
+
+	Status() byte
+	Close()
+}
+
+type session struct {
+	tbm table.Manage
+
+	tid    uint64
+	inTx   bool
+	failed bool
+}
+
+func New(tbm table.Manage) Session {
+	return &session{
+		tbm: tbm,
+	}
+}
+
+// Execute 解析 sql 语句，绑定参数并执行
+func (s *session) Execute(in string, args ...any) (*Result, error) {
+	p, err := s.Prepare(in)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.Bind(args...)
+	if err != nil {
+		s.fail()
+		return nil, err
+	}
+	return s.Run(stmt)
+}
+
+// Prepare 解析 sql 语句（可以包含参数占位符）
+func (s *session) Prepare(in string) (*sql.Prepared, error) {
+	p, err := sql.Prepare(in)
+	if err != nil {
+		s.fail()
+		return nil, err
+	}
+	return p, nil
+}
+
+// fail 显式事务中发生错误时，事务进入失败状态
+func (s *session) fail() {
+	if s.inTx {
+		s.failed = true
+	}
+}
+
+// Status 事务状态
+func (s *session) Status() byte {
+	if !s.inTx {
+		return StatusIdle
+	}
+	if s.failed {
+		return StatusFailed
+	}
+	return StatusInTx
+}
+
+// Run 执行已经解析的 sql 语句
+func (s *session) Run(stmt sql.Statement) (*Result, error) {
+	switch stmt.StmtType() {
+	case sql.Begin:
+		return s.begin(stmt.(*sql.BeginStmt))
+	case sql.Commit:
+		return s.commit()
+	case sql.Rollback:
+		return s.rollback()
+	}
+
+	// 显式事务
+	if s.inTx {
+		if s.failed {
+			return nil, ErrTxAborted
+		}
+		res, err := s.execute(s.tid, stmt)
+		if err != nil {
+			s.failed = true
+			if errors.Is(err, ver.ErrCannotHandle) {
+				return nil, fmt.Errorf("%w, %w", err, ErrTxAborted)
+			}
+			return nil, err
+		}
+		return res, nil
+	}
+
+	// 自动提交（可重复读）
+	tid := s.tbm.Begin(1)
+	res, err := s.execute(tid, stmt)
+	if err != nil {
+		s.tbm.Rollback(tid)
+		return nil, err
+	}
+	err = s.tbm.Commit(tid)
+	if err != nil {
+		s.tbm.Rollback(tid)
+		return nil, err
+	}
+	return res, nil
+}
+
+// Describe 查询结果的字段信息，不是查询语句时返回空
+func (s *session) Describe(stmt sql.Statement) (*Result, error) {
+	if stmt == nil || stmt.StmtType() != sql.Select {
+		return nil, nil
+	}
+
+	cols, err := s.tbm.Describe(stmt.(*sql.SelectStmt))
+	if err != nil {
+		return nil, err
+	}
+	return newResult(cols), nil
+}
+
+// newResult 根据字段信息创建查询结果
+func newResult(cols []*table.Column) *Result {
+	res := &Result{
+		Columns: make([]string, 0, len(cols)),
+		Types:   make([]string, 0, len(cols)),
+	}
+	for _, col := range cols {
+		res.Columns = append(res.Columns, col.Name)
+		res.Types = append(res.Types, col.Type)
+	}
+	return res
+}
+
+// Close 关闭会话，回滚未提交的事务
+func (s *session) Close() {
+	if s.inTx {
+		s.tbm.Rollback(s.tid)
+		s.reset()
+	}
+}
+
+func (s *session) reset() {
+	s.tid = 0
+	s.inTx = false
+	s.failed = false
+}
+
+func (s *session) execute(tid uint64, stmt sql.Statement) (*Result, error) {
+	var (
+		n   int
+		err error
+	)
+	switch stmt.StmtType() {
+	case sql.Create:
+		err = s.tbm.Create(tid, stmt.(*sql.CreateStmt))
+	case sql.Alter:
+		err = s.tbm.Alter(tid, stmt.(*sql.AlterStmt))
+	case sql.Drop:
+		err = s.tbm.Drop(tid, stmt.(*sql.DropStmt))
+	case sql.Truncate:
+		err = s.tbm.Truncate(tid, stmt.(*sql.TruncateStmt))
+	case sql.IndexCreate:
+		err = s.tbm.CreateIndex(tid, stmt.(*sql.CreateIndexStmt))
+	case sql.IndexDrop:
+		err = s.tbm.DropIndex(tid, stmt.(*sql.DropIndexStmt))
+	case sql.Analyze:
+		err = s.tbm.Analyze(tid, stmt.(*sql.AnalyzeStmt))
+	case sql.Insert:
+		n, err = s.tbm.Insert(tid, stmt.(*sql.InsertStmt))
+	case sql.Update:
+		n, err = s.tbm.Update(tid, stmt.(*sql.UpdateStmt))
+	case sql.Delete:
+		n, err = s.tbm.Delete(tid, stmt.(*sql.DeleteStmt))
+	case sql.Select:
+		return s.query(tid, stmt.(*sql.SelectStmt))
+	default:
+		err = ErrUnsupportedStmt
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Affected: n}, nil
+}
+
+func (s *session) query(tid uint64, stmt *sql.SelectStmt) (*Result, error) {
+	// 字段信息和数据使用相同的表结构（其他事务正在修改表结构时返回错误）
+	cols, entries, err := s.tbm.Query(tid, stmt)
+	if err != nil {
+		return nil, err
+	}
+	res := newResult(cols)
+
+	types := make([]sql.ColumnType, 0, len(res.Types))
+	for _, typ := range res.Types {
+		ct, err := sql.ParseColumnType(typ)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, ct)
+	}
+
+	res.Rows = make([][]string, 0, len(entries))
+	res.Nulls = make([][]bool, 0, len(entries))
+	for _, ent := range entries {
+		row := make([]string, 0, len(res.Columns))
+		nulls := make([]bool, 0, len(res.Columns))
+		for i, col := range res.Columns {
+			row = append(row, sql.FormatText(types[i], ent[col]))
+			nulls = append(nulls, ent[col] == nil)
+		}
+		res.Rows = append(res.Rows, row)
+		res.Nulls = append(res.Nulls, nulls)
+	}
+	return res, nil
+}
+
+// parseLevel 解析事务的隔离级别
+// 0：读已提交，1：可重复读
+func parseLevel(level string) (int, error) {
+	switch strings.ToUpper(level) {
+	case "0", "READ COMMITTED":
+		return 0, nil
+	case "", "1", "REPEATABLE READ":
+		return 1, nil
+	}
+	return 0, fmt.Errorf("%w %s", ErrInvalidLevel, level)
+}
+
+func (s *session) begin(stmt *sql.BeginStmt) (*Result, error) {
+	if s.inTx {
+		return nil, ErrInTransaction
+	}
+
+	level, err := parseLevel(stmt.Level)
+	if err != nil {
+		return nil, err
+	}
+	s.tid = s.tbm.Begin(level)
+	s.inTx = true
+	return &Result{}, nil
+}
+
+func (s *session) commit() (*Result, error) {
+	if !s.inTx {
+		return nil, ErrNotInTransaction
+	}
+
+	tid := s.tid
+	failed := s.failed
+	s.reset()
+
+	if failed {
+		s.tbm.Rollback(tid)
+		return nil, ErrTxRolledBack
+	}
+	err := s.tbm.Commit(tid)
+	if err != nil {
+		s.tbm.Rollback(tid)
+		return nil, err
+	}
+	return &Result{}, nil
+}
+
+func (s *session) rollback() (*Result, error) {
+	if !s.inTx {
+		return nil, ErrNotInTransaction
+	}
+
+	s.tbm.Rollback(s.tid)
+	s.reset()
+	return &Result{}, nil
+}
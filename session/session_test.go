@@ -0,0 +1,1078 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ggymm/db"
+	"github.com/ggymm/db/boot"
+	"github.com/ggymm/db/data"
+	"github.com/ggymm/db/pkg/sql"
+	"github.com/ggymm/db/table"
+	"github.com/ggymm/db/tx"
+	"github.com/ggymm/db/ver"
+)
+
+func openTbm(t *testing.T) (table.Manage, func()) {
+	err := os.RemoveAll(db.RunPath() + "/temp/session")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	return reopenTbm()
+}
+
+// reopenTbm 打开已经存在的数据库
+func reopenTbm() (table.Manage, func()) {
+	opt := db.NewOption(db.RunPath(), "temp/session")
+	opt.Memory = (1 << 20) * 64
+
+	tm := tx.NewManager(opt)
+	dm := data.NewManage(tm, opt)
+	tbm := table.NewManage(boot.New(opt), ver.NewManage(tm, dm), dm)
+	return tbm, func() {
+		dm.Close()
+		tm.Close()
+	}
+}
+
+func mustExec(t *testing.T, s Session, sql string) *Result {
+	res, err := s.Execute(sql)
+	if err != nil {
+		t.Fatalf("exec %s err %v", sql, err)
+	}
+	return res
+}
+
+func TestSession_Transaction(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	defer closeFn()
+
+	s1 := New(tbm)
+	s2 := New(tbm)
+	defer s1.Close()
+	defer s2.Close()
+
+	mustExec(t, s1, "CREATE TABLE user (id INT64, name VARCHAR, PRIMARY KEY (id));")
+	mustExec(t, s1, "INSERT INTO user (id, name) VALUE (1, 'a');")
+
+	// 读已提交：可以读取其他事务提交的数据
+	mustExec(t, s1, "BEGIN READ COMMITTED;")
+	if s1.Status() != StatusInTx {
+		t.Fatalf("status %c", s1.Status())
+	}
+	mustExec(t, s2, "INSERT INTO user (id, name) VALUE (2, 'b');")
+	res := mustExec(t, s1, "SELECT * FROM user WHERE id = 2;")
+	if len(res.Rows) != 1 {
+		t.Fatalf("committed rows invisible")
+	}
+	mustExec(t, s1, "COMMIT;")
+	if s1.Status() != StatusIdle {
+		t.Fatalf("status %c", s1.Status())
+	}
+
+	// 可重复读：不能读取事务开始后其他事务提交的数据
+	mustExec(t, s1, "BEGIN REPEATABLE READ;")
+	mustExec(t, s1, "SELECT * FROM user WHERE id = 1;")
+	mustExec(t, s2, "INSERT INTO user (id, name) VALUE (3, 'c');")
+	res = mustExec(t, s1, "SELECT * FROM user WHERE id = 3;")
+	if len(res.Rows) != 0 {
+		t.Fatalf("rows committed after begin visible")
+	}
+	mustExec(t, s1, "ROLLBACK;")
+
+	_, err := s1.Execute("BEGIN SERIALIZABLE;")
+	if !errors.Is(err, ErrInvalidLevel) {
+		t.Fatalf("err %v", err)
+	}
+	_, err = s1.Execute("COMMIT;")
+	if !errors.Is(err, ErrNotInTransaction) {
+		t.Fatalf("err %v", err)
+	}
+}
+
+func TestSession_Aborted(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	defer closeFn()
+
+	s1 := New(tbm)
+	s2 := New(tbm)
+	defer s1.Close()
+	defer s2.Close()
+
+	mustExec(t, s1, "CREATE TABLE user (id INT64, name VARCHAR, PRIMARY KEY (id));")
+	mustExec(t, s1, "INSERT INTO user (id, name) VALUE (1, 'a');")
+
+	// 并发更新同一行数据，后提交的事务被回滚
+	mustExec(t, s1, "BEGIN;")
+	mustExec(t, s2, "BEGIN;")
+	mustExec(t, s1, "UPDATE user SET name = 'b' WHERE id = 1;")
+	mustExec(t, s1, "COMMIT;")
+
+	_, err := s2.Execute("UPDATE user SET name = 'c' WHERE id = 1;")
+	if !errors.Is(err, ver.ErrCannotHandle) || !errors.Is(err, ErrTxAborted) {
+		t.Fatalf("err %v", err)
+	}
+	if s2.Status() != StatusFailed {
+		t.Fatalf("status %c", s2.Status())
+	}
+
+	// 失败的事务中，只能执行 ROLLBACK
+	_, err = s2.Execute("SELECT * FROM user WHERE id = 1;")
+	if !errors.Is(err, ErrTxAborted) {
+		t.Fatalf("err %v", err)
+	}
+	mustExec(t, s2, "ROLLBACK;")
+	if s2.Status() != StatusIdle {
+		t.Fatalf("status %c", s2.Status())
+	}
+
+	res := mustExec(t, s2, "SELECT * FROM user WHERE id = 1;")
+	if len(res.Rows) != 1 || res.Rows[0][1] != "b" {
+		t.Fatalf("rows %v", res.Rows)
+	}
+}
+
+func TestSession_Types(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	defer closeFn()
+
+	s := New(tbm)
+	defer s.Close()
+
+	mustExec(t, s, "CREATE TABLE item (id INT, ok BOOL, score DOUBLE, price DECIMAL(10,2), created TIMESTAMP, day DATE, PRIMARY KEY (id));")
+	for i := -2; i <= 2; i++ {
+		mustExec(t, s, fmt.Sprintf("INSERT INTO item (id, ok, score, price, created, day) VALUE (%d, %t, %d.5, '%d.005', '2024-01-0%d 10:00:00', '2024-01-0%d');", i, i > 0, i, i, i+3, i+3))
+	}
+
+	// 负数的索引范围
+	res := mustExec(t, s, "SELECT * FROM item WHERE id < 0;")
+	if len(res.Rows) != 2 {
+		t.Fatalf("rows %v", res.Rows)
+	}
+	if res.Types[3] != "DECIMAL(10,2)" {
+		t.Fatalf("types %v", res.Types)
+	}
+	if res.Rows[0][0] != "-2" || res.Rows[0][3] != "-2.01" || res.Rows[0][5] != "2024-01-01" {
+		t.Fatalf("row %v", res.Rows[0])
+	}
+
+	res = mustExec(t, s, "SELECT * FROM item WHERE price > 0.5 AND ok = true;")
+	if len(res.Rows) != 2 || res.Rows[0][2] != "1.5" || res.Rows[0][4] != "2024-01-04 10:00:00" {
+		t.Fatalf("rows %v", res.Rows)
+	}
+	res = mustExec(t, s, "SELECT * FROM item WHERE created >= '2024-01-04';")
+	if len(res.Rows) != 2 {
+		t.Fatalf("rows %v", res.Rows)
+	}
+
+	_, err := s.Execute("INSERT INTO item (id, ok) VALUE (10, 'yes');")
+	if !errors.Is(err, sql.ErrInvalidValue) {
+		t.Fatalf("err %v", err)
+	}
+	_, err = s.Execute("INSERT INTO item (id, price) VALUE (10, 123456789.1);")
+	if !errors.Is(err, sql.ErrInvalidValue) {
+		t.Fatalf("err %v", err)
+	}
+}
+
+func TestSession_Null(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	defer closeFn()
+
+	s := New(tbm)
+	defer s.Close()
+
+	mustExec(t, s, "CREATE TABLE user (id INT64, age INT32, name VARCHAR NOT NULL, PRIMARY KEY (id), INDEX age_idx (age));")
+	mustExec(t, s, "INSERT INTO user (id, age, name) VALUE (1, 18, 'a');")
+	mustExec(t, s, "INSERT INTO user (id, age, name) VALUE (2, NULL, '');")
+	mustExec(t, s, "INSERT INTO user (id, name) VALUE (3, 'c');")
+
+	_, err := s.Execute("INSERT INTO user (id, age, name) VALUE (4, 20, NULL);")
+	if err == nil {
+		t.Fatalf("null value for NOT NULL field should fail")
+	}
+
+	count := func(where string) int {
+		return len(mustExec(t, s, "SELECT * FROM user WHERE "+where+";").Rows)
+	}
+	for where, want := range map[string]int{
+		"age IS NULL":              2,
+		"age IS NOT NULL":          1,
+		"age = 18":                 1,
+		"age != 18":                0,
+		"age = NULL":               0,
+		"name = ''":                1,
+		"id > 0 AND age < 100":     1,
+		"age = 18 OR age IS NULL":  3,
+		"age = 18 OR id = 3":       2,
+		"id >= 1 AND age IS NULL":  2,
+		"age < 10 OR age IS NULL":  2,
+		"name = 'c' OR (age > 10)": 2,
+	} {
+		if got := count(where); got != want {
+			t.Fatalf("%s: got %d rows, want %d", where, got, want)
+		}
+	}
+
+	res := mustExec(t, s, "SELECT * FROM user WHERE id = 2;")
+	if !res.IsNull(0, 1) || res.IsNull(0, 2) || res.Rows[0][2] != "" {
+		t.Fatalf("row %v nulls %v", res.Rows[0], res.Nulls[0])
+	}
+
+	mustExec(t, s, "UPDATE user SET age = NULL WHERE id = 1;")
+	if got := count("age IS NULL"); got != 3 {
+		t.Fatalf("update to null: got %d rows", got)
+	}
+}
+
+func TestSession_Default(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	s := New(tbm)
+
+	mustExec(t, s, "CREATE TABLE user (id INT64 AUTO_INCREMENT, name VARCHAR DEFAULT 'x', score INT32 NOT NULL DEFAULT '-1', created TIMESTAMP DEFAULT CURRENT_TIMESTAMP, note VARCHAR, PRIMARY KEY (id));")
+	mustExec(t, s, "INSERT INTO user (note) VALUE ('a');")
+	mustExec(t, s, "INSERT INTO user (id, name) VALUE (10, NULL);")
+	mustExec(t, s, "INSERT INTO user (id, note) VALUE (NULL, 'c');")
+
+	res := mustExec(t, s, "SELECT * FROM user WHERE id >= 1;")
+	if len(res.Rows) != 3 {
+		t.Fatalf("rows %v", res.Rows)
+	}
+	row := res.Rows[0]
+	if row[0] != "1" || row[1] != "x" || row[2] != "-1" || row[4] != "a" || res.IsNull(0, 3) {
+		t.Fatalf("row %v", row)
+	}
+	created, err := time.Parse(sql.TimestampFormat, row[3])
+	if err != nil || time.Since(created) > time.Minute {
+		t.Fatalf("created %s err %v", row[3], err)
+	}
+	if !res.IsNull(1, 1) || res.Rows[2][0] != "11" {
+		t.Fatalf("rows %v", res.Rows)
+	}
+
+	for _, stmt := range []string{
+		"CREATE TABLE bad (id INT64, age INT32 DEFAULT 'abc', PRIMARY KEY (id));",
+		"CREATE TABLE bad (id INT64, age INT32 DEFAULT CURRENT_TIMESTAMP, PRIMARY KEY (id));",
+		"CREATE TABLE bad (id INT64, age INT32 NOT NULL DEFAULT NULL, PRIMARY KEY (id));",
+		"CREATE TABLE bad (id VARCHAR AUTO_INCREMENT, PRIMARY KEY (id));",
+	} {
+		_, err = s.Execute(stmt)
+		if err == nil {
+			t.Fatalf("%s should fail", stmt)
+		}
+	}
+	s.Close()
+	closeFn()
+
+	// 重新打开后，默认值和自增序列仍然有效
+	tbm, closeFn = reopenTbm()
+	defer closeFn()
+	s = New(tbm)
+	defer s.Close()
+
+	mustExec(t, s, "INSERT INTO user (note) VALUE ('d');")
+	res = mustExec(t, s, "SELECT * FROM user WHERE note = 'd';")
+	if len(res.Rows) != 1 || res.Rows[0][0] != "12" || res.Rows[0][2] != "-1" {
+		t.Fatalf("rows %v", res.Rows)
+	}
+}
+
+func TestSession_Alter(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	s := New(tbm)
+
+	mustExec(t, s, "CREATE TABLE user (id INT64, name VARCHAR, age INT32, PRIMARY KEY (id));")
+	mustExec(t, s, "INSERT INTO user (id, name, age) VALUE (1, 'a', 10);")
+
+	// 新增字段，已有的数据使用默认值
+	mustExec(t, s, "ALTER TABLE user ADD COLUMN score INT32 NOT NULL DEFAULT '5';")
+	mustExec(t, s, "ALTER TABLE user ADD note VARCHAR;")
+	mustExec(t, s, "INSERT INTO user (id, name, age, score, note) VALUE (2, 'b', 20, 7, 'x');")
+	res := mustExec(t, s, "SELECT * FROM user WHERE id >= 1;")
+	if fmt.Sprint(res.Columns) != "[id name age score note]" || len(res.Rows) != 2 {
+		t.Fatalf("columns %v rows %v", res.Columns, res.Rows)
+	}
+	if res.Rows[0][3] != "5" || !res.IsNull(0, 4) || res.Rows[1][3] != "7" || res.Rows[1][4] != "x" {
+		t.Fatalf("rows %v", res.Rows)
+	}
+
+	// 删除字段和修改名称
+	mustExec(t, s, "ALTER TABLE user DROP COLUMN age;")
+	mustExec(t, s, "ALTER TABLE user RENAME COLUMN name TO nick;")
+	mustExec(t, s, "ALTER TABLE user RENAME TO member;")
+	mustExec(t, s, "INSERT INTO member (id, nick, score) VALUE (3, 'c', 9);")
+	res = mustExec(t, s, "SELECT * FROM member WHERE id >= 1;")
+	if fmt.Sprint(res.Columns) != "[id nick score note]" || fmt.Sprint(res.Rows) != "[[1 a 5 NULL] [2 b 7 x] [3 c 9 NULL]]" {
+		t.Fatalf("columns %v rows %v", res.Columns, res.Rows)
+	}
+	if _, err := s.Execute("SELECT * FROM user WHERE id = 1;"); !errors.Is(err, table.ErrNoSuchTable) {
+		t.Fatalf("old table name err %v", err)
+	}
+
+	for _, stmt := range []string{
+		"ALTER TABLE member ADD nick VARCHAR;",
+		"ALTER TABLE member ADD flag BOOL NOT NULL;",
+		"ALTER TABLE member ADD seq INT64 AUTO_INCREMENT;",
+		"ALTER TABLE member DROP id;",
+		"ALTER TABLE member DROP age;",
+		"ALTER TABLE member RENAME COLUMN nick TO score;",
+		"ALTER TABLE nothing RENAME TO other;",
+	} {
+		if _, err := s.Execute(stmt); err == nil {
+			t.Fatalf("%s should fail", stmt)
+		}
+	}
+
+	// 事务中修改表结构，其他事务不能访问，回滚后恢复
+	other := New(tbm)
+	mustExec(t, s, "BEGIN;")
+	mustExec(t, s, "ALTER TABLE member DROP COLUMN note;")
+	mustExec(t, s, "INSERT INTO member (id, nick, score) VALUE (4, 'd', 1);")
+	if _, err := other.Execute("SELECT * FROM member WHERE id = 1;"); !errors.Is(err, table.ErrSchemaBusy) {
+		t.Fatalf("other session err %v", err)
+	}
+	mustExec(t, s, "ROLLBACK;")
+	res = mustExec(t, other, "SELECT * FROM member WHERE id >= 1;")
+	if fmt.Sprint(res.Columns) != "[id nick score note]" || len(res.Rows) != 3 {
+		t.Fatalf("columns %v rows %v", res.Columns, res.Rows)
+	}
+	other.Close()
+	s.Close()
+	closeFn()
+
+	// 重新打开后，表结构和数据仍然有效
+	tbm, closeFn = reopenTbm()
+	defer closeFn()
+	s = New(tbm)
+	defer s.Close()
+
+	res = mustExec(t, s, "SELECT * FROM member WHERE id >= 1;")
+	if fmt.Sprint(res.Columns) != "[id nick score note]" || fmt.Sprint(res.Rows) != "[[1 a 5 NULL] [2 b 7 x] [3 c 9 NULL]]" {
+		t.Fatalf("columns %v rows %v", res.Columns, res.Rows)
+	}
+}
+
+func TestSession_Drop(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	s := New(tbm)
+
+	insert := func(n int) {
+		for i := 0; i < n; i++ {
+			mustExec(t, s, fmt.Sprintf("INSERT INTO user (name) VALUE ('name_%d');", i))
+		}
+	}
+	mustExec(t, s, "CREATE TABLE user (id INT64 AUTO_INCREMENT, name VARCHAR, PRIMARY KEY (id), INDEX name_idx (name));")
+	insert(3)
+
+	// 清空表，自增序列重新开始
+	mustExec(t, s, "TRUNCATE TABLE user;")
+	res := mustExec(t, s, "SELECT * FROM user WHERE name = 'name_1';")
+	if len(res.Rows) != 0 {
+		t.Fatalf("rows %v", res.Rows)
+	}
+	insert(2)
+	res = mustExec(t, s, "SELECT * FROM user WHERE id >= 1;")
+	if fmt.Sprint(res.Rows) != "[[1 name_0] [2 name_1]]" {
+		t.Fatalf("rows %v", res.Rows)
+	}
+
+	// 回滚删除表
+	other := New(tbm)
+	mustExec(t, s, "BEGIN;")
+	mustExec(t, s, "DROP TABLE user;")
+	if _, err := other.Execute("SELECT * FROM user WHERE id = 1;"); !errors.Is(err, table.ErrSchemaBusy) {
+		t.Fatalf("other session err %v", err)
+	}
+	mustExec(t, s, "ROLLBACK;")
+	res = mustExec(t, other, "SELECT * FROM user WHERE id >= 1;")
+	if len(res.Rows) != 2 {
+		t.Fatalf("rows %v", res.Rows)
+	}
+	other.Close()
+
+	// 删除表
+	mustExec(t, s, "DROP TABLE user;")
+	mustExec(t, s, "DROP TABLE IF EXISTS user;")
+	for _, stmt := range []string{
+		"DROP TABLE user;",
+		"TRUNCATE TABLE user;",
+		"SELECT * FROM user WHERE id = 1;",
+	} {
+		if _, err := s.Execute(stmt); !errors.Is(err, table.ErrNoSuchTable) {
+			t.Fatalf("%s err %v", stmt, err)
+		}
+	}
+
+	// 删除表之后，空间可以重新使用
+	size := func() int64 {
+		s.Close()
+		closeFn()
+		stat, err := os.Stat(db.RunPath() + "/temp/session/DB.BIN")
+		if err != nil {
+			t.Fatalf("stat err %v", err)
+		}
+		tbm, closeFn = reopenTbm()
+		s = New(tbm)
+		return stat.Size()
+	}
+	mustExec(t, s, "CREATE TABLE user (id INT64 AUTO_INCREMENT, name VARCHAR, PRIMARY KEY (id), INDEX name_idx (name));")
+	insert(500)
+	before := size()
+	mustExec(t, s, "DROP TABLE user;")
+	mustExec(t, s, "CREATE TABLE user (id INT64 AUTO_INCREMENT, name VARCHAR, PRIMARY KEY (id), INDEX name_idx (name));")
+	insert(500)
+	after := size()
+	if after > before {
+		t.Fatalf("space not reused, size %d -> %d", before, after)
+	}
+
+	res = mustExec(t, s, "SELECT * FROM user WHERE name = 'name_499';")
+	if fmt.Sprint(res.Rows) != "[[500 name_499]]" {
+		t.Fatalf("rows %v", res.Rows)
+	}
+	s.Close()
+	closeFn()
+}
+
+func TestSession_Index(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	s := New(tbm)
+
+	mustExec(t, s, "CREATE TABLE user (id INT64, name VARCHAR, age INT32, PRIMARY KEY (id));")
+	for i := 1; i <= 20; i++ {
+		mustExec(t, s, fmt.Sprintf("INSERT INTO user (id, name, age) VALUE (%d, 'name_%d', %d);", i, i, 20+i%3))
+	}
+	mustExec(t, s, "INSERT INTO user (id, age) VALUE (21, 20);")
+	mustExec(t, s, "DELETE FROM user WHERE id = 1;")
+
+	count := func(where string) int {
+		res := mustExec(t, s, "SELECT * FROM user WHERE "+where+";")
+		return len(res.Rows)
+	}
+
+	// 创建索引，使用已有的数据填充索引
+	mustExec(t, s, "CREATE INDEX age_idx ON user (age);")
+	mustExec(t, s, "CREATE UNIQUE INDEX name_uni ON user (name);")
+	if got := count("age = 20"); got != 7 {
+		t.Fatalf("age = 20: got %d rows", got)
+	}
+	if got := count("name = 'name_5'"); got != 1 {
+		t.Fatalf("name = 'name_5': got %d rows", got)
+	}
+	mustExec(t, s, "INSERT INTO user (id, name, age) VALUE (22, 'name_22', 21);")
+	if got := count("age = 21"); got != 7 {
+		t.Fatalf("age = 21: got %d rows", got)
+	}
+
+	for _, stmt := range []string{
+		"CREATE INDEX age_idx ON user (name);",
+		"CREATE INDEX age_idx2 ON user (age);",
+		"CREATE INDEX x_idx ON user (nothing);",
+		"CREATE INDEX x_idx ON nothing (age);",
+		"DROP INDEX PRIMARY ON user;",
+		"DROP INDEX nothing;",
+	} {
+		if _, err := s.Execute(stmt); err == nil {
+			t.Fatalf("%s should fail", stmt)
+		}
+	}
+
+	// 唯一索引，已有重复的值时创建失败
+	mustExec(t, s, "DROP INDEX age_idx ON user;")
+	if _, err := s.Execute("CREATE UNIQUE INDEX age_uni ON user (age);"); err == nil {
+		t.Fatalf("unique index on duplicated values should fail")
+	}
+
+	// 回滚创建索引
+	mustExec(t, s, "BEGIN;")
+	mustExec(t, s, "CREATE INDEX age_idx ON user (age);")
+	mustExec(t, s, "ROLLBACK;")
+	mustExec(t, s, "DROP INDEX name_uni;")
+	mustExec(t, s, "CREATE INDEX age_idx ON user (age);")
+	s.Close()
+	closeFn()
+
+	// 重新打开后，索引仍然有效
+	tbm, closeFn = reopenTbm()
+	defer closeFn()
+	s = New(tbm)
+	defer s.Close()
+
+	if got := count("age = 22"); got != 7 {
+		t.Fatalf("age = 22: got %d rows", got)
+	}
+	if got := count("name = 'name_5'"); got != 1 {
+		t.Fatalf("name = 'name_5': got %d rows", got)
+	}
+}
+
+func TestSession_IndexActive(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	defer closeFn()
+
+	s1 := New(tbm)
+	s2 := New(tbm)
+	defer s1.Close()
+	defer s2.Close()
+
+	mustExec(t, s1, "CREATE TABLE u (id INT64, name VARCHAR, PRIMARY KEY (id));")
+	mustExec(t, s1, "INSERT INTO u (id, name) VALUE (1, 'a');")
+
+	// 创建索引时没有提交的数据，提交之后可以通过索引读取
+	mustExec(t, s2, "BEGIN;")
+	mustExec(t, s2, "INSERT INTO u (id, name) VALUE (2, 'b');")
+	mustExec(t, s1, "CREATE INDEX iname ON u (name);")
+	mustExec(t, s2, "COMMIT;")
+	res := mustExec(t, s1, "SELECT id FROM u WHERE name = 'b';")
+	if fmt.Sprint(res.Rows) != "[[2]]" {
+		t.Fatalf("rows %v", res.Rows)
+	}
+
+	// 回滚的数据不能通过索引读取
+	mustExec(t, s2, "BEGIN;")
+	mustExec(t, s2, "INSERT INTO u (id, name) VALUE (3, 'c');")
+	mustExec(t, s1, "DROP INDEX iname ON u;")
+	mustExec(t, s1, "CREATE INDEX iname ON u (name);")
+	mustExec(t, s2, "ROLLBACK;")
+	res = mustExec(t, s1, "SELECT id FROM u WHERE name = 'c';")
+	if len(res.Rows) != 0 {
+		t.Fatalf("rows %v", res.Rows)
+	}
+
+	// 唯一索引，其他事务没有提交的数据无法确定是否重复
+	mustExec(t, s2, "BEGIN;")
+	mustExec(t, s2, "INSERT INTO u (id, name) VALUE (4, 'a');")
+	mustExec(t, s1, "DROP INDEX iname ON u;")
+	_, err := s1.Execute("CREATE UNIQUE INDEX uname ON u (name);")
+	if !errors.Is(err, ver.ErrCannotHandle) {
+		t.Fatalf("err %v", err)
+	}
+	mustExec(t, s2, "ROLLBACK;")
+	mustExec(t, s1, "CREATE UNIQUE INDEX uname ON u (name);")
+}
+
+func TestSession_Heap(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	s := New(tbm)
+
+	// 没有主键的表
+	mustExec(t, s, "CREATE TABLE log (level VARCHAR, msg VARCHAR, code INT32);")
+	for i := 1; i <= 300; i++ {
+		mustExec(t, s, fmt.Sprintf("INSERT INTO log (level, msg, code) VALUE ('level_%d', 'msg_%d', %d);", i%3, i, i))
+	}
+
+	count := func(where string) int {
+		sql := "SELECT * FROM log;"
+		if where != "" {
+			sql = "SELECT * FROM log WHERE " + where + ";"
+		}
+		return len(mustExec(t, s, sql).Rows)
+	}
+	if got := count(""); got != 300 {
+		t.Fatalf("all: got %d rows", got)
+	}
+	if got := count("level = 'level_1'"); got != 100 {
+		t.Fatalf("level = 'level_1': got %d rows", got)
+	}
+	if got := count("code > 290"); got != 10 {
+		t.Fatalf("code > 290: got %d rows", got)
+	}
+
+	// 修改和删除
+	mustExec(t, s, "UPDATE log SET msg = 'updated' WHERE level = 'level_0';")
+	mustExec(t, s, "DELETE FROM log WHERE code <= 30;")
+	if got := count("msg = 'updated'"); got != 90 {
+		t.Fatalf("msg = 'updated': got %d rows", got)
+	}
+	if got := count(""); got != 270 {
+		t.Fatalf("all: got %d rows", got)
+	}
+
+	// 没有主键的表也可以创建索引
+	mustExec(t, s, "CREATE INDEX code_idx ON log (code);")
+	res := mustExec(t, s, "SELECT * FROM log WHERE code = 31;")
+	if fmt.Sprint(res.Rows) != "[[level_1 msg_31 31]]" {
+		t.Fatalf("rows %v", res.Rows)
+	}
+	s.Close()
+	closeFn()
+
+	// 重新打开后，仍然可以遍历全部数据
+	tbm, closeFn = reopenTbm()
+	defer closeFn()
+	s = New(tbm)
+	defer s.Close()
+
+	if got := count(""); got != 270 {
+		t.Fatalf("all: got %d rows", got)
+	}
+	if got := count("level = 'level_0'"); got != 90 {
+		t.Fatalf("level = 'level_0': got %d rows", got)
+	}
+	mustExec(t, s, "INSERT INTO log (level, msg, code) VALUE ('level_9', 'msg_301', 301);")
+	if got := count("code = 301"); got != 1 {
+		t.Fatalf("code = 301: got %d rows", got)
+	}
+
+	// 主键字段必须存在
+	if _, err := s.Execute("CREATE TABLE bad (id INT64, PRIMARY KEY (nothing));"); err == nil {
+		t.Fatalf("create with unknown primary key should fail")
+	}
+	mustExec(t, s, "DROP TABLE log;")
+}
+
+func TestSession_Unique(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	defer closeFn()
+	s := New(tbm)
+	defer s.Close()
+
+	dup := func(stmt, index string) {
+		_, err := s.Execute(stmt)
+		var e *table.DuplicateKeyError
+		if !errors.As(err, &e) || e.Index != index {
+			t.Fatalf("%s err %v", stmt, err)
+		}
+	}
+
+	// 长度超过索引键的字符串，只有前缀相同时不是重复的值
+	prefix := strings.Repeat("x", 100)
+	mustExec(t, s, "CREATE TABLE user (id INT64, name VARCHAR, age INT32, PRIMARY KEY (id));")
+	mustExec(t, s, "CREATE UNIQUE INDEX name_uni ON user (name);")
+	mustExec(t, s, fmt.Sprintf("INSERT INTO user (id, name, age) VALUE (1, '%s_a', 20);", prefix))
+	mustExec(t, s, fmt.Sprintf("INSERT INTO user (id, name, age) VALUE (2, '%s_b', 20);", prefix))
+	mustExec(t, s, "INSERT INTO user (id, age) VALUE (3, 20);")
+	mustExec(t, s, "INSERT INTO user (id, age) VALUE (4, 20);")
+
+	dup("INSERT INTO user (id, name) VALUE (1, 'other');", "PRIMARY")
+	dup(fmt.Sprintf("INSERT INTO user (id, name) VALUE (5, '%s_a');", prefix), "name_uni")
+	dup("UPDATE user SET id = 1 WHERE id = 2;", "PRIMARY")
+	dup(fmt.Sprintf("UPDATE user SET name = '%s_a' WHERE id = 3;", prefix), "name_uni")
+	dup("UPDATE user SET name = 'same' WHERE age = 20;", "name_uni")
+
+	// 修改为自身的值，删除之后可以重新插入
+	mustExec(t, s, "UPDATE user SET id = 2, age = 21 WHERE id = 2;")
+	mustExec(t, s, "DELETE FROM user WHERE id = 1;")
+	mustExec(t, s, fmt.Sprintf("INSERT INTO user (id, name) VALUE (1, '%s_a');", prefix))
+
+	// 事务中的数据对当前事务可见
+	mustExec(t, s, "BEGIN;")
+	mustExec(t, s, "INSERT INTO user (id, name) VALUE (10, 'tx');")
+	dup("INSERT INTO user (id, name) VALUE (11, 'tx');", "name_uni")
+	mustExec(t, s, "ROLLBACK;")
+	mustExec(t, s, "INSERT INTO user (id, name) VALUE (10, 'tx');")
+
+	res := mustExec(t, s, "SELECT * FROM user WHERE id >= 1;")
+	if len(res.Rows) != 5 {
+		t.Fatalf("rows %v", res.Rows)
+	}
+}
+
+func TestSession_UniqueConcurrent(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	defer closeFn()
+
+	s1 := New(tbm)
+	s2 := New(tbm)
+	defer s1.Close()
+	defer s2.Close()
+
+	mustExec(t, s1, "CREATE TABLE user (id INT64, name VARCHAR, PRIMARY KEY (id));")
+
+	// 快照之后其他事务提交的数据对当前事务不可见，但是仍然是重复的值
+	mustExec(t, s1, "BEGIN;")
+	mustExec(t, s1, "SELECT * FROM user;")
+	mustExec(t, s2, "INSERT INTO user (id, name) VALUE (1, 'a');")
+	_, err := s1.Execute("INSERT INTO user (id, name) VALUE (1, 'b');")
+	var dup *table.DuplicateKeyError
+	if !errors.As(err, &dup) || dup.Index != "PRIMARY" {
+		t.Fatalf("err %v", err)
+	}
+	mustExec(t, s1, "ROLLBACK;")
+
+	// 其他事务写入但是没有提交的数据，返回写冲突
+	mustExec(t, s2, "BEGIN;")
+	mustExec(t, s2, "INSERT INTO user (id, name) VALUE (2, 'a');")
+	_, err = s1.Execute("INSERT INTO user (id, name) VALUE (2, 'b');")
+	if !errors.Is(err, ver.ErrCannotHandle) {
+		t.Fatalf("err %v", err)
+	}
+	mustExec(t, s2, "COMMIT;")
+
+	// 其他事务删除但是没有提交的数据，同样返回写冲突（删除的事务可能回滚）
+	mustExec(t, s2, "BEGIN;")
+	mustExec(t, s2, "DELETE FROM user WHERE id = 2;")
+	_, err = s1.Execute("INSERT INTO user (id, name) VALUE (2, 'b');")
+	if !errors.Is(err, ver.ErrCannotHandle) {
+		t.Fatalf("err %v", err)
+	}
+	mustExec(t, s2, "ROLLBACK;")
+
+	// 写入数据的事务回滚之后，可以插入相同的值
+	mustExec(t, s2, "BEGIN;")
+	mustExec(t, s2, "INSERT INTO user (id, name) VALUE (3, 'a');")
+	mustExec(t, s2, "ROLLBACK;")
+	mustExec(t, s1, "INSERT INTO user (id, name) VALUE (3, 'b');")
+
+	res := mustExec(t, s1, "SELECT id, name FROM user ORDER BY id;")
+	if fmt.Sprint(res.Rows) != "[[1 a] [2 a] [3 b]]" {
+		t.Fatalf("rows %v", res.Rows)
+	}
+
+	// 只有前 32 个字节（索引的键）相同的值不是重复的值，不会产生写冲突
+	mustExec(t, s1, "CREATE TABLE mail (id INT64, email VARCHAR, PRIMARY KEY (id));")
+	mustExec(t, s1, "CREATE UNIQUE INDEX email_idx ON mail (email);")
+	prefix := strings.Repeat("a", 32)
+	mustExec(t, s1, "BEGIN;")
+	mustExec(t, s2, "BEGIN;")
+	mustExec(t, s1, "INSERT INTO mail (id, email) VALUE (1, '"+prefix+"@x.com');")
+	mustExec(t, s2, "INSERT INTO mail (id, email) VALUE (2, '"+prefix+"@y.com');")
+	mustExec(t, s1, "COMMIT;")
+	mustExec(t, s2, "COMMIT;")
+	_, err = s1.Execute("INSERT INTO mail (id, email) VALUE (3, '" + prefix + "@y.com');")
+	if !errors.As(err, &dup) || dup.Index != "email_idx" {
+		t.Fatalf("err %v", err)
+	}
+}
+
+func TestSession_Purge(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	defer closeFn()
+	s1 := New(tbm)
+	s2 := New(tbm)
+	defer s1.Close()
+	defer s2.Close()
+
+	mustExec(t, s1, "CREATE TABLE user (id INT64, name VARCHAR, PRIMARY KEY (id), INDEX name_idx (name));")
+	mustExec(t, s1, "INSERT INTO user (id, name) VALUE (1, 'a');")
+
+	// 事务开始之后，其他事务的修改不可见，旧版本的索引项仍然保留
+	mustExec(t, s1, "BEGIN;")
+	mustExec(t, s1, "SELECT * FROM user WHERE id = 1;")
+	mustExec(t, s2, "UPDATE user SET name = 'b' WHERE id = 1;")
+	for i := 0; i < 10; i++ {
+		mustExec(t, s2, fmt.Sprintf("UPDATE user SET name = 'c%d' WHERE id = 1;", i))
+	}
+	mustExec(t, s2, "DELETE FROM user WHERE id = 1;")
+	res := mustExec(t, s1, "SELECT * FROM user WHERE name = 'a';")
+	if fmt.Sprint(res.Rows) != "[[1 a]]" {
+		t.Fatalf("rows %v", res.Rows)
+	}
+	mustExec(t, s1, "COMMIT;")
+
+	// 回滚的新版本和删除的旧版本都已经从索引中删除
+	mustExec(t, s1, "BEGIN;")
+	mustExec(t, s1, "INSERT INTO user (id, name) VALUE (2, 'd');")
+	mustExec(t, s1, "ROLLBACK;")
+	mustExec(t, s1, "INSERT INTO user (id, name) VALUE (1, 'a');")
+	for _, where := range []string{"name = 'a'", "id = 1", "id >= 1", "name >= 'a'"} {
+		res = mustExec(t, s2, "SELECT * FROM user WHERE "+where+";")
+		if fmt.Sprint(res.Rows) != "[[1 a]]" {
+			t.Fatalf("%s rows %v", where, res.Rows)
+		}
+	}
+}
+
+func TestSession_Composite(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	s := New(tbm)
+
+	mustExec(t, s, "CREATE TABLE log (id INT64, tenant_id INT32, created_at INT64, msg VARCHAR, PRIMARY KEY (id), INDEX tenant_time (tenant_id, created_at));")
+	for i := 1; i <= 30; i++ {
+		mustExec(t, s, fmt.Sprintf("INSERT INTO log (id, tenant_id, created_at, msg) VALUE (%d, %d, %d, 'msg_%d');", i, i%3, 1000+i, i))
+	}
+	mustExec(t, s, "INSERT INTO log (id, tenant_id, msg) VALUE (100, 1, 'no time');")
+
+	count := func(where string) int {
+		res := mustExec(t, s, "SELECT * FROM log WHERE "+where+";")
+		return len(res.Rows)
+	}
+	check := func() {
+		for where, want := range map[string]int{
+			"tenant_id = 1": 11,
+			"tenant_id = 2 AND created_at >= 1010 AND created_at < 1020": 3,
+			"tenant_id = 2 AND created_at > 1029":                        0,
+			"tenant_id = 1 AND created_at = 1010":                        1,
+			"tenant_id >= 1 AND created_at = 1011":                       1,
+			"tenant_id = 1 AND created_at IS NULL":                       1,
+			"created_at = 1010":                                          1,
+		} {
+			if got := count(where); got != want {
+				t.Fatalf("%s: got %d rows, want %d", where, got, want)
+			}
+		}
+	}
+	check()
+
+	// 多列唯一索引，全部字段相同时重复
+	mustExec(t, s, "CREATE UNIQUE INDEX msg_uni ON log (msg, tenant_id);")
+	mustExec(t, s, "INSERT INTO log (id, tenant_id, msg) VALUE (101, 0, 'no time');")
+	if _, err := s.Execute("INSERT INTO log (id, tenant_id, msg) VALUE (102, 1, 'no time');"); err == nil {
+		t.Fatalf("duplicated composite unique index should fail")
+	}
+	if got := count("msg = 'msg_5' AND tenant_id = 2"); got != 1 {
+		t.Fatalf("msg = 'msg_5': got %d rows", got)
+	}
+	mustExec(t, s, "DELETE FROM log WHERE id = 101;")
+
+	for _, stmt := range []string{
+		"CREATE INDEX x_idx ON log (created_at, created_at);",
+		"CREATE INDEX x_idx ON log (created_at, nothing);",
+		"CREATE INDEX x_idx ON log (tenant_id, msg);",
+		"ALTER TABLE log DROP COLUMN created_at;",
+		"CREATE TABLE x (a INT32, b INT32, PRIMARY KEY (a, c));",
+		"CREATE TABLE x (a INT32, b INT32, PRIMARY KEY (a, b), INDEX a_idx (a));",
+	} {
+		if _, err := s.Execute(stmt); err == nil {
+			t.Fatalf("%s should fail", stmt)
+		}
+	}
+
+	// 修改字段名称，多列索引中的字段名称同时修改
+	mustExec(t, s, "ALTER TABLE log RENAME COLUMN created_at TO ts;")
+	if got := count("tenant_id = 2 AND ts >= 1010 AND ts < 1020"); got != 3 {
+		t.Fatalf("renamed: got %d rows", got)
+	}
+	mustExec(t, s, "ALTER TABLE log RENAME COLUMN ts TO created_at;")
+	mustExec(t, s, "DROP INDEX msg_uni ON log;")
+
+	// 多列主键，全部字段都不允许为空
+	mustExec(t, s, "CREATE TABLE member (tenant_id INT32, name VARCHAR, age INT32, PRIMARY KEY (tenant_id, name));")
+	mustExec(t, s, "INSERT INTO member (tenant_id, name, age) VALUE (1, 'a', 20);")
+	mustExec(t, s, "INSERT INTO member (tenant_id, name, age) VALUE (1, 'b', 20);")
+	mustExec(t, s, "INSERT INTO member (tenant_id, name, age) VALUE (2, 'a', 20);")
+	for _, stmt := range []string{
+		"INSERT INTO member (tenant_id, name) VALUE (1, 'a');",
+		"INSERT INTO member (tenant_id) VALUE (3);",
+		"UPDATE member SET tenant_id = 1 WHERE tenant_id = 2;",
+	} {
+		if _, err := s.Execute(stmt); err == nil {
+			t.Fatalf("%s should fail", stmt)
+		}
+	}
+	s.Close()
+	closeFn()
+
+	// 重新打开后，多列索引仍然有效
+	tbm, closeFn = reopenTbm()
+	defer closeFn()
+	s = New(tbm)
+	defer s.Close()
+	check()
+
+	res := mustExec(t, s, "SELECT * FROM member WHERE tenant_id = 1 AND name >= 'b';")
+	if fmt.Sprint(res.Rows) != "[[1 b 20]]" {
+		t.Fatalf("rows %v", res.Rows)
+	}
+}
+
+func TestSession_Analyze(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	s := New(tbm)
+
+	mustExec(t, s, "CREATE TABLE user (id INT64, name VARCHAR, age INT32, PRIMARY KEY (id), INDEX age_idx (age), INDEX name_idx (name));")
+	for i := 1; i <= 50; i++ {
+		mustExec(t, s, fmt.Sprintf("INSERT INTO user (id, name, age) VALUE (%d, 'name_%d', %d);", i, i%5, 20+i%10))
+	}
+	if _, err := s.Execute("ANALYZE TABLE nothing;"); err == nil {
+		t.Fatalf("analyze no such table should fail")
+	}
+	mustExec(t, s, "ANALYZE TABLE user;")
+	mustExec(t, s, "BEGIN;")
+	mustExec(t, s, "DELETE FROM user WHERE age = 20;")
+	mustExec(t, s, "ANALYZE TABLE user;")
+	mustExec(t, s, "ROLLBACK;")
+	s.Close()
+	closeFn()
+
+	// 重新打开后，统计信息仍然有效
+	tbm, closeFn = reopenTbm()
+	defer closeFn()
+	s = New(tbm)
+	defer s.Close()
+	for where, want := range map[string]int{
+		"age = 21":                          5,
+		"age >= 21 AND name = 'name_1'":     10,
+		"age != 21 AND name != 'name_1'":    40,
+		"id >= 10 AND id < 20 AND age = 25": 1,
+	} {
+		res := mustExec(t, s, "SELECT * FROM user WHERE "+where+";")
+		if len(res.Rows) != want {
+			t.Fatalf("%s: got %d rows, want %d", where, len(res.Rows), want)
+		}
+	}
+	mustExec(t, s, "DROP TABLE user;")
+}
+
+func TestSession_Order(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	defer closeFn()
+	s := New(tbm)
+	defer s.Close()
+
+	mustExec(t, s, "CREATE TABLE user (id INT64, name VARCHAR, score DECIMAL(5,2), born DATE, PRIMARY KEY (id));")
+	mustExec(t, s, "INSERT INTO user (id, name, score, born) VALUE (1, 'b', 9.5, '2000-01-02');")
+	mustExec(t, s, "INSERT INTO user (id, name, score, born) VALUE (2, 'a', 10.25, '1999-12-31');")
+	mustExec(t, s, "INSERT INTO user (id, name, score, born) VALUE (3, 'c', 9.5, '2001-05-01');")
+	mustExec(t, s, "INSERT INTO user (id, score) VALUE (4, 1.0);")
+
+	ids := func(stmt string) string {
+		res := mustExec(t, s, stmt)
+		out := make([]string, 0)
+		for _, row := range res.Rows {
+			out = append(out, row[0])
+		}
+		return strings.Join(out, ",")
+	}
+	for stmt, want := range map[string]string{
+		"SELECT * FROM user ORDER BY name;":                        "4,2,1,3",
+		"SELECT * FROM user ORDER BY name DESC;":                   "3,1,2,4",
+		"SELECT * FROM user ORDER BY score DESC, born DESC;":       "2,3,1,4",
+		"SELECT * FROM user ORDER BY score, id DESC;":              "4,3,1,2",
+		"SELECT * FROM user ORDER BY born LIMIT 2;":                "4,2",
+		"SELECT * FROM user ORDER BY born LIMIT 2 OFFSET 1;":       "2,1",
+		"SELECT * FROM user ORDER BY id DESC LIMIT 1, 2;":          "3,2",
+		"SELECT * FROM user WHERE id >= 2 LIMIT 2;":                "2,3",
+		"SELECT * FROM user WHERE id >= 2 ORDER BY id LIMIT 0;":    "",
+		"SELECT * FROM user WHERE id >= 2 ORDER BY id LIMIT 9, 9;": "",
+	} {
+		if got := ids(stmt); got != want {
+			t.Fatalf("%s: got %s, want %s", stmt, got, want)
+		}
+	}
+	if _, err := s.Execute("SELECT * FROM user ORDER BY nothing;"); err == nil {
+		t.Fatalf("order by unknown field should fail")
+	}
+}
+
+func TestSession_Project(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	defer closeFn()
+	s := New(tbm)
+	defer s.Close()
+
+	mustExec(t, s, "CREATE TABLE user (id INT64, name VARCHAR, age INT32, PRIMARY KEY (id));")
+	mustExec(t, s, "INSERT INTO user (id, name, age) VALUE (1, 'b', 20);")
+	mustExec(t, s, "INSERT INTO user (id, name, age) VALUE (2, 'a', 30);")
+
+	for stmt, want := range map[string]string{
+		"SELECT * FROM user WHERE id = 1;":                                  "[id name age] [INT64 VARCHAR INT32] [[1 b 20]]",
+		"SELECT age, id FROM user WHERE id = 1;":                            "[age id] [INT32 INT64] [[20 1]]",
+		"SELECT name AS n, *, id FROM user ORDER BY n;":                     "[n id name age id] [VARCHAR INT64 VARCHAR INT32 INT64] [[a 2 a 30 2] [b 1 b 20 1]]",
+		"SELECT name AS id2, age AS `years` FROM user ORDER BY years DESC;": "[id2 years] [VARCHAR INT32] [[a 30] [b 20]]",
+	} {
+		res := mustExec(t, s, stmt)
+		if got := fmt.Sprint(res.Columns, res.Types, res.Rows); got != want {
+			t.Fatalf("%s: got %s, want %s", stmt, got, want)
+		}
+	}
+
+	for _, stmt := range []string{
+		"SELECT nothing FROM user;",
+		"SELECT id, nothing AS n FROM user;",
+		"SELECT name AS id, id FROM user;",
+		"SELECT * AS x FROM user;",
+	} {
+		if _, err := s.Execute(stmt); err == nil {
+			t.Fatalf("%s should fail", stmt)
+		}
+	}
+}
+
+func TestSession_Aggregate(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	defer closeFn()
+	s := New(tbm)
+	defer s.Close()
+
+	mustExec(t, s, "CREATE TABLE user (id INT64, city VARCHAR, age INT32, score DECIMAL(6,2), PRIMARY KEY (id));")
+	mustExec(t, s, "INSERT INTO user (id, city, age, score) VALUE (1, 'bj', 20, 1.50);")
+	mustExec(t, s, "INSERT INTO user (id, city, age, score) VALUE (2, 'sh', 30, 2.00);")
+	mustExec(t, s, "INSERT INTO user (id, city, age, score) VALUE (3, 'bj', 40, NULL);")
+	mustExec(t, s, "INSERT INTO user (id, city, age, score) VALUE (4, NULL, 50, 3.25);")
+	mustExec(t, s, "INSERT INTO user (id, city, age, score) VALUE (5, 'bj', 60, 1.00);")
+
+	for stmt, want := range map[string]string{
+		"SELECT COUNT(*), COUNT(score), SUM(age), MIN(city), MAX(score) FROM user;": "[COUNT(*) COUNT(score) SUM(age) MIN(city) MAX(score)] [INT64 INT64 INT64 VARCHAR DECIMAL(6,2)] [[5 4 200 bj 3.25]]",
+		"SELECT AVG(age) AS a, AVG(score) AS s, SUM(score) FROM user;":              "[a s SUM(score)] [DOUBLE DECIMAL(18,6) DECIMAL(18,2)] [[40 1.937500 7.75]]",
+		// 没有数据时也有一条数据
+		"SELECT COUNT(*), SUM(age) FROM user WHERE id > 10;": "[COUNT(*) SUM(age)] [INT64 INT64] [[0 NULL]]",
+		// NULL 是一个分组，分组按照第一次出现的顺序
+		"SELECT city, COUNT(*) AS n FROM user GROUP BY city;": "[city n] [VARCHAR INT64] [[bj 3] [sh 1] [NULL 1]]",
+		// HAVING 和 ORDER BY 可以使用别名和没有查询的聚合函数
+		"SELECT city, COUNT(*) AS n FROM user WHERE age >= 30 GROUP BY city HAVING n > 1 OR MAX(age) = 30 ORDER BY SUM(age) DESC;": "[city n] [VARCHAR INT64] [[bj 2] [sh 1]]",
+		"SELECT city FROM user GROUP BY city ORDER BY city LIMIT 1, 2;":                                                            "[city] [VARCHAR] [[bj] [sh]]",
+		"SELECT COUNT(*) FROM user GROUP BY city HAVING COUNT(*) > 5;":                                                             "[COUNT(*)] [INT64] []",
+	} {
+		res := mustExec(t, s, stmt)
+		if got := fmt.Sprint(res.Columns, res.Types, res.Rows); got != want {
+			t.Fatalf("%s: got %s, want %s", stmt, got, want)
+		}
+	}
+
+	for _, stmt := range []string{
+		"SELECT city, age FROM user GROUP BY city;",
+		"SELECT COUNT(*) FROM user WHERE COUNT(*) > 1;",
+		"SELECT SUM(city) FROM user;",
+		"SELECT MAX(*) FROM user;",
+		"SELECT COUNT(nothing) FROM user;",
+		"SELECT COUNT(*) FROM user HAVING age > 1;",
+		"SELECT COUNT(*) AS city FROM user GROUP BY city;",
+	} {
+		if _, err := s.Execute(stmt); err == nil {
+			t.Fatalf("%s should fail", stmt)
+		}
+	}
+}
+
+func TestSession_Join(t *testing.T) {
+	tbm, closeFn := openTbm(t)
+	defer closeFn()
+	s := New(tbm)
+	defer s.Close()
+
+	mustExec(t, s, "CREATE TABLE user (id INT64, name VARCHAR, city INT32, PRIMARY KEY (id));")
+	mustExec(t, s, "CREATE TABLE orders (id INT64, uid INT64, amount INT32, PRIMARY KEY (id), INDEX uid_idx (uid));")
+	mustExec(t, s, "CREATE TABLE city (code INT64, name VARCHAR, PRIMARY KEY (name));")
+	mustExec(t, s, "INSERT INTO user (id, name, city) VALUE (1, 'a', 10);")
+	mustExec(t, s, "INSERT INTO user (id, name, city) VALUE (2, 'b', 20);")
+	mustExec(t, s, "INSERT INTO user (id, name, city) VALUE (3, 'c', NULL);")
+	mustExec(t, s, "INSERT INTO orders (id, uid, amount) VALUE (1, 1, 100);")
+	mustExec(t, s, "INSERT INTO orders (id, uid, amount) VALUE (2, 1, 200);")
+	mustExec(t, s, "INSERT INTO orders (id, uid, amount) VALUE (3, 2, 50);")
+	mustExec(t, s, "INSERT INTO city (code, name) VALUE (10, 'bj');")
+	mustExec(t, s, "INSERT INTO city (code, name) VALUE (30, 'sz');")
+
+	for stmt, want := range map[string]string{
+		"SELECT u.name, o.amount FROM user u JOIN orders o ON u.id = o.uid ORDER BY o.id;":                    "[u.name o.amount] [VARCHAR INT32] [[a 100] [a 200] [b 50]]",
+		"SELECT u.name, o.id AS oid FROM user u LEFT JOIN orders o ON o.uid = u.id ORDER BY u.id, oid DESC;":  "[u.name oid] [VARCHAR INT64] [[a 2] [a 1] [b 3] [c NULL]]",
+		"SELECT user.name, c.name AS city FROM user LEFT JOIN city c ON user.city = c.code ORDER BY user.id;": "[user.name city] [VARCHAR VARCHAR] [[a bj] [b NULL] [c NULL]]",
+		"SELECT a.id, b.id FROM user a JOIN user b ON a.id < b.id ORDER BY a.id, b.id;":                       "[a.id b.id] [INT64 INT64] [[1 2] [1 3] [2 3]]",
+		// 没有歧义的字段可以省略表别名，LEFT JOIN 的右表的条件在连接之后判断
+		"SELECT u.name, amount FROM user u LEFT JOIN orders o ON u.id = o.uid WHERE amount IS NULL OR amount < 100;":                         "[u.name amount] [VARCHAR INT32] [[b 50] [c NULL]]",
+		"SELECT * FROM user u JOIN orders o ON u.id = o.uid JOIN city ON city.code = u.city WHERE o.amount >= 200;":                          "[u.id u.name u.city o.id o.uid o.amount city.code city.name] [INT64 VARCHAR INT32 INT64 INT64 INT32 INT64 VARCHAR] [[1 a 10 2 1 200 10 bj]]",
+		"SELECT o.*, city.name FROM orders o JOIN user u ON u.id = o.uid JOIN city ON u.city = city.code;":                                   "[o.id o.uid o.amount city.name] [INT64 INT64 INT32 VARCHAR] [[1 1 100 bj] [2 1 200 bj]]",
+		"SELECT u.name, COUNT(o.id) AS n, SUM(amount) FROM user u LEFT JOIN orders o ON u.id = o.uid GROUP BY u.name ORDER BY n DESC, name;": "[u.name n SUM(amount)] [VARCHAR INT64 INT64] [[a 2 300] [b 1 50] [c 0 NULL]]",
+	} {
+		res := mustExec(t, s, stmt)
+		if got := fmt.Sprint(res.Columns, res.Types, res.Rows); got != want {
+			t.Fatalf("%s: got %s, want %s", stmt, got, want)
+		}
+	}
+
+	for _, stmt := range []string{
+		"SELECT name FROM user u JOIN city c ON u.city = c.code;",
+		"SELECT * FROM user JOIN user ON user.id = user.id;",
+		"SELECT * FROM user u JOIN orders o ON o.uid = c.code JOIN city c ON c.code = u.city;",
+		"SELECT * FROM user u JOIN nothing n ON u.id = n.id;",
+		"SELECT * FROM user u JOIN orders o ON u.id = o.nothing;",
+	} {
+		if _, err := s.Execute(stmt); err == nil {
+			t.Fatalf("%s should fail", stmt)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package table
+
+import (
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/ggymm/db/pkg/sql"
+)
+
+// 聚合
+//
+// 查询中有聚合函数，或者有 GROUP BY、HAVING 时，按照 GROUP BY 的字段将数据分组（哈希聚合）
+// 每个分组输出一条数据，包含分组的字段和聚合函数的结果，聚合函数的结果使用 COUNT(*)、SUM(age) 形式的名称
+// 没有 GROUP BY 时全部数据是一个分组（没有数据时也输出一条数据）
+//
+// 分组之后的数据中还包含查询字段的别名，HAVING 和 ORDER BY 可以使用别名、分组的字段和聚合函数
+// 只在 HAVING 和 ORDER BY 中使用的聚合函数也会被计算，但是不会出现在查询结果中
+// 查询的字段中不是聚合函数的字段必须出现在 GROUP BY 中
+//
+// NULL 不参与聚合（COUNT(*) 除外），没有参与聚合的值时结果为 NULL（COUNT 为 0）
+// 分组的字段为 NULL 的数据属于同一个分组
+//
+// 聚合结果的类型：
+// COUNT：INT64
+// SUM：整数为 INT64，DOUBLE 为 DOUBLE，DECIMAL 为 DECIMAL(18,s)
+// AVG：整数和 DOUBLE 为 DOUBLE，DECIMAL 为 DECIMAL(18,s+4)（最多 18 位小数）
+// MIN、MAX：与字段的类型相同
+
+const (
+	decPrecision = 18 // DECIMAL 的最大有效数字位数（参考 sql.Dec）
+	avgScale     = 4  // DECIMAL 的平均值增加的小数位数
+)
+
+// aggregate 聚合函数
+type aggregate struct {
+	fn  string
+	arg *field // 参数（COUNT(*) 时为 nil）
+	out *field // 聚合结果
+}
+
+// accum 聚合的中间结果
+//
+// SUM、AVG 时 val 为累加的值，MIN、MAX 时 val 为当前的最小（最大）值
+type accum struct {
+	count int64
+	val   any
+}
+
+// aggregate 解析聚合函数
+func (s *source) aggregate(fn, arg string) (*aggregate, error) {
+	name := sql.FuncName(fn, arg)
+	a := &aggregate{fn: fn}
+	if arg == "*" {
+		if fn != sql.FuncCount {
+			return nil, NewError(ErrAggregateArg, name)
+		}
+	} else {
+		var err error
+		a.arg, err = s.field(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	typ := sql.ColumnType{Type: sql.Int64}
+	switch fn {
+	case sql.FuncSum, sql.FuncAvg:
+		switch a.arg.typ.Type {
+		case sql.Int32, sql.Int64:
+			if fn == sql.FuncAvg {
+				typ.Type = sql.Double
+			}
+		case sql.Double:
+			typ.Type = sql.Double
+		case sql.Decimal:
+			typ = sql.ColumnType{Type: sql.Decimal, Precision: decPrecision, Scale: a.arg.typ.Scale}
+			if fn == sql.FuncAvg {
+				typ.Scale = min(typ.Scale+avgScale, decPrecision)
+			}
+		default:
+			return nil, NewError(ErrAggregateType, fn, arg, a.arg.Type)
+		}
+	case sql.FuncMin, sql.FuncMax:
+		typ = a.arg.typ
+	}
+	a.out = &field{
+		Name:     name,
+		Type:     typ.String(),
+		Nullable: true,
+		typ:      typ,
+	}
+	return a, nil
+}
+
+func (a *aggregate) add(acc *accum, row Entry) error {
+	if a.arg == nil {
+		acc.count++
+		return nil
+	}
+	v := row[a.arg.Name]
+	if v == nil {
+		return nil
+	}
+	acc.count++
+
+	switch a.fn {
+	case sql.FuncSum, sql.FuncAvg:
+		switch val := v.(type) {
+		case int32, int64:
+			sum, ok := addInt(intVal(acc.val), intVal(val))
+			if !ok {
+				return NewError(ErrAggregateRange, a.out.Name)
+			}
+			acc.val = sum
+		case float64:
+			sum, _ := acc.val.(float64)
+			acc.val = sum + val
+		case sql.Dec:
+			sum, _ := acc.val.(sql.Dec)
+			n, ok := addInt(sum.Value, val.Value)
+			if !ok {
+				return NewError(ErrAggregateRange, a.out.Name)
+			}
+			acc.val = sql.Dec{Value: n, Scale: val.Scale}
+		}
+	case sql.FuncMin:
+		if acc.val == nil || compareValue(v, acc.val) < 0 {
+			acc.val = v
+		}
+	case sql.FuncMax:
+		if acc.val == nil || compareValue(v, acc.val) > 0 {
+			acc.val = v
+		}
+	}
+	return nil
+}
+
+func (a *aggregate) result(acc *accum) (any, error) {
+	if a.fn == sql.FuncCount {
+		return acc.count, nil
+	}
+	if acc.count == 0 || a.fn != sql.FuncAvg {
+		return acc.val, nil
+	}
+
+	switch sum := acc.val.(type) {
+	case int64:
+		return float64(sum) / float64(acc.count), nil
+	case float64:
+		return sum / float64(acc.count), nil
+	case sql.Dec:
+		// 增加小数位数之后相除，远离零的方向舍入
+		scale := a.out.typ.Scale
+		n := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale-sum.Scale)), nil)
+		n.Mul(n, big.NewInt(sum.Value))
+		count := big.NewInt(acc.count)
+		q, r := new(big.Int).QuoRem(n, count, new(big.Int))
+		if r.Abs(r).Lsh(r, 1).Cmp(count) >= 0 {
+			q.Add(q, big.NewInt(int64(n.Sign())))
+		}
+		if !q.IsInt64() {
+			return nil, NewError(ErrAggregateRange, a.out.Name)
+		}
+		return sql.Dec{Value: q.Int64(), Scale: scale}, nil
+	}
+	return nil, nil
+}
+
+// addInt 整数相加，溢出时返回 false
+func addInt(a, b int64) (int64, bool) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, false
+	}
+	return a + b, true
+}
+
+// grouping 聚合查询
+type grouping struct {
+	keys   []*field          // 分组的字段
+	aggs   []*aggregate      // 聚合函数（包括只在 HAVING、ORDER BY 中使用的）
+	alias  map[string]string // 查询字段的别名对应的字段名称
+	fields []*field          // 分组之后的数据中的全部字段
+
+	groups map[string]*group
+	list   []*group // 按照分组第一次出现的顺序
+}
+
+type group struct {
+	row  Entry // 分组的字段的值
+	accs []*accum
+}
+
+// grouping 解析聚合查询，不是聚合查询时返回 nil
+func (s *source) grouping(stmt *sql.SelectStmt, cols []*column) (*grouping, error) {
+	// 查询条件在分组之前执行，不能使用聚合函数
+	for _, name := range whereFields(stmt.Where) {
+		if isFuncName(name) {
+			return nil, NewError(ErrAggregateWhere, name)
+		}
+	}
+
+	refs := whereFields(stmt.Having)
+	for _, o := range stmt.Order {
+		refs = append(refs, o.Field)
+	}
+	agg := len(stmt.Group) != 0 || len(stmt.Having) != 0
+	for _, c := range cols {
+		agg = agg || c.agg != nil
+	}
+	for _, name := range refs {
+		agg = agg || isFuncName(name)
+	}
+	if !agg {
+		return nil, nil
+	}
+
+	g := &grouping{
+		alias:  make(map[string]string),
+		groups: make(map[string]*group),
+	}
+	for _, name := range stmt.Group {
+		f, err := s.field(name)
+		if err != nil {
+			return nil, err
+		}
+		if g.field(f.Name) == nil {
+			g.keys = append(g.keys, f)
+			g.fields = append(g.fields, f)
+		}
+	}
+
+	// 查询的字段
+	for _, c := range cols {
+		switch {
+		case c.agg != nil:
+			g.addAggregate(c.agg)
+		case g.field(c.f.Name) == nil:
+			return nil, NewError(ErrNotGrouped, c.f.Name)
+		}
+	}
+	for _, c := range cols {
+		err := g.addAlias(c.name, c.f)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// HAVING 和 ORDER BY 中使用的字段（分组的字段可以使用其他的名称，例如没有限定的名称）
+	for _, name := range refs {
+		if g.field(name) != nil {
+			continue
+		}
+		if isFuncName(name) {
+			i := strings.IndexByte(name, '(')
+			a, err := s.aggregate(name[:i], name[i+1:len(name)-1])
+			if err != nil {
+				return nil, err
+			}
+			g.addAggregate(a)
+			continue
+		}
+
+		f, err := s.field(name)
+		if err != nil {
+			return nil, err
+		}
+		if g.field(f.Name) == nil {
+			return nil, NewError(ErrNotGrouped, name)
+		}
+		err = g.addAlias(name, f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// field 分组之后的数据中的字段
+func (g *grouping) field(name string) *field {
+	for _, f := range g.fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// addAlias 添加字段的别名（分组之后的数据中使用别名保存字段的值）
+func (g *grouping) addAlias(name string, f *field) error {
+	if name == f.Name {
+		return nil
+	}
+	if src, ok := g.alias[name]; ok && src == f.Name {
+		return nil
+	}
+	if g.field(name) != nil {
+		return NewError(ErrAmbiguousColumn, name)
+	}
+	g.alias[name] = f.Name
+	g.fields = append(g.fields, &field{
+		Name:     name,
+		Type:     f.Type,
+		Nullable: true,
+		typ:      f.typ,
+	})
+	return nil
+}
+
+// addAggregate 添加聚合函数（相同的聚合函数只计算一次）
+func (g *grouping) addAggregate(a *aggregate) {
+	if g.field(a.out.Name) != nil {
+		return
+	}
+	g.aggs = append(g.aggs, a)
+	g.fields = append(g.fields, a.out)
+}
+
+func (g *grouping) newGroup(row Entry) *group {
+	gr := &group{
+		row:  make(Entry, len(g.fields)),
+		accs: make([]*accum, 0, len(g.aggs)),
+	}
+	for _, f := range g.keys {
+		gr.row[f.Name] = row[f.Name]
+	}
+	for range g.aggs {
+		gr.accs = append(gr.accs, new(accum))
+	}
+	return gr
+}
+
+func (g *grouping) add(row Entry) error {
+	// 分组的字段值（与写入表中的格式相同，NULL 也可以区分）
+	key := make([]byte, 0)
+	for _, f := range g.keys {
+		key = append(key, f.wrapRaw(row[f.Name])...)
+	}
+
+	gr, ok := g.groups[string(key)]
+	if !ok {
+		gr = g.newGroup(row)
+		g.groups[string(key)] = gr
+		g.list = append(g.list, gr)
+	}
+	for i, a := range g.aggs {
+		err := a.add(gr.accs[i], row)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish 读取数据完成（没有 GROUP BY 并且没有数据时，输出一个空的分组）
+func (g *grouping) finish() {
+	if len(g.keys) == 0 && len(g.list) == 0 {
+		g.list = append(g.list, g.newGroup(nil))
+	}
+}
+
+// row 分组之后的数据（HAVING 在分组之后过滤，参考 exec.go）
+func (g *grouping) row(gr *group) (Entry, error) {
+	row := gr.row
+	for i, a := range g.aggs {
+		v, err := a.result(gr.accs[i])
+		if err != nil {
+			return nil, err
+		}
+		row[a.out.Name] = v
+	}
+	for name, src := range g.alias {
+		row[name] = row[src]
+	}
+	return row, nil
+}
+
+// whereFields 条件中使用的全部字段
+func whereFields(where []sql.SelectWhere) []string {
+	names := make([]string, 0)
+	for _, w := range where {
+		switch cond := w.(type) {
+		case *sql.SelectWhereExpr:
+			names = append(names, whereFields(cond.Cnf)...)
+		case *sql.SelectWhereField:
+			names = append(names, cond.Field)
+		case *sql.SelectWhereNull:
+			names = append(names, cond.Field)
+		}
+	}
+	return names
+}
+
+// isFuncName 是否是聚合函数的名称（字段名称中不会出现括号）
+func isFuncName(name string) bool {
+	return strings.HasSuffix(name, ")")
+}
@@ -0,0 +1,612 @@
+package table
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/ggymm/db"
+	"github.com/ggymm/db/index"
+	"github.com/ggymm/db/pkg/sql"
+)
+
+// ddl 未提交的表结构修改
+//
+// 修改表结构时，不会修改原有的表信息和字段信息，而是保存新的记录（在当前事务中写入）
+// 然后重新写入全部的表信息（表信息之间是链表结构），新的链表头保存在 head 中
+// 事务提交之后才更新 boot 中的表信息 itemId，事务回滚时恢复 tables
+//
+// 同一时间只允许一个事务修改表结构
+// 其他事务不能访问被修改的表，直到修改表结构的事务结束
+//
+// 删除和清空表时，表的数据、索引和自增序列在事务提交之后才释放（data item 的释放不受事务控制）
+// 事务回滚时，释放当前事务创建的表（包括清空之后的表）
+// 释放失败只会导致空间不能重新使用，不影响表信息的正确性
+type ddl struct {
+	tid    uint64
+	head   uint64
+	tables map[string]*table // 修改之前的表信息
+
+	records []uint64 // 被替换的表信息（提交之后释放）
+	written []uint64 // 当前事务写入的表信息（回滚之后释放）
+	dropped []*table // 删除或者清空的表（提交之后释放）
+	created []*table // 创建或者清空之后的表（回滚之后释放）
+
+	droppedTrees []index.Index // 删除的索引（提交之后释放）
+	createdTrees []index.Index // 创建的索引（回滚之后释放）
+}
+
+// beginDDL 开始修改表结构（调用前需要持有 tbm 的锁）
+func (tbm *tableManage) beginDDL(tid uint64) error {
+	if tbm.ddl != nil {
+		if tbm.ddl.tid != tid {
+			return ErrSchemaBusy
+		}
+		return nil
+	}
+	tbm.ddl = &ddl{
+		tid:    tid,
+		head:   tbm.readTableId(),
+		tables: tbm.tables.Items(),
+	}
+	return nil
+}
+
+// endDDL 结束修改表结构，提交时更新表信息的 itemId，回滚时恢复表信息
+func (tbm *tableManage) endDDL(tid uint64, commit bool) {
+	tbm.Lock()
+	defer tbm.Unlock()
+	if tbm.ddl == nil || tbm.ddl.tid != tid {
+		return
+	}
+
+	d := tbm.ddl
+	tbm.ddl = nil
+	if commit {
+		tbm.updateTableId(d.head)
+		tbm.reclaim(d.records, d.dropped, d.droppedTrees)
+	} else {
+		tbm.tables.Clear()
+		tbm.tables.MSet(d.tables)
+		tbm.reclaim(d.written, d.created, d.createdTrees)
+	}
+}
+
+// reclaim 释放不再使用的表信息、表和索引
+func (tbm *tableManage) reclaim(records []uint64, tables []*table, trees []index.Index) {
+	// 丢弃释放的索引中等待清理的索引项
+	dropped := slices.Clone(trees)
+	for _, t := range tables {
+		for _, f := range t.all {
+			if f.index != nil {
+				dropped = append(dropped, f.index)
+			}
+		}
+	}
+	tbm.purge.discard(dropped)
+
+	dm := tbm.DataManage()
+	for _, id := range records {
+		_ = dm.Free(id)
+	}
+	for _, t := range tables {
+		_ = t.drop()
+	}
+	for _, tree := range trees {
+		_ = tree.Drop()
+	}
+}
+
+// saveCatalog 重新写入全部的表信息（按照表名排序）
+func (tbm *tableManage) saveCatalog(tid uint64) error {
+	names := tbm.tables.Keys()
+	slices.Sort(names)
+
+	next := uint64(0)
+	for i := len(names) - 1; i >= 0; i-- {
+		t, _ := tbm.tables.Get(names[i])
+
+		// 复制表信息，修改之前的表信息在回滚时使用
+		nt := t.clone()
+		nt.Next = next
+		err := nt.save(tid)
+		if err != nil {
+			return err
+		}
+		tbm.tables.Set(nt.Name, nt)
+		next = nt.itemId
+
+		if t.itemId != 0 {
+			tbm.ddl.records = append(tbm.ddl.records, t.itemId)
+		}
+		tbm.ddl.written = append(tbm.ddl.written, nt.itemId)
+	}
+	tbm.ddl.head = next
+	return nil
+}
+
+// getTable 获取表信息
+//
+// 其他事务正在修改表结构时，返回 ErrSchemaBusy
+func (tbm *tableManage) getTable(tid uint64, name string) (*table, error) {
+	tbm.Lock()
+	defer tbm.Unlock()
+	return tbm.lookup(tid, name)
+}
+
+func (tbm *tableManage) lookup(tid uint64, name string) (*table, error) {
+	t, ok := tbm.tables.Get(name)
+	if tbm.ddl == nil || tbm.ddl.tid == tid {
+		if !ok {
+			return nil, ErrNoSuchTable
+		}
+		return t, nil
+	}
+
+	old, exist := tbm.ddl.tables[name]
+	switch {
+	case !ok && !exist:
+		return nil, ErrNoSuchTable
+	case ok && exist && modified(old, t):
+		return nil, ErrSchemaBusy
+	case ok != exist:
+		return nil, ErrSchemaBusy
+	}
+	return t, nil
+}
+
+// modified 表结构是否被修改（重新写入表信息时表名和字段不变）
+func modified(old, t *table) bool {
+	return old.Name != t.Name || !slices.Equal(old.all, t.all)
+}
+
+// newField 根据建表语句中的字段创建字段（没有保存字段信息）
+func (tbm *tableManage) newField(tf *sql.CreateField, indexed bool) (f *field, err error) {
+	f = new(field)
+	f.tbm = tbm
+	f.Name = tf.Name
+	f.typ = tf.Type
+	f.Type = tf.Type.String()
+	f.TreeId = 0
+	f.Nullable = tf.Nullable
+
+	// 默认值和自增序列
+	err = f.parseDefault(tf)
+	if err != nil {
+		return nil, err
+	}
+	if tf.AutoIncrement {
+		f.seq, f.SeqId, err = newSequence(tbm.DataManage())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if indexed {
+		i, err1 := index.NewIndex(tbm.DataManage(), &db.Option{
+			Open: false,
+		})
+		if err1 != nil {
+			return nil, err1
+		}
+		f.index = i
+		f.TreeId = i.GetBootId()
+	}
+	return f, nil
+}
+
+func (tbm *tableManage) Alter(tid uint64, stmt *sql.AlterStmt) (err error) {
+	tbm.Lock()
+	defer tbm.Unlock()
+
+	t, err := tbm.lookup(tid, stmt.Table)
+	if err != nil {
+		return err
+	}
+	err = tbm.beginDDL(tid)
+	if err != nil {
+		return err
+	}
+
+	nt := t.clone()
+	switch stmt.Action {
+	case sql.AddColumn:
+		err = tbm.addColumn(tid, nt, stmt.Field)
+	case sql.DropColumn:
+		err = tbm.dropColumn(tid, nt, stmt.Name)
+	case sql.RenameColumn:
+		err = tbm.renameColumn(tid, nt, stmt.Name, stmt.NewName)
+	case sql.RenameTable:
+		if tbm.tables.Has(stmt.NewName) {
+			return NewError(ErrTableExists, stmt.NewName)
+		}
+		nt.Name = stmt.NewName
+	}
+	if err != nil {
+		return err
+	}
+
+	// 更新表信息
+	tbm.tables.Remove(t.Name)
+	tbm.tables.Set(nt.Name, nt)
+	return tbm.saveCatalog(tid)
+}
+
+// addColumn 新增字段
+//
+// 新增的字段不能是自增字段，不允许为空时必须有常量默认值（已有的数据使用默认值）
+func (tbm *tableManage) addColumn(tid uint64, t *table, tf *sql.CreateField) error {
+	if t.field(tf.Name) != nil {
+		return NewError(ErrFieldExists, tf.Name)
+	}
+	if tf.AutoIncrement {
+		return NewError(ErrInvalidAutoInc, tf.Name)
+	}
+	if !tf.Nullable && (tf.Default == nil || tf.Default.Value == nil || tf.Default.Value.Null) {
+		return NewError(ErrAddNotNull, tf.Name)
+	}
+
+	f, err := tbm.newField(tf, false)
+	if err != nil {
+		return err
+	}
+	f.AddVer = t.version + 1
+	err = f.save(tid)
+	if err != nil {
+		return err
+	}
+	t.all = append(t.all, f)
+	t.init()
+	return nil
+}
+
+// dropColumn 删除字段
+//
+// 字段信息仍然保存在表信息中（用于解析之前写入的数据），字段的索引在事务提交之后释放
+// 主键、多列索引包含的字段（包括多列索引的第一个字段）和最后一个字段不能删除
+func (tbm *tableManage) dropColumn(tid uint64, t *table, name string) error {
+	f := t.field(name)
+	if f == nil {
+		return NewError(ErrNoSuchField, name)
+	}
+	if f.PrimaryKey {
+		return NewError(ErrDropPrimaryKey, name)
+	}
+	if len(t.Fields) == 1 {
+		return NewError(ErrDropLastField, name)
+	}
+	for _, o := range t.Fields {
+		if slices.Contains(o.IndexCols, name) {
+			return NewError(ErrDropIndexField, name, o.IndexName)
+		}
+	}
+
+	nf := *f
+	nf.DropVer = t.version + 1
+	if f.index != nil {
+		nf.index = nil
+		nf.TreeId = 0
+		nf.IndexName = ""
+		nf.Unique = false
+	}
+	err := nf.save(tid)
+	if err != nil {
+		return err
+	}
+	t.replace(f, &nf)
+	if f.index != nil {
+		tbm.ddl.droppedTrees = append(tbm.ddl.droppedTrees, f.index)
+	}
+	return nil
+}
+
+// renameColumn 修改字段名称（数据格式不变，不需要修改表结构版本）
+func (tbm *tableManage) renameColumn(tid uint64, t *table, name, newName string) error {
+	f := t.field(name)
+	if f == nil {
+		return NewError(ErrNoSuchField, name)
+	}
+	if t.field(newName) != nil {
+		return NewError(ErrFieldExists, newName)
+	}
+
+	nf := *f
+	nf.Name = newName
+	err := nf.save(tid)
+	if err != nil {
+		return err
+	}
+	t.replace(f, &nf)
+
+	// 更新多列索引中的字段名称
+	for _, o := range t.Fields {
+		if !slices.Contains(o.IndexCols, name) {
+			continue
+		}
+		no := *o
+		no.IndexCols = slices.Clone(o.IndexCols)
+		no.IndexCols[slices.Index(no.IndexCols, name)] = newName
+		err = no.save(tid)
+		if err != nil {
+			return err
+		}
+		t.replace(o, &no)
+	}
+	return nil
+}
+
+func (tbm *tableManage) Drop(tid uint64, stmt *sql.DropStmt) (err error) {
+	tbm.Lock()
+	defer tbm.Unlock()
+
+	t, err := tbm.lookup(tid, stmt.Table)
+	if err != nil {
+		if errors.Is(err, ErrNoSuchTable) && stmt.IfExists {
+			return nil
+		}
+		return err
+	}
+	err = tbm.beginDDL(tid)
+	if err != nil {
+		return err
+	}
+
+	// 从表信息的链表中删除
+	tbm.tables.Remove(t.Name)
+	tbm.ddl.records = append(tbm.ddl.records, t.itemId)
+	tbm.ddl.dropped = append(tbm.ddl.dropped, t)
+	return tbm.saveCatalog(tid)
+}
+
+// Truncate 清空表
+//
+// 使用新的索引和自增序列替换原有的（自增序列重新开始），已经删除的字段不再保留
+// 原有的数据、索引和自增序列在事务提交之后释放
+func (tbm *tableManage) Truncate(tid uint64, stmt *sql.TruncateStmt) (err error) {
+	tbm.Lock()
+	defer tbm.Unlock()
+
+	t, err := tbm.lookup(tid, stmt.Table)
+	if err != nil {
+		return err
+	}
+	err = tbm.beginDDL(tid)
+	if err != nil {
+		return err
+	}
+
+	nt := t.clone()
+	nt.stats = nil
+	nt.StatsId = 0
+	nt.HeapId, err = tbm.DataManage().NewHeap()
+	if err != nil {
+		return err
+	}
+	tbm.ddl.created = append(tbm.ddl.created, nt)
+
+	nt.all = make([]*field, 0, len(t.Fields))
+	for _, f := range t.Fields {
+		nf := *f
+		nf.AddVer = 0
+		if f.TreeId != 0 {
+			nf.index, err = index.NewIndex(tbm.DataManage(), &db.Option{
+				Open: false,
+			})
+			if err != nil {
+				return err
+			}
+			nf.TreeId = nf.index.GetBootId()
+		}
+		if f.SeqId != 0 {
+			nf.seq, nf.SeqId, err = newSequence(tbm.DataManage())
+			if err != nil {
+				return err
+			}
+		}
+		err = nf.save(tid)
+		if err != nil {
+			return err
+		}
+		nt.all = append(nt.all, &nf)
+	}
+	nt.init()
+
+	// 更新表信息
+	tbm.tables.Set(nt.Name, nt)
+	tbm.ddl.dropped = append(tbm.ddl.dropped, t)
+	return tbm.saveCatalog(tid)
+}
+
+// CreateIndex 创建索引
+//
+// 每个字段只能有一个索引（多列索引属于第一个字段），创建之后使用表中的数据填充索引
+// 填充的数据包括当前事务可见的数据和已经提交的数据（查询时会再次判断数据是否可见）
+func (tbm *tableManage) CreateIndex(tid uint64, stmt *sql.CreateIndexStmt) (err error) {
+	tbm.Lock()
+	defer tbm.Unlock()
+
+	t, err := tbm.lookup(tid, stmt.Table)
+	if err != nil {
+		return err
+	}
+	if t.index(stmt.Name) != nil {
+		return NewError(ErrIndexExists, stmt.Name)
+	}
+	err = checkIndexFields(stmt.Field, func(name string) bool {
+		return t.field(name) != nil
+	})
+	if err != nil {
+		return err
+	}
+	f := t.field(stmt.Field[0])
+	if f.TreeId != 0 {
+		return NewError(ErrFieldIndexed, f.Name)
+	}
+	err = tbm.beginDDL(tid)
+	if err != nil {
+		return err
+	}
+
+	nf := *f
+	nf.index, err = index.NewIndex(tbm.DataManage(), &db.Option{
+		Open: false,
+	})
+	if err != nil {
+		return err
+	}
+	nf.TreeId = nf.index.GetBootId()
+	nf.IndexName = stmt.Name
+	nf.IndexCols = nil
+	if len(stmt.Field) > 1 {
+		nf.IndexCols = stmt.Field
+	}
+	nf.Unique = stmt.Unique
+	tbm.ddl.createdTrees = append(tbm.ddl.createdTrees, nf.index)
+
+	// 填充索引
+	err = tbm.backfill(tid, t, &nf)
+	if err != nil {
+		return err
+	}
+
+	// 保存字段信息
+	err = nf.save(tid)
+	if err != nil {
+		return err
+	}
+	nt := t.clone()
+	nt.replace(f, &nf)
+
+	// 更新表信息
+	tbm.tables.Set(nt.Name, nt)
+	return tbm.saveCatalog(tid)
+}
+
+// checkIndexFields 检查索引的字段是否存在，并且没有重复
+func checkIndexFields(names []string, exist func(name string) bool) error {
+	for i, name := range names {
+		if !exist(name) {
+			return NewError(ErrNoSuchField, name)
+		}
+		if slices.Contains(names[:i], name) {
+			return NewError(ErrIndexFieldRepeat, name)
+		}
+	}
+	return nil
+}
+
+// backfill 使用表中的数据填充字段的索引（唯一索引时检查是否有重复的值）
+//
+// 索引全部的数据版本（包括其他事务写入但是没有提交的数据），读取索引时会重新判断数据的可见性
+// 否则修改表结构时没有提交的数据，在提交之后不能通过索引读取
+// 检查唯一约束时只比较数据的最新版本（参考 checkUnique）
+func (tbm *tableManage) backfill(tid uint64, t *table, f *field) error {
+	scan := &scanOp{rowReader: rowReader{tbm: tbm, tid: tid, t: t}}
+	defer scan.Close()
+	err := scan.Open()
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]bool)
+	for {
+		rid, ok, err := scan.nextRid()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		raw, ok, err := tbm.verManage.ReadAny(rid)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		row := t.wrapEntry(raw, nil)
+		key := t.indexKey(f, row)
+		if key == nil {
+			continue
+		}
+		err = f.index.Insert(key, rid)
+		if err != nil {
+			return err
+		}
+		uk := t.uniqueKey(f, row)
+		if !f.Unique || uk == nil {
+			continue
+		}
+
+		_, ok, err = tbm.verManage.ReadLatest(tid, rid)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if keys[string(uk)] {
+			return &DuplicateKeyError{Index: f.IndexName, Value: t.indexValue(f, row)}
+		}
+		keys[string(uk)] = true
+	}
+}
+
+// DropIndex 删除索引（不能删除主键索引）
+//
+// 没有指定表时，在全部的表中查找索引
+func (tbm *tableManage) DropIndex(tid uint64, stmt *sql.DropIndexStmt) (err error) {
+	tbm.Lock()
+	defer tbm.Unlock()
+
+	names := []string{stmt.Table}
+	if stmt.Table == "" {
+		names = tbm.tables.Keys()
+		slices.Sort(names)
+	}
+
+	var (
+		t *table
+		f *field
+	)
+	for _, name := range names {
+		it, err1 := tbm.lookup(tid, name)
+		if err1 != nil {
+			return err1
+		}
+		if i := it.index(stmt.Name); i != nil {
+			if f != nil {
+				return NewError(ErrAmbiguousIndex, stmt.Name)
+			}
+			t, f = it, i
+		}
+	}
+	if f == nil {
+		return NewError(ErrNoSuchIndex, stmt.Name)
+	}
+	if f.PrimaryKey {
+		return NewError(ErrDropPrimaryKey, f.Name)
+	}
+	err = tbm.beginDDL(tid)
+	if err != nil {
+		return err
+	}
+
+	nf := *f
+	nf.index = nil
+	nf.TreeId = 0
+	nf.IndexName = ""
+	nf.IndexCols = nil
+	nf.Unique = false
+	err = nf.save(tid)
+	if err != nil {
+		return err
+	}
+	nt := t.clone()
+	nt.replace(f, &nf)
+
+	// 更新表信息
+	tbm.tables.Set(nt.Name, nt)
+	tbm.ddl.droppedTrees = append(tbm.ddl.droppedTrees, f.index)
+	return tbm.saveCatalog(tid)
+}
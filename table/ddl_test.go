@@ -0,0 +1,96 @@
+package table
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/ggymm/db"
+	"github.com/ggymm/db/boot"
+	"github.com/ggymm/db/data"
+	"github.com/ggymm/db/pkg/sql"
+	"github.com/ggymm/db/tx"
+	"github.com/ggymm/db/ver"
+)
+
+func TestTableManage_DropColumn(t *testing.T) {
+	opt := db.NewOption(db.RunPath(), "temp/table_ddl")
+	opt.Memory = (1 << 20) * 64
+	err := os.RemoveAll(opt.Path)
+	if err != nil {
+		t.Fatalf("remove err %v", err)
+	}
+	tm := tx.NewManager(opt)
+	dm := data.NewManage(tm, opt)
+	defer tm.Close()
+	defer dm.Close()
+	tbm := NewManage(boot.New(opt), ver.NewManage(tm, dm), dm).(*tableManage)
+
+	exec := func(tid uint64, in string) error {
+		stmt, err := sql.ParseSQL(in)
+		if err != nil {
+			t.Fatalf("parse %s err %v", in, err)
+		}
+		switch s := stmt.(type) {
+		case *sql.CreateStmt:
+			return tbm.Create(tid, s)
+		case *sql.CreateIndexStmt:
+			return tbm.CreateIndex(tid, s)
+		case *sql.AlterStmt:
+			return tbm.Alter(tid, s)
+		}
+		t.Fatalf("unexpected statement %s", in)
+		return nil
+	}
+	mustExec := func(in string) {
+		tid := tbm.Begin(1)
+		err := exec(tid, in)
+		if err != nil {
+			t.Fatalf("%s err %v", in, err)
+		}
+		err = tbm.Commit(tid)
+		if err != nil {
+			t.Fatalf("%s commit err %v", in, err)
+		}
+	}
+
+	mustExec("CREATE TABLE user (id INT64, name VARCHAR, age INT32, city INT32, PRIMARY KEY (id));")
+	mustExec("CREATE INDEX name_idx ON user (name);")
+	mustExec("CREATE INDEX city_age_idx ON user (city, age);")
+
+	// 多列索引包含的字段（包括第一个字段）不能删除
+	for _, name := range []string{"age", "city"} {
+		tid := tbm.Begin(1)
+		err = exec(tid, "ALTER TABLE user DROP COLUMN "+name+";")
+		tbm.Rollback(tid)
+		if err == nil || err.Error() != fmt.Sprintf(ErrDropIndexField, name, "city_age_idx") {
+			t.Fatalf("drop %s err %v", name, err)
+		}
+	}
+
+	// 删除有索引的字段，索引在事务提交之后释放
+	tid := tbm.Begin(1)
+	tb, _ := tbm.getTable(tid, "user")
+	tree := tb.field("name").index
+	err = exec(tid, "ALTER TABLE user DROP COLUMN name;")
+	if err != nil {
+		t.Fatalf("drop name err %v", err)
+	}
+	if !slices.Contains(tbm.ddl.droppedTrees, tree) {
+		t.Fatalf("index of dropped field not freed")
+	}
+	err = tbm.Commit(tid)
+	if err != nil {
+		t.Fatalf("commit err %v", err)
+	}
+	tb, _ = tbm.getTable(tid, "user")
+	if tb.index("name_idx") != nil {
+		t.Fatalf("index of dropped field still exists")
+	}
+	for _, f := range tb.all {
+		if f.Name == "name" && (f.TreeId != 0 || f.index != nil) {
+			t.Fatalf("dropped field keeps tree %d", f.TreeId)
+		}
+	}
+}
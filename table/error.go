@@ -9,14 +9,51 @@ var (
 	ErrNoSuchTable       = NewError("no such table")
 	ErrNoPrimaryKey      = NewError("no primary key")
 	ErrMustHaveCondition = NewError("must have condition")
+	ErrNoSuchSequence    = NewError("no such sequence")
+	ErrSchemaBusy        = NewError("table is being altered by another transaction")
 
 	ErrInsertNotMatch = errors.New("mismatch between number of fields and values")
 )
 
 const (
-	ErrNotAllowNull = "field %s is not allowed to be null"
+	ErrNotAllowNull      = "field %s is not allowed to be null"
+	ErrInvalidDefault    = "invalid default value for field %s"
+	ErrInvalidAutoInc    = "field %s cannot be auto increment"
+	ErrSequenceExhausted = "sequence of field %s is exhausted"
+	ErrTableExists       = "table %s already exists"
+	ErrNoSuchField       = "no such field %s"
+	ErrFieldExists       = "field %s already exists"
+	ErrDropPrimaryKey    = "cannot drop primary key field %s"
+	ErrDropLastField     = "cannot drop the last field %s"
+	ErrAddNotNull        = "field %s must have a default value to be added as not null"
+	ErrNoSuchIndex       = "no such index %s"
+	ErrIndexExists       = "index %s already exists"
+	ErrAmbiguousIndex    = "index %s exists in more than one table"
+	ErrFieldIndexed      = "field %s already has an index"
+	ErrIndexFieldRepeat  = "field %s appears more than once in the index"
+	ErrDropIndexField    = "cannot drop field %s used by index %s"
+	ErrAmbiguousColumn   = "column %s is ambiguous in select list"
+	ErrAliasAll          = "cannot use alias %s for *"
+	ErrAggregateArg      = "invalid argument for %s"
+	ErrAggregateType     = "cannot apply %s to field %s of type %s"
+	ErrAggregateRange    = "%s is out of range"
+	ErrAggregateWhere    = "aggregate %s is not allowed in WHERE"
+	ErrNotGrouped        = "field %s must appear in GROUP BY or be used in an aggregate function"
+	ErrDuplicateAlias    = "table alias %s is not unique"
+	ErrJoinField         = "field %s cannot be used in join condition of %s"
+	ErrJoinLiteral       = "join condition of %s must compare two columns"
 )
 
+// DuplicateKeyError 违反唯一约束（主键或者唯一索引）
+type DuplicateKeyError struct {
+	Index string // 索引名称（主键索引为 PRIMARY）
+	Value any
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("duplicate value %v for unique index %s", e.Value, e.Index)
+}
+
 func NewError(msg string, args ...any) error {
 	if len(args) == 0 {
 		return errors.New(msg)
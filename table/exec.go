@@ -0,0 +1,511 @@
+package table
+
+import (
+	"github.com/ggymm/db/index"
+	"github.com/ggymm/db/pkg/sql"
+)
+
+// 执行器
+//
+// 查询按照火山模型执行：查询被组织为算子树，上层的算子通过 Next 从下层的算子逐条拉取数据
+// 数据逐条流过算子树，只有排序、聚合和哈希连接需要缓存数据（排序的数据过多时写入临时文件，参考 sort.go）
+// LIMIT 读取到足够的数据之后不再拉取，下层的算子不会读取多余的数据
+//
+// 单表查询的算子树：
+// Project <- Limit <- Sort <- [Filter(HAVING) <- Aggregate] <- Filter(WHERE) <- Scan 或者 IndexScan
+// 连接查询时，读取第一个表的算子之上依次是每个连接的表的 Join 算子，之后是过滤连接之后的数据的 Filter（参考 join.go）
+//
+// 算子：
+// Scan：遍历主键索引（没有主键时遍历堆），读取全部数据
+// IndexScan：遍历索引中满足条件的区间，索引合并时取交集（参考 plan.go）
+// Filter：过滤不满足条件的数据
+// Project：只保留查询的字段（参考 project.go）
+// Sort：排序
+// Limit：跳过 OFFSET 条数据，返回 LIMIT 条数据
+// Aggregate：分组并计算聚合函数（参考 aggregate.go）
+// Join：连接左边的数据和一个表（参考 join.go）
+//
+// 更新和删除同样通过 Scan 或者 IndexScan 读取数据，读取一条处理一条
+
+// operator 算子
+//
+// Open 之后依次调用 Next 读取数据，Next 返回 nil 时没有更多的数据
+// Close 释放资源（包括下层的算子），可以在读取完成之前调用
+type operator interface {
+	Open() error
+	Next() (Entry, error)
+	Close()
+}
+
+// drain 执行算子树，返回全部数据
+func drain(op operator) ([]Entry, error) {
+	defer op.Close()
+	err := op.Open()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]Entry, 0)
+	for {
+		row, err := op.Next()
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			return rows, nil
+		}
+		rows = append(rows, row)
+	}
+}
+
+// matchWhere 数据是否满足全部条件
+func matchWhere(where []sql.SelectWhere, row Entry) bool {
+	for _, w := range where {
+		if !w.Match(row) {
+			return false
+		}
+	}
+	return true
+}
+
+// accessOp 读取表中数据的算子（Scan、IndexScan），rid 为最后返回的数据的 rid
+type accessOp interface {
+	operator
+	rid() uint64
+}
+
+// access 选择访问路径，返回读取表中数据的算子（不过滤数据）
+func (t *table) access(tbm *tableManage, tid uint64, where []sql.SelectWhere) (accessOp, error) {
+	p, err := t.plan(where)
+	if err != nil {
+		return nil, err
+	}
+	r := rowReader{tbm: tbm, tid: tid, t: t}
+	if len(p.scans) == 0 {
+		return &scanOp{rowReader: r}, nil
+	}
+	return &indexScanOp{rowReader: r, scans: p.scans}, nil
+}
+
+// orderedAccess 按照 ORDER BY 的字段的顺序读取数据，不能使用索引的顺序时 sorted 为 false
+//
+// ORDER BY 只有一个字段，字段有单列索引，索引键与字段值的顺序相同（字符串的索引键可能被截断，不能使用），并且：
+// 访问路径是全表扫描时，字段不允许为空（索引中没有 NULL），改为遍历该字段的全部索引
+// 访问路径是索引扫描时，第一个索引是该字段的索引（满足条件的数据不会是 NULL）
+// 降序时反向遍历索引，不需要排序，LIMIT 读取到足够的数据之后停止读取
+func (t *table) orderedAccess(tbm *tableManage, tid uint64, where []sql.SelectWhere, order []*sql.SelectOrder) (accessOp, bool, error) {
+	scan, err := t.access(tbm, tid, where)
+	if err != nil || len(order) != 1 {
+		return scan, false, err
+	}
+	f := t.field(order[0].Field)
+	if f == nil || f.index == nil || len(f.IndexCols) != 0 || f.typ.Type == sql.Varchar {
+		return scan, false, nil
+	}
+
+	switch op := scan.(type) {
+	case *scanOp:
+		if f.Nullable {
+			return scan, false, nil
+		}
+		return &indexScanOp{
+			rowReader: op.rowReader,
+			scans:     []*indexScan{{f: f, rs: []*Interval{{Min: index.MinKey(), Max: index.MaxKey()}}}},
+			desc:      !order[0].Asc,
+		}, true, nil
+	case *indexScanOp:
+		if op.scans[0].f != f {
+			return scan, false, nil
+		}
+		op.scans[0].rs = newExplain().format(op.scans[0].rs)
+		op.desc = !order[0].Asc
+		return op, true, nil
+	}
+	return scan, false, nil
+}
+
+// rowReader 根据 rid 读取当前事务可见的数据
+type rowReader struct {
+	tbm *tableManage
+	tid uint64
+	t   *table
+	cur uint64 // 最后读取的数据的 rid
+}
+
+// read 读取数据，数据对当前事务不可见时返回 nil
+func (r *rowReader) read(rid uint64) (Entry, error) {
+	raw, ok, err := r.tbm.verManage.Read(r.tid, rid)
+	if err != nil || !ok {
+		return nil, err
+	}
+	r.cur = rid
+	return r.t.wrapEntry(raw, nil), nil
+}
+
+func (r *rowReader) rid() uint64 {
+	return r.cur
+}
+
+// scanOp 全表扫描
+//
+// 遍历主键索引（数据按照主键排序），没有主键时遍历堆（每次读取一个页面中的 rid）
+type scanOp struct {
+	rowReader
+	it   index.Iterator
+	heap bool
+	page int      // 堆中下一个页面
+	rids []uint64 // 当前页面中没有读取的 rid
+}
+
+func (op *scanOp) Open() error {
+	for _, f := range op.t.Fields {
+		if f.PrimaryKey && f.index != nil {
+			op.it = f.index.Iterator(index.Bound{}, index.Bound{})
+			return nil
+		}
+	}
+	if op.t.HeapId != 0 {
+		op.heap = true
+		return nil
+	}
+	return ErrNoPrimaryKey
+}
+
+// nextRid 下一个 rid，没有更多的数据时返回 false
+func (op *scanOp) nextRid() (uint64, bool, error) {
+	if op.it != nil {
+		if !op.it.Next() {
+			return 0, false, op.it.Err()
+		}
+		return op.it.ItemId(), true, nil
+	}
+	for len(op.rids) == 0 {
+		if !op.heap {
+			return 0, false, nil
+		}
+		rids, ok, err := op.tbm.DataManage().ScanHeap(op.t.HeapId, op.page)
+		if err != nil || !ok {
+			return 0, false, err
+		}
+		op.page++
+		op.rids = rids
+	}
+	rid := op.rids[0]
+	op.rids = op.rids[1:]
+	return rid, true, nil
+}
+
+func (op *scanOp) Next() (Entry, error) {
+	for {
+		rid, ok, err := op.nextRid()
+		if err != nil || !ok {
+			return nil, err
+		}
+		row, err := op.read(rid)
+		if err != nil || row != nil {
+			return row, err
+		}
+	}
+}
+
+func (op *scanOp) Close() {
+	if op.it != nil {
+		op.it.Close()
+	}
+	op.rids = nil
+}
+
+// indexScanOp 索引扫描
+//
+// 依次遍历第一个索引中的区间（区间按照顺序排列，desc 为 true 时反向遍历），数据按照第一个索引的顺序读取
+// 索引合并时，其他的索引在打开时读取全部的 rid，用于取交集
+type indexScanOp struct {
+	rowReader
+	scans []*indexScan
+	sets  []map[uint64]bool // 其他的索引中的 rid
+	desc  bool
+
+	pos int // 下一个区间
+	it  index.Iterator
+}
+
+func (op *indexScanOp) Open() error {
+	for _, s := range op.scans[1:] {
+		set := make(map[uint64]bool)
+		for _, r := range s.rs {
+			rids, err := s.f.index.SearchRange(r.Min, r.Max)
+			if err != nil {
+				return err
+			}
+			for _, rid := range rids {
+				set[rid] = true
+			}
+		}
+		op.sets = append(op.sets, set)
+	}
+	return nil
+}
+
+// nextRid 下一个在全部索引中都存在的 rid，没有更多的数据时返回 false
+func (op *indexScanOp) nextRid() (uint64, bool, error) {
+	s := op.scans[0]
+	for {
+		if op.it == nil {
+			if op.pos == len(s.rs) {
+				return 0, false, nil
+			}
+			r := s.rs[op.pos]
+			if op.desc {
+				r = s.rs[len(s.rs)-1-op.pos]
+			}
+			op.pos++
+			op.it = s.f.index.Iterator(index.Bound{Key: r.Min}, index.Bound{Key: r.Max})
+		}
+		ok := false
+		if op.desc {
+			ok = op.it.Prev()
+		} else {
+			ok = op.it.Next()
+		}
+		if !ok {
+			err := op.it.Err()
+			op.it.Close()
+			op.it = nil
+			if err != nil {
+				return 0, false, err
+			}
+			continue
+		}
+
+		rid := op.it.ItemId()
+		ok = true
+		for _, set := range op.sets {
+			ok = ok && set[rid]
+		}
+		if ok {
+			return rid, true, nil
+		}
+	}
+}
+
+func (op *indexScanOp) Next() (Entry, error) {
+	for {
+		rid, ok, err := op.nextRid()
+		if err != nil || !ok {
+			return nil, err
+		}
+		row, err := op.read(rid)
+		if err != nil || row != nil {
+			return row, err
+		}
+	}
+}
+
+func (op *indexScanOp) Close() {
+	if op.it != nil {
+		op.it.Close()
+		op.it = nil
+	}
+	op.pos = len(op.scans[0].rs)
+	op.sets = nil
+}
+
+// filterOp 过滤不满足条件的数据
+type filterOp struct {
+	child operator
+	where []sql.SelectWhere
+}
+
+func (op *filterOp) Open() error {
+	return op.child.Open()
+}
+
+func (op *filterOp) Next() (Entry, error) {
+	for {
+		row, err := op.child.Next()
+		if err != nil || row == nil {
+			return nil, err
+		}
+		if matchWhere(op.where, row) {
+			return row, nil
+		}
+	}
+}
+
+func (op *filterOp) Close() {
+	op.child.Close()
+}
+
+// projectOp 只保留查询的字段
+type projectOp struct {
+	child operator
+	cols  []*column
+}
+
+func (op *projectOp) Open() error {
+	return op.child.Open()
+}
+
+func (op *projectOp) Next() (Entry, error) {
+	row, err := op.child.Next()
+	if err != nil || row == nil {
+		return nil, err
+	}
+	nr := make(Entry, len(op.cols))
+	for _, c := range op.cols {
+		nr[c.name] = row[c.f.Name]
+	}
+	return nr, nil
+}
+
+func (op *projectOp) Close() {
+	op.child.Close()
+}
+
+// limitOp 跳过 offset 条数据，返回 limit 条数据
+type limitOp struct {
+	child  operator
+	offset int
+	limit  int
+	n      int // 已经返回的数据量
+}
+
+// newLimitOp 没有 LIMIT 时返回 child
+func newLimitOp(child operator, limit *sql.SelectLimit) operator {
+	if limit == nil {
+		return child
+	}
+	return &limitOp{
+		child:  child,
+		offset: max(limit.Offset, 0),
+		limit:  max(limit.Limit, 0),
+	}
+}
+
+func (op *limitOp) Open() error {
+	return op.child.Open()
+}
+
+func (op *limitOp) Next() (Entry, error) {
+	if op.n >= op.limit {
+		return nil, nil
+	}
+	for ; op.offset > 0; op.offset-- {
+		row, err := op.child.Next()
+		if err != nil || row == nil {
+			return nil, err
+		}
+	}
+	row, err := op.child.Next()
+	if err != nil || row == nil {
+		return nil, err
+	}
+	op.n++
+	return row, nil
+}
+
+func (op *limitOp) Close() {
+	op.child.Close()
+}
+
+// sortOp 排序
+//
+// 第一次调用 Next 时读取下层的全部数据
+type sortOp struct {
+	child  operator
+	s      *sorter
+	sorted bool
+}
+
+// newSortOp 排序 fields 中的数据，只需要 OFFSET + LIMIT 条数据
+func newSortOp(child operator, fields []*field, order []*sql.SelectOrder, limit *sql.SelectLimit) (*sortOp, error) {
+	n := -1
+	if limit != nil {
+		n = max(limit.Offset, 0) + max(limit.Limit, 0)
+	}
+	s, err := newSorter(fields, order, n)
+	if err != nil {
+		return nil, err
+	}
+	return &sortOp{child: child, s: s}, nil
+}
+
+func (op *sortOp) Open() error {
+	return op.child.Open()
+}
+
+func (op *sortOp) Next() (Entry, error) {
+	if !op.sorted {
+		for {
+			row, err := op.child.Next()
+			if err != nil {
+				return nil, err
+			}
+			if row == nil {
+				break
+			}
+			err = op.s.add(row)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err := op.s.sort()
+		if err != nil {
+			return nil, err
+		}
+		op.sorted = true
+	}
+	return op.s.next()
+}
+
+func (op *sortOp) Close() {
+	op.child.Close()
+	op.s.close()
+}
+
+// aggregateOp 分组并计算聚合函数
+//
+// 第一次调用 Next 时读取下层的全部数据，之后每次返回一个分组
+type aggregateOp struct {
+	child   operator
+	g       *grouping
+	grouped bool
+	pos     int
+}
+
+func (op *aggregateOp) Open() error {
+	return op.child.Open()
+}
+
+func (op *aggregateOp) Next() (Entry, error) {
+	g := op.g
+	if !op.grouped {
+		for {
+			row, err := op.child.Next()
+			if err != nil {
+				return nil, err
+			}
+			if row == nil {
+				break
+			}
+			err = g.add(row)
+			if err != nil {
+				return nil, err
+			}
+		}
+		g.finish()
+		op.grouped = true
+	}
+
+	if op.pos == len(g.list) {
+		return nil, nil
+	}
+	gr := g.list[op.pos]
+	g.list[op.pos] = nil
+	op.pos++
+	return g.row(gr)
+}
+
+func (op *aggregateOp) Close() {
+	op.child.Close()
+}
@@ -0,0 +1,197 @@
+package table
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/ggymm/db"
+	"github.com/ggymm/db/boot"
+	"github.com/ggymm/db/data"
+	"github.com/ggymm/db/pkg/sql"
+	"github.com/ggymm/db/tx"
+	"github.com/ggymm/db/ver"
+)
+
+// rowsOp 返回内存中的数据，pulled 为读取的数据量
+type rowsOp struct {
+	rows   []Entry
+	pulled int
+	closed bool
+}
+
+func (op *rowsOp) Open() error {
+	return nil
+}
+
+func (op *rowsOp) Next() (Entry, error) {
+	if op.pulled == len(op.rows) {
+		return nil, nil
+	}
+	op.pulled++
+	return op.rows[op.pulled-1], nil
+}
+
+func (op *rowsOp) Close() {
+	op.closed = true
+}
+
+func TestOperator(t *testing.T) {
+	rows := make([]Entry, 0)
+	for i := 0; i < 100; i++ {
+		rows = append(rows, Entry{"id": int64(i), "odd": i%2 == 1})
+	}
+	odd := []sql.SelectWhere{&sql.SelectWhereField{Field: "odd", Operate: sql.EQ, Value: &sql.Value{Str: "true"}}}
+	id := &field{Name: "id", typ: sql.ColumnType{Type: sql.Int64}}
+
+	// LIMIT 读取到足够的数据之后不再拉取
+	src := &rowsOp{rows: rows}
+	op := &projectOp{
+		child: newLimitOp(&filterOp{child: src, where: odd}, &sql.SelectLimit{Offset: 2, Limit: 3}),
+		cols:  []*column{{name: "n", f: id}},
+	}
+	got, err := drain(op)
+	if err != nil {
+		t.Fatalf("drain err %v", err)
+	}
+	if fmt.Sprint(got) != "[map[n:5] map[n:7] map[n:9]]" {
+		t.Fatalf("got %v", got)
+	}
+	if src.pulled != 10 || !src.closed {
+		t.Fatalf("pulled %d rows, closed %v", src.pulled, src.closed)
+	}
+
+	// LIMIT 0 时不读取数据
+	src = &rowsOp{rows: rows}
+	sop, err := newSortOp(src, []*field{id}, []*sql.SelectOrder{{Field: "id"}}, &sql.SelectLimit{Limit: 0})
+	if err != nil {
+		t.Fatalf("new sort err %v", err)
+	}
+	got, err = drain(newLimitOp(sop, &sql.SelectLimit{Limit: 0}))
+	if err != nil || len(got) != 0 || src.pulled != 0 {
+		t.Fatalf("got %v, pulled %d, err %v", got, src.pulled, err)
+	}
+
+	// 没有 GROUP BY 并且没有数据时输出一个分组
+	s := &source{fields: []*field{id}, names: map[string]*field{"id": id}}
+	a, err := s.aggregate(sql.FuncCount, "*")
+	if err != nil {
+		t.Fatalf("aggregate err %v", err)
+	}
+	g := &grouping{groups: make(map[string]*group)}
+	g.addAggregate(a)
+	got, err = drain(&aggregateOp{child: &rowsOp{}, g: g})
+	if err != nil || fmt.Sprint(got) != "[map[COUNT(*):0]]" {
+		t.Fatalf("got %v, err %v", got, err)
+	}
+}
+
+func TestTableManage_Exec(t *testing.T) {
+	opt := db.NewOption(db.RunPath(), "temp/table_exec")
+	opt.Memory = (1 << 20) * 64
+	err := os.RemoveAll(opt.Path)
+	if err != nil {
+		t.Fatalf("remove err %v", err)
+	}
+	tm := tx.NewManager(opt)
+	dm := data.NewManage(tm, opt)
+	defer tm.Close()
+	defer dm.Close()
+	tbm := NewManage(boot.New(opt), ver.NewManage(tm, dm), dm).(*tableManage)
+
+	parse := func(in string) sql.Statement {
+		stmt, err := sql.ParseSQL(in)
+		if err != nil {
+			t.Fatalf("parse %s err %v", in, err)
+		}
+		return stmt
+	}
+
+	tid := tbm.Begin(1)
+	defer tbm.Rollback(tid)
+	err = tbm.Create(tid, parse("CREATE TABLE item (id INT64, score INT64, PRIMARY KEY (id), INDEX score_idx (score));").(*sql.CreateStmt))
+	if err != nil {
+		t.Fatalf("create err %v", err)
+	}
+	for i := 0; i < 50; i++ {
+		_, err = tbm.Insert(tid, parse(fmt.Sprintf("INSERT INTO item (id, score) VALUE (%d, %d);", i, i%10)).(*sql.InsertStmt))
+		if err != nil {
+			t.Fatalf("insert err %v", err)
+		}
+	}
+
+	// 访问路径
+	for in, index := range map[string]bool{
+		"SELECT * FROM item;":                 false,
+		"SELECT * FROM item WHERE score = 3;": true,
+	} {
+		stmt := parse(in).(*sql.SelectStmt)
+		t0, _ := tbm.getTable(tid, "item")
+		scan, err := t0.access(tbm, tid, stmt.Where)
+		if err != nil {
+			t.Fatalf("%s: access err %v", in, err)
+		}
+		if _, ok := scan.(*indexScanOp); ok != index {
+			t.Fatalf("%s: index scan %v, want %v", in, ok, index)
+		}
+	}
+
+	// 按照索引的顺序读取数据时不需要排序
+	for in, want := range map[string]struct {
+		sorted bool
+		rows   string
+	}{
+		"SELECT id FROM item ORDER BY id DESC LIMIT 3;":                               {true, "[map[id:49] map[id:48] map[id:47]]"},
+		"SELECT id FROM item ORDER BY id LIMIT 2 OFFSET 1;":                           {true, "[map[id:1] map[id:2]]"},
+		"SELECT score FROM item WHERE score >= 8 ORDER BY score DESC LIMIT 6;":        {true, "[map[score:9] map[score:9] map[score:9] map[score:9] map[score:9] map[score:8]]"},
+		"SELECT score FROM item WHERE score < 2 OR score > 8 ORDER BY score LIMIT 6;": {true, "[map[score:0] map[score:0] map[score:0] map[score:0] map[score:0] map[score:1]]"},
+		"SELECT score FROM item ORDER BY score DESC LIMIT 1;":                         {false, "[map[score:9]]"},
+		"SELECT id FROM item WHERE score = 3 ORDER BY id DESC LIMIT 1;":               {false, "[map[id:43]]"},
+	} {
+		stmt := parse(in).(*sql.SelectStmt)
+		op, err := tbm.selectOp(tid, stmt)
+		if err != nil {
+			t.Fatalf("%s: select err %v", in, err)
+		}
+		_, sorting := op.(*projectOp).child.(*limitOp).child.(*sortOp)
+		if sorting == want.sorted {
+			t.Fatalf("%s: sorted %v, want %v", in, !sorting, want.sorted)
+		}
+		rows, err := drain(op)
+		if err != nil || fmt.Sprint(rows) != want.rows {
+			t.Fatalf("%s: got %v, want %s, err %v", in, rows, want.rows, err)
+		}
+	}
+
+	// 查询数据时同时返回字段信息
+	cols, rows, err := tbm.Query(tid, parse("SELECT id AS n, score FROM item WHERE id = 13;").(*sql.SelectStmt))
+	if err != nil || fmt.Sprint(rows) != "[map[n:13 score:3]]" {
+		t.Fatalf("query got %v, err %v", rows, err)
+	}
+	if len(cols) != 2 || cols[0].Name != "n" || cols[0].Type != "INT64" || cols[1].Name != "score" {
+		t.Fatalf("query cols %v %v", cols[0], cols[1])
+	}
+
+	// 更新索引字段时，新版本的数据不会被再次读取
+	n, err := tbm.Update(tid, parse("UPDATE item SET score = 100 WHERE score >= 5;").(*sql.UpdateStmt))
+	if err != nil || n != 25 {
+		t.Fatalf("update %d rows, err %v", n, err)
+	}
+	n, err = tbm.Delete(tid, parse("DELETE FROM item WHERE score = 100 AND id < 20;").(*sql.DeleteStmt))
+	if err != nil || n != 10 {
+		t.Fatalf("delete %d rows, err %v", n, err)
+	}
+
+	for in, want := range map[string]int{
+		"SELECT * FROM item WHERE score = 100;":                    15,
+		"SELECT * FROM item ORDER BY id DESC LIMIT 5 OFFSET 3;":    5,
+		"SELECT score, COUNT(*) FROM item GROUP BY score;":         6,
+		"SELECT * FROM item a JOIN item b ON a.score = b.score;":   5*25 + 15*15,
+		"SELECT * FROM item WHERE score < 5 ORDER BY id LIMIT 30;": 25,
+	} {
+		rows, err := tbm.Select(tid, parse(in).(*sql.SelectStmt))
+		if err != nil || len(rows) != want {
+			t.Fatalf("%s: got %d rows, want %d, err %v", in, len(rows), want, err)
+		}
+	}
+}
@@ -1,19 +1,31 @@
 package table
 
 import (
-	"cmp"
+	"bytes"
 	"errors"
 	"fmt"
-	"math"
 	"slices"
 
-	"github.com/ggymm/db/pkg/hash"
+	"github.com/ggymm/db/index"
 	"github.com/ggymm/db/pkg/sql"
 )
 
 // 索引搜索条件
 // 索引条件与其他条件为 and 关系
 // 索引条件中不能含有非当前索引字段的条件
+//
+// 索引键是保持顺序的字节数组（参考 index/key.go），字符串可能被截断
+// 因此区间的端点全部为闭区间，区间内的数据需要使用完整的字段值再次过滤
+//
+// 同样因为截断，区间无法精确取反
+// 处理取反条件时，使用 !(A AND B) == !A OR !B 将取反下推到字段条件
+//
+// NULL 不会写入索引，与 NULL 比较的结果不可能为真，因此比较条件可以使用索引
+// IS [NOT] NULL 条件无法使用索引
+//
+// 多列索引按照字段的顺序使用条件（最左前缀）
+// 前面的字段为等值条件时，继续使用下一个字段的条件，遇到范围条件或者没有条件的字段时停止
+// 第一个字段没有条件时，无法使用索引
 
 var (
 	ErrNotIndex     = errors.New("not index")
@@ -24,12 +36,12 @@ type Explain struct {
 }
 
 type Interval struct {
-	Min uint64
-	Max uint64
+	Min []byte
+	Max []byte
 }
 
 func (i *Interval) String() string {
-	return fmt.Sprintf("[%d, %d]", i.Min, i.Max)
+	return fmt.Sprintf("[%x, %x]", i.Min, i.Max)
 }
 
 func newExplain() *Explain {
@@ -44,18 +56,18 @@ func (e *Explain) format(i []*Interval) []*Interval {
 
 	// 排序
 	slices.SortFunc(i, func(x, y *Interval) int {
-		if x.Min == y.Min {
-			return cmp.Compare(x.Max, y.Max)
+		if index.CompareKey(x.Min, y.Min) == 0 {
+			return index.CompareKey(x.Max, y.Max)
 		}
-		return cmp.Compare(x.Min, y.Min)
+		return index.CompareKey(x.Min, y.Min)
 	})
 
 	// 合并
 	dst := []*Interval{i[0]}
 	for _, item := range i {
 		n := len(dst) - 1
-		if item.Min <= dst[n].Max {
-			if item.Max > dst[n].Max {
+		if index.CompareKey(item.Min, dst[n].Max) <= 0 {
+			if index.CompareKey(item.Max, dst[n].Max) > 0 {
 				dst[n].Max = item.Max
 			}
 		} else {
@@ -74,19 +86,22 @@ func (e *Explain) compact(i0, i1 []*Interval) []*Interval {
 	dst := make([]*Interval, 0)
 	for _, x := range i0 {
 		for _, y := range i1 {
-			if x.Min > y.Max || y.Min > x.Max {
+			if index.CompareKey(x.Min, y.Max) > 0 || index.CompareKey(y.Min, x.Max) > 0 {
 				continue
 			}
 			dst = append(dst, &Interval{
-				Min: max(x.Min, y.Min),
-				Max: min(x.Max, y.Max),
+				Min: maxKey(x.Min, y.Min),
+				Max: minKey(x.Max, y.Max),
 			})
 		}
 	}
 	return dst
 }
 
-func (e *Explain) process(f *field, w sql.SelectWhere) ([]*Interval, error) {
+// process 解析条件对应的索引区间
+//
+// negate 表示条件需要取反
+func (e *Explain) process(f *field, w sql.SelectWhere, negate bool) ([]*Interval, error) {
 	dst := make([]*Interval, 0)
 	switch w.(type) {
 	case *sql.SelectWhereExpr:
@@ -95,9 +110,27 @@ func (e *Explain) process(f *field, w sql.SelectWhere) ([]*Interval, error) {
 			return dst, ErrNotIndex
 		}
 
+		if expr.Negation != negate {
+			// !(A AND B) == !A OR !B
+			// 取并集，任意条件无法使用索引时，整体无法使用索引
+			for _, c := range expr.Cnf {
+				next, err := e.process(f, c, true)
+				if err != nil {
+					return dst, err
+				}
+				dst = append(dst, next...)
+			}
+			return e.format(dst), nil
+		}
+
+		// A AND B
+		// 取交集，忽略无法使用索引的条件
 		for _, c := range expr.Cnf {
-			next, err := e.process(f, c)
+			next, err := e.process(f, c, false)
 			if err != nil {
+				if errors.Is(err, ErrNotIndex) {
+					continue
+				}
 				return dst, err
 			}
 
@@ -113,36 +146,8 @@ func (e *Explain) process(f *field, w sql.SelectWhere) ([]*Interval, error) {
 				return dst, ErrCondConflict
 			}
 		}
-
-		if expr.Negation && len(dst) > 0 {
-			tmp := make([]*Interval, 0)
-
-			// 处理第一个元素
-			if dst[0].Min > 0 {
-				tmp = append(tmp, &Interval{
-					Min: 0,
-					Max: dst[0].Min - 1,
-				})
-			}
-
-			// 处理相邻的元素
-			for i := 0; i < len(dst)-1; i++ {
-				tmp = append(tmp, &Interval{
-					Min: dst[i].Max + 1,
-					Max: dst[i+1].Min - 1,
-				})
-			}
-
-			// 处理最后一个元素
-			if dst[len(dst)-1].Max < math.MaxUint64 {
-				tmp = append(tmp, &Interval{
-					Min: dst[len(dst)-1].Max + 1,
-					Max: math.MaxUint64,
-				})
-			}
-
-			// 重新赋值
-			dst = tmp
+		if len(dst) == 0 {
+			return dst, ErrNotIndex
 		}
 	case *sql.SelectWhereField:
 		cond := w.(*sql.SelectWhereField)
@@ -150,27 +155,36 @@ func (e *Explain) process(f *field, w sql.SelectWhere) ([]*Interval, error) {
 			return dst, ErrNotIndex
 		}
 
-		val := hash.Sum64(sql.FormatVal(f.Type, cond.Value))
-		switch cond.Operate {
+		if cond.Value.Null {
+			return dst, ErrNotIndex
+		}
+
+		op := cond.Operate
+		if negate {
+			op.Negate()
+		}
+
+		// 字面量无法转换为字段类型时，不使用索引（由 Match 过滤）
+		v, err := sql.FormatVal(f.typ, cond.Value.Str)
+		if err != nil {
+			return dst, ErrNotIndex
+		}
+		val := f.wrapKey(v)
+		switch op {
 		case sql.EQ:
 			dst = append(dst, &Interval{Min: val, Max: val})
 		case sql.NE:
-			// 不等于
-			dst = append(dst, &Interval{Min: 0, Max: val - 1})
-			dst = append(dst, &Interval{Min: val + 1, Max: math.MaxUint64})
-		case sql.LT:
-			// 小于
-			dst = append(dst, &Interval{Min: 0, Max: val - 1})
-		case sql.GT:
-			// 大于
-			dst = append(dst, &Interval{Min: val + 1, Max: math.MaxUint64})
-		case sql.LE:
-			// 小于等于
-			dst = append(dst, &Interval{Min: 0, Max: val})
-		case sql.GE:
-			// 大于等于
-			dst = append(dst, &Interval{Min: val, Max: math.MaxUint64})
+			// 不等于（键可能被截断，无法排除）
+			dst = append(dst, &Interval{Min: index.MinKey(), Max: index.MaxKey()})
+		case sql.LT, sql.LE:
+			// 小于，小于等于
+			dst = append(dst, &Interval{Min: index.MinKey(), Max: val})
+		case sql.GT, sql.GE:
+			// 大于，大于等于
+			dst = append(dst, &Interval{Min: val, Max: index.MaxKey()})
 		}
+	default:
+		return dst, ErrNotIndex
 	}
 	return dst, nil
 }
@@ -178,7 +192,7 @@ func (e *Explain) process(f *field, w sql.SelectWhere) ([]*Interval, error) {
 func (e *Explain) execute(f *field, ws []sql.SelectWhere) ([]*Interval, error) {
 	dst := make([]*Interval, 0)
 	for _, w := range ws {
-		next, err := e.process(f, w)
+		next, err := e.process(f, w, false)
 		if err != nil {
 			if errors.Is(err, ErrNotIndex) {
 				continue
@@ -199,3 +213,58 @@ func (e *Explain) execute(f *field, ws []sql.SelectWhere) ([]*Interval, error) {
 	}
 	return dst, nil
 }
+
+// composite 解析多列索引的区间
+//
+// 等值条件的字段值拼接为前缀，第一个范围条件转换为以前缀开头的区间
+func (e *Explain) composite(fs []*field, ws []sql.SelectWhere) ([]*Interval, error) {
+	prefix := make([]byte, 0)
+	for i, f := range fs {
+		if f == nil {
+			break
+		}
+		rs, err := e.execute(f, ws)
+		if err != nil {
+			if errors.Is(err, ErrNotIndex) && i != 0 {
+				break
+			}
+			return nil, err
+		}
+
+		// 等值条件
+		if len(rs) == 1 && bytes.Equal(rs[0].Min, rs[0].Max) {
+			prefix = append(prefix, f.keyPart(rs[0].Min)...)
+			continue
+		}
+
+		// 范围条件
+		dst := make([]*Interval, 0, len(rs))
+		for _, r := range rs {
+			lo := prefix
+			if !bytes.Equal(r.Min, index.MinKey()) {
+				lo = index.EncodeComposite(prefix, f.keyPart(r.Min))
+			}
+			hi := index.PrefixMax(prefix)
+			if !bytes.Equal(r.Max, index.MaxKey()) {
+				hi = index.PrefixMax(index.EncodeComposite(prefix, f.keyPart(r.Max)))
+			}
+			dst = append(dst, &Interval{Min: lo, Max: hi})
+		}
+		return dst, nil
+	}
+	return []*Interval{{Min: prefix, Max: index.PrefixMax(prefix)}}, nil
+}
+
+func minKey(a, b []byte) []byte {
+	if index.CompareKey(a, b) <= 0 {
+		return a
+	}
+	return b
+}
+
+func maxKey(a, b []byte) []byte {
+	if index.CompareKey(a, b) >= 0 {
+		return a
+	}
+	return b
+}
@@ -4,16 +4,21 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/ggymm/db/index"
 	"github.com/ggymm/db/pkg/sql"
 	"github.com/ggymm/db/test"
 )
 
+func key(v uint64) []byte {
+	return index.EncodeUint64(v)
+}
+
 func Test_fmtCond(t *testing.T) {
 	src := []*Interval{
-		{Min: 1, Max: 20},
-		{Min: 30, Max: 40},
-		{Min: 20, Max: 30},
-		{Min: 20, Max: 50},
+		{Min: key(1), Max: key(20)},
+		{Min: key(30), Max: key(40)},
+		{Min: key(20), Max: key(30)},
+		{Min: key(20), Max: key(50)},
 	}
 	dst := newExplain().format(src)
 	t.Logf("%+v", dst)
@@ -21,10 +26,10 @@ func Test_fmtCond(t *testing.T) {
 
 func Test_mixCond(t *testing.T) {
 	s0 := []*Interval{
-		{Min: 1, Max: 20},
+		{Min: key(1), Max: key(20)},
 	}
 	s1 := []*Interval{
-		{Min: 30, Max: 40},
+		{Min: key(30), Max: key(40)},
 	}
 	dst := newExplain().compact(s0, s1)
 	t.Logf("%+v", dst)
@@ -53,6 +58,7 @@ func Test_SelectIndex(t *testing.T) {
 
 		selectStmt := stmt.(*sql.SelectStmt)
 		res, err := newExplain().execute(&field{
+			typ:  sql.ColumnType{Type: sql.Int64},
 			Name: "id",
 			Type: sql.Int64.String(),
 		}, selectStmt.Where)
@@ -64,3 +70,43 @@ func Test_SelectIndex(t *testing.T) {
 		}
 	}
 }
+
+func Test_composite(t *testing.T) {
+	fs := []*field{
+		{typ: sql.ColumnType{Type: sql.Int32}, Name: "a"},
+		{typ: sql.ColumnType{Type: sql.Varchar}, Name: "b"},
+	}
+	a := index.EncodePart(index.EncodeInt32(1))
+	ab := index.EncodeComposite(a, index.EncodePart(index.EncodeVarPart([]byte("x"))))
+	for where, want := range map[string][]*Interval{
+		"a = 1":                {{Min: a, Max: index.PrefixMax(a)}},
+		"a = 1 AND b = 'x'":    {{Min: ab, Max: index.PrefixMax(ab)}},
+		"a = 1 AND b >= 'x'":   {{Min: ab, Max: index.PrefixMax(a)}},
+		"a = 1 AND b <= 'x'":   {{Min: a, Max: index.PrefixMax(ab)}},
+		"a >= 1 AND b = 'x'":   {{Min: a, Max: index.MaxKey()}},
+		"a < 1 AND b = 'x'":    {{Min: nil, Max: index.PrefixMax(a)}},
+		"b = 'x'":              nil,
+		"a = 1 AND a = 2":      nil,
+		"a = 1 AND name = 'x'": {{Min: a, Max: index.PrefixMax(a)}},
+	} {
+		stmt, err := sql.ParseSQL("SELECT * FROM t WHERE " + where + ";")
+		if err != nil {
+			t.Fatalf("%s: %+v", where, err)
+		}
+		got, err := newExplain().composite(fs, stmt.(*sql.SelectStmt).Where)
+		if want == nil {
+			if err == nil {
+				t.Fatalf("%s: got %v, want error", where, got)
+			}
+			continue
+		}
+		if err != nil || len(got) != len(want) {
+			t.Fatalf("%s: got %v, err %v", where, got, err)
+		}
+		for i := range want {
+			if index.CompareKey(got[i].Min, want[i].Min) != 0 || index.CompareKey(got[i].Max, want[i].Max) != 0 {
+				t.Fatalf("%s: got %v, want %v", where, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package table
+
+import (
+	"maps"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/ggymm/db/index"
+	"github.com/ggymm/db/pkg/sql"
+)
+
+// 连接
+//
+// FROM a JOIN b ON a.x = b.y 按照从左到右的顺序连接，INNER JOIN 只保留满足连接条件的数据
+// LEFT JOIN 时左边的数据在右表中没有满足连接条件的数据时仍然保留，右表的字段为 NULL
+//
+// 连接之后的数据中的字段使用 表别名.字段名称 的形式（没有别名时使用表名），没有歧义的字段可以省略表别名
+// 查询的字段使用查询中的名称，* 展开为全部表中的字段，别名.* 展开为一个表中的字段
+//
+// 连接条件只能比较两个字段，字段只能属于当前的表和之前连接的表，与常量比较的条件需要写在 WHERE 中
+// WHERE 中只使用一个表的字段的条件（AND 连接的条件分别判断）下推到读取表的数据时过滤（可以使用索引）
+// LEFT JOIN 的右表的条件需要在连接之后判断（右表的字段可能为 NULL），不能下推
+//
+// 连接的算法（依次选择）：
+// 1. 索引嵌套循环连接：右表的字段等于之前的表的字段，右表的字段有索引（索引的第一个字段），并且两个字段的类型相同
+// 左边的每条数据使用字段值查询右表的索引
+// 2. 哈希连接：有等值条件但是没有可以使用的索引时，读取右表的数据构造哈希表，左边的每条数据查询哈希表
+// 3. 嵌套循环连接：没有等值条件时，左边的每条数据与右表的全部数据比较
+//
+// 比较字段时，整数统一为 INT64，DECIMAL 忽略小数末尾的 0，其他类型不同的字段不相等
+
+type join struct {
+	src    *source
+	tables []*joinTable
+	where  []sql.SelectWhere // 连接之后过滤的条件
+}
+
+// joinTable 连接的表
+type joinTable struct {
+	t      *table
+	alias  string
+	left   bool
+	fields []*field          // 连接之后的数据中的字段（与 t.Fields 的顺序相同）
+	on     []*joinCond       // 连接条件
+	where  []sql.SelectWhere // 下推的条件（使用表中的字段名称）
+
+	eq    *joinCond // 等值连接条件（nil 时使用嵌套循环连接）
+	index *field    // 索引嵌套循环连接使用的索引字段（表中的字段）
+}
+
+// joinCond 连接条件 l op r
+//
+// 一边是当前的表的字段，另一边是之前连接的表的字段时，r 为当前的表的字段
+type joinCond struct {
+	l, r *field
+	op   sql.CompareOperate
+}
+
+func newJoin(stmt *sql.SelectStmt, get func(string) (*table, error)) (*join, error) {
+	j := &join{
+		src: &source{
+			names:  make(map[string]*field),
+			tables: make(map[string][]*field),
+		},
+	}
+	owner := make(map[string]int) // 数据中的字段对应的表的序号
+
+	// 连接的表
+	add := func(name, alias string, left bool) error {
+		t, err := get(name)
+		if err != nil {
+			return err
+		}
+		if alias == "" {
+			alias = name
+		}
+		if _, ok := j.src.tables[alias]; ok {
+			return NewError(ErrDuplicateAlias, alias)
+		}
+
+		jt := &joinTable{t: t, alias: alias, left: left}
+		for _, f := range t.Fields {
+			nf := &field{
+				Name:     alias + "." + f.Name,
+				Type:     f.Type,
+				Nullable: true,
+				typ:      f.typ,
+			}
+			jt.fields = append(jt.fields, nf)
+			owner[nf.Name] = len(j.tables)
+
+			j.src.fields = append(j.src.fields, nf)
+			j.src.names[nf.Name] = nf
+			if _, ok := j.src.names[f.Name]; ok {
+				j.src.names[f.Name] = nil
+			} else {
+				j.src.names[f.Name] = nf
+			}
+		}
+		j.src.tables[alias] = jt.fields
+		j.tables = append(j.tables, jt)
+		return nil
+	}
+	err := add(stmt.Table, stmt.Alias, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, sj := range stmt.Join {
+		err = add(sj.Table, sj.Alias, sj.Left)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 连接条件
+	for i, sj := range stmt.Join {
+		jt := j.tables[i+1]
+		field := func(name string) (*field, error) {
+			f, err := j.src.field(name)
+			if err != nil {
+				return nil, err
+			}
+			if owner[f.Name] > i+1 {
+				return nil, NewError(ErrJoinField, name, jt.alias)
+			}
+			return f, nil
+		}
+
+		for _, on := range sj.On {
+			if on.Literal {
+				return nil, NewError(ErrJoinLiteral, jt.alias)
+			}
+			l, err := field(on.Left)
+			if err != nil {
+				return nil, err
+			}
+			r, err := field(on.Right)
+			if err != nil {
+				return nil, err
+			}
+			c := &joinCond{l: l, r: r, op: on.Operate}
+			if owner[l.Name] == i+1 && owner[r.Name] <= i {
+				c.l, c.r = r, l
+				c.op.Reverse()
+			}
+			jt.on = append(jt.on, c)
+		}
+		jt.algorithm(owner, i+1)
+	}
+
+	// 下推查询条件
+	for _, w := range stmt.Where {
+		k := -1
+		for _, name := range whereFields([]sql.SelectWhere{w}) {
+			f := j.src.names[name]
+			if f == nil || (k != -1 && owner[f.Name] != k) {
+				k = -1
+				break
+			}
+			k = owner[f.Name]
+		}
+		if k != -1 && !j.tables[k].left {
+			jt := j.tables[k]
+			jt.where = append(jt.where, renameWhere([]sql.SelectWhere{w}, func(name string) string {
+				return strings.TrimPrefix(j.src.names[name].Name, jt.alias+".")
+			})...)
+			continue
+		}
+
+		nw, err := j.src.where([]sql.SelectWhere{w})
+		if err != nil {
+			return nil, err
+		}
+		j.where = append(j.where, nw...)
+	}
+	return j, nil
+}
+
+// algorithm 选择连接的算法（i 为当前的表的序号）
+func (jt *joinTable) algorithm(owner map[string]int, i int) {
+	for _, c := range jt.on {
+		if c.op != sql.EQ || owner[c.r.Name] != i || owner[c.l.Name] >= i {
+			continue
+		}
+		if jt.eq == nil {
+			jt.eq = c
+		}
+		f := jt.field(c.r)
+		if f.index != nil && joinable(c.l, f) {
+			jt.eq = c
+			jt.index = f
+			return
+		}
+	}
+}
+
+// field 连接之后的数据中的字段对应的表中的字段
+func (jt *joinTable) field(f *field) *field {
+	for i, nf := range jt.fields {
+		if nf == f {
+			return jt.t.Fields[i]
+		}
+	}
+	return nil
+}
+
+// access 读取表中满足下推条件的数据的算子
+func (jt *joinTable) access(tbm *tableManage, tid uint64) (operator, error) {
+	scan, err := jt.t.access(tbm, tid, jt.where)
+	if err != nil {
+		return nil, err
+	}
+	return &filterOp{child: scan, where: jt.where}, nil
+}
+
+// lookup 使用左边的字段值查询索引
+func (jt *joinTable) lookup(tbm *tableManage, tid uint64, v any) ([]Entry, error) {
+	f := jt.index
+	v, ok := indexVal(f, v)
+	if !ok {
+		return nil, nil
+	}
+
+	var r *Interval
+	if len(f.IndexCols) == 0 {
+		key := f.wrapKey(v)
+		r = &Interval{Min: key, Max: key}
+	} else {
+		prefix := f.wrapPart(v)
+		r = &Interval{Min: prefix, Max: index.PrefixMax(prefix)}
+	}
+	return drain(&filterOp{
+		child: &indexScanOp{
+			rowReader: rowReader{tbm: tbm, tid: tid, t: jt.t},
+			scans:     []*indexScan{{f: f, rs: []*Interval{r}}},
+		},
+		where: jt.where,
+	})
+}
+
+// wrap 将表中的数据写入连接之后的数据（row 为 nil 时字段为 NULL）
+func (jt *joinTable) wrap(dst, row Entry) Entry {
+	for i, f := range jt.t.Fields {
+		dst[jt.fields[i].Name] = row[f.Name]
+	}
+	return dst
+}
+
+func (jt *joinTable) match(row Entry) bool {
+	for _, c := range jt.on {
+		if !c.match(row) {
+			return false
+		}
+	}
+	return true
+}
+
+// wrapOp 将第一个表的数据转换为连接之后的数据
+type wrapOp struct {
+	child operator
+	jt    *joinTable
+	size  int // 连接之后的数据中的字段数量
+}
+
+func (op *wrapOp) Open() error {
+	return op.child.Open()
+}
+
+func (op *wrapOp) Next() (Entry, error) {
+	row, err := op.child.Next()
+	if err != nil || row == nil {
+		return nil, err
+	}
+	return op.jt.wrap(make(Entry, op.size), row), nil
+}
+
+func (op *wrapOp) Close() {
+	op.child.Close()
+}
+
+// joinOp 连接左边的数据和当前的表
+//
+// 依次读取左边的数据，与右表中的数据连接
+// 索引嵌套循环连接时每条数据查询一次索引，哈希连接和嵌套循环连接在打开时读取右表的全部数据
+type joinOp struct {
+	tbm  *tableManage
+	tid  uint64
+	jt   *joinTable
+	left operator
+
+	hash map[any][]Entry // 哈希连接时右表的数据
+	all  []Entry         // 嵌套循环连接时右表的数据
+
+	cur     Entry   // 当前的左边的数据
+	rows    []Entry // 当前的左边的数据对应的右表的数据
+	pos     int
+	matched bool
+}
+
+func (op *joinOp) Open() error {
+	err := op.left.Open()
+	if err != nil {
+		return err
+	}
+	jt := op.jt
+	if jt.index != nil {
+		return nil
+	}
+
+	right, err := jt.access(op.tbm, op.tid)
+	if err != nil {
+		return err
+	}
+	rows, err := drain(right)
+	if err != nil {
+		return err
+	}
+	if jt.eq == nil {
+		op.all = rows
+		return nil
+	}
+
+	f := jt.field(jt.eq.r)
+	op.hash = make(map[any][]Entry)
+	for _, r := range rows {
+		if v := r[f.Name]; v != nil {
+			k := joinValue(v)
+			op.hash[k] = append(op.hash[k], r)
+		}
+	}
+	return nil
+}
+
+// candidates 左边的数据可能连接的右表的数据
+func (op *joinOp) candidates(l Entry) ([]Entry, error) {
+	jt := op.jt
+	switch {
+	case jt.index != nil:
+		// 索引嵌套循环连接
+		if v := l[jt.eq.l.Name]; v != nil {
+			return jt.lookup(op.tbm, op.tid, v)
+		}
+		return nil, nil
+	case jt.eq != nil:
+		// 哈希连接
+		if v := l[jt.eq.l.Name]; v != nil {
+			return op.hash[joinValue(v)], nil
+		}
+		return nil, nil
+	}
+	// 嵌套循环连接
+	return op.all, nil
+}
+
+func (op *joinOp) Next() (Entry, error) {
+	jt := op.jt
+	for {
+		for op.pos < len(op.rows) {
+			r := op.rows[op.pos]
+			op.pos++
+			row := jt.wrap(maps.Clone(op.cur), r)
+			if jt.match(row) {
+				op.matched = true
+				return row, nil
+			}
+		}
+		if op.cur != nil && !op.matched && jt.left {
+			row := jt.wrap(op.cur, nil)
+			op.cur = nil
+			return row, nil
+		}
+
+		l, err := op.left.Next()
+		if err != nil || l == nil {
+			return nil, err
+		}
+		rows, err := op.candidates(l)
+		if err != nil {
+			return nil, err
+		}
+		op.cur, op.rows, op.pos, op.matched = l, rows, 0, false
+	}
+}
+
+func (op *joinOp) Close() {
+	op.left.Close()
+	op.hash, op.all, op.rows = nil, nil, nil
+}
+
+// joinOp 构造连接查询的算子树
+func (tbm *tableManage) joinOp(tid uint64, stmt *sql.SelectStmt) (operator, error) {
+	j, err := newJoin(stmt, func(name string) (*table, error) {
+		return tbm.getTable(tid, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	first := j.tables[0]
+	input, err := first.access(tbm, tid)
+	if err != nil {
+		return nil, err
+	}
+	var op operator = &wrapOp{child: input, jt: first, size: len(j.src.fields)}
+	for _, jt := range j.tables[1:] {
+		op = &joinOp{tbm: tbm, tid: tid, jt: jt, left: op}
+	}
+	return query(j.src, stmt, func([]*sql.SelectOrder) (operator, bool, error) {
+		return &filterOp{child: op, where: j.where}, false, nil
+	})
+}
+
+func (c *joinCond) match(row Entry) bool {
+	a, b := row[c.l.Name], row[c.r.Name]
+	if a == nil || b == nil {
+		return false
+	}
+	r, ok := compareJoin(a, b)
+	if !ok {
+		return false
+	}
+	switch c.op {
+	case sql.EQ:
+		return r == 0
+	case sql.NE:
+		return r != 0
+	case sql.LT:
+		return r < 0
+	case sql.GT:
+		return r > 0
+	case sql.LE:
+		return r <= 0
+	case sql.GE:
+		return r >= 0
+	}
+	return false
+}
+
+// joinValue 将字段值转换为连接时比较的值（整数统一为 int64，DECIMAL 去掉小数末尾的 0）
+func joinValue(v any) any {
+	switch val := v.(type) {
+	case int32:
+		return int64(val)
+	case sql.Dec:
+		for val.Scale > 0 && val.Value%10 == 0 {
+			val.Value /= 10
+			val.Scale--
+		}
+		return val
+	}
+	return v
+}
+
+// compareJoin 比较连接条件两边的字段值，类型不同时返回 false
+func compareJoin(a, b any) (int, bool) {
+	a, b = joinValue(a), joinValue(b)
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return 0, false
+	}
+	return compareValue(a, b), true
+}
+
+// joinable 左边的字段值是否可以直接用于查询右边的字段的索引
+func joinable(l, r *field) bool {
+	isInt := func(f *field) bool {
+		return f.typ.Type == sql.Int32 || f.typ.Type == sql.Int64
+	}
+	if isInt(l) && isInt(r) {
+		return true
+	}
+	return l.typ.Type == r.typ.Type && l.typ.Scale == r.typ.Scale
+}
+
+// indexVal 将整数转换为字段的类型（超出范围时返回 false）
+func indexVal(f *field, v any) (any, bool) {
+	switch f.typ.Type {
+	case sql.Int32:
+		n := intVal(v)
+		if n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, false
+		}
+		return int32(n), true
+	case sql.Int64:
+		return intVal(v), true
+	}
+	return v, true
+}
@@ -0,0 +1,120 @@
+package table
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ggymm/db"
+	"github.com/ggymm/db/boot"
+	"github.com/ggymm/db/data"
+	"github.com/ggymm/db/pkg/sql"
+	"github.com/ggymm/db/tx"
+	"github.com/ggymm/db/ver"
+)
+
+func TestTableManage_Join(t *testing.T) {
+	opt := db.NewOption(db.RunPath(), "temp/table_join")
+	opt.Memory = (1 << 20) * 64
+	err := os.RemoveAll(opt.Path)
+	if err != nil {
+		t.Fatalf("remove err %v", err)
+	}
+	tm := tx.NewManager(opt)
+	dm := data.NewManage(tm, opt)
+	defer tm.Close()
+	defer dm.Close()
+	tbm := NewManage(boot.New(opt), ver.NewManage(tm, dm), dm).(*tableManage)
+
+	parse := func(in string) sql.Statement {
+		stmt, err := sql.ParseSQL(in)
+		if err != nil {
+			t.Fatalf("parse %s err %v", in, err)
+		}
+		return stmt
+	}
+
+	tid := tbm.Begin(1)
+	defer tbm.Rollback(tid)
+	for _, in := range []string{
+		"CREATE TABLE user (id INT64, city INT32, PRIMARY KEY (id));",
+		"CREATE TABLE orders (id INT64, uid INT64, amount INT32, PRIMARY KEY (id), INDEX uid_idx (uid));",
+		"CREATE TABLE city (code INT64, name VARCHAR, PRIMARY KEY (name));",
+	} {
+		err = tbm.Create(tid, parse(in).(*sql.CreateStmt))
+		if err != nil {
+			t.Fatalf("create err %v", err)
+		}
+	}
+	for _, in := range []string{
+		"INSERT INTO city (code, name) VALUE (0, 'c0');",
+		"INSERT INTO city (code, name) VALUE (1, 'c1');",
+	} {
+		_, err = tbm.Insert(tid, parse(in).(*sql.InsertStmt))
+		if err != nil {
+			t.Fatalf("insert err %v", err)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		_, err = tbm.Insert(tid, parse(fmt.Sprintf("INSERT INTO user (id, city) VALUE (%d, %d);", i, i%3)).(*sql.InsertStmt))
+		if err != nil {
+			t.Fatalf("insert err %v", err)
+		}
+		for j := 0; j < i%4; j++ {
+			in := fmt.Sprintf("INSERT INTO orders (id, uid, amount) VALUE (%d, %d, %d);", i*10+j, i, j)
+			_, err = tbm.Insert(tid, parse(in).(*sql.InsertStmt))
+			if err != nil {
+				t.Fatalf("insert err %v", err)
+			}
+		}
+	}
+
+	for in, want := range map[string]struct {
+		algorithm string
+		rows      int
+	}{
+		// user.id 0 ~ 19 的订单数量为 i % 4
+		"SELECT * FROM user u JOIN orders o ON u.id = o.uid;":                    {"index", 30},
+		"SELECT * FROM user u LEFT JOIN orders o ON o.uid = u.id;":               {"index", 35},
+		"SELECT * FROM orders o JOIN user u ON o.uid = u.id WHERE o.amount = 2;": {"index", 5},
+		"SELECT * FROM user u JOIN city c ON u.city = c.code;":                   {"hash", 14},
+		"SELECT * FROM user u LEFT JOIN city c ON c.code = u.city;":              {"hash", 20},
+		// city 为 0、1、2 的用户分别为 4、3、3 个，amount 为 0、1、2 的订单分别为 15、10、5 个
+		"SELECT * FROM user u JOIN orders o ON o.amount >= u.city WHERE u.id < 10;": {"nested", 4*30 + 3*15 + 3*5},
+		"SELECT * FROM user u JOIN city c;":                                         {"nested", 40},
+	} {
+		stmt := parse(in).(*sql.SelectStmt)
+		j, err := newJoin(stmt, func(name string) (*table, error) {
+			return tbm.getTable(tid, name)
+		})
+		if err != nil {
+			t.Fatalf("%s: join err %v", in, err)
+		}
+		algorithm := "nested"
+		switch {
+		case j.tables[1].index != nil:
+			algorithm = "index"
+		case j.tables[1].eq != nil:
+			algorithm = "hash"
+		}
+		if algorithm != want.algorithm {
+			t.Fatalf("%s: algorithm %s, want %s", in, algorithm, want.algorithm)
+		}
+
+		rows, err := tbm.Select(tid, stmt)
+		if err != nil || len(rows) != want.rows {
+			t.Fatalf("%s: got %d rows, want %d, err %v", in, len(rows), want.rows, err)
+		}
+	}
+	// 连接条件只能比较两个字段
+	for _, in := range []string{
+		"SELECT * FROM user u JOIN city c ON u.city = c.code AND c.name = 'c1';",
+		"SELECT * FROM user u LEFT JOIN orders o ON o.uid = u.id AND o.amount > 1;",
+	} {
+		_, err = tbm.Select(tid, parse(in).(*sql.SelectStmt))
+		if err == nil || !strings.Contains(err.Error(), "must compare two columns") {
+			t.Fatalf("%s: err %v", in, err)
+		}
+	}
+}
@@ -1,17 +1,14 @@
 package table
 
 import (
-	"fmt"
+	"bytes"
 	"slices"
 	"sync"
 
-	"github.com/ggymm/db"
 	"github.com/ggymm/db/boot"
 	"github.com/ggymm/db/data"
-	"github.com/ggymm/db/index"
 	"github.com/ggymm/db/pkg/bin"
 	"github.com/ggymm/db/pkg/cmap"
-	"github.com/ggymm/db/pkg/hash"
 	"github.com/ggymm/db/pkg/sql"
 	"github.com/ggymm/db/pkg/view"
 	"github.com/ggymm/db/ver"
@@ -23,14 +20,24 @@ type Manage interface {
 	Rollback(tid uint64)
 
 	Create(tid uint64, stmt *sql.CreateStmt) (err error)
-	Insert(tid uint64, stmt *sql.InsertStmt) (err error)
-	Delete(tid uint64, stmt *sql.DeleteStmt) (err error)
-	Update(tid uint64, stmt *sql.UpdateStmt) (err error)
+	Alter(tid uint64, stmt *sql.AlterStmt) (err error)
+	Drop(tid uint64, stmt *sql.DropStmt) (err error)
+	Truncate(tid uint64, stmt *sql.TruncateStmt) (err error)
+	CreateIndex(tid uint64, stmt *sql.CreateIndexStmt) (err error)
+	DropIndex(tid uint64, stmt *sql.DropIndexStmt) (err error)
+	Analyze(tid uint64, stmt *sql.AnalyzeStmt) (err error)
+	Insert(tid uint64, stmt *sql.InsertStmt) (n int, err error)
+	Delete(tid uint64, stmt *sql.DeleteStmt) (n int, err error)
+	Update(tid uint64, stmt *sql.UpdateStmt) (n int, err error)
 	Select(tid uint64, stmt *sql.SelectStmt) ([]Entry, error)
+	Query(tid uint64, stmt *sql.SelectStmt) ([]*Column, []Entry, error)
+
+	Columns(table string) ([]*Column, error)
+	Describe(stmt *sql.SelectStmt) ([]*Column, error)
 
 	ShowTable() string
 	ShowField(table string) string
-	ShowResult(table string, entries []Entry) string
+	ShowResult(stmt *sql.SelectStmt, entries []Entry) string
 
 	VerManage() ver.Manage
 	DataManage() data.Manage
@@ -38,7 +45,10 @@ type Manage interface {
 
 type tableManage struct {
 	sync.Mutex
+	unique sync.Mutex // 检查唯一约束和写入数据互斥执行
 	tables cmap.CMap[string, *table]
+	ddl    *ddl   // 未提交的表结构修改
+	purge  *purge // 等待清理的索引项
 
 	boot       boot.Boot
 	verManage  ver.Manage
@@ -48,6 +58,7 @@ type tableManage struct {
 func NewManage(boot boot.Boot, verManage ver.Manage, dataManage data.Manage) Manage {
 	tbm := &tableManage{
 		tables: cmap.New[*table](),
+		purge:  newPurge(),
 
 		boot:       boot,
 		verManage:  verManage,
@@ -74,15 +85,32 @@ func (tbm *tableManage) updateTableId(id uint64) {
 }
 
 func (tbm *tableManage) Begin(level int) uint64 {
-	return tbm.verManage.Begin(level)
+	tid := tbm.verManage.Begin(level)
+	tbm.purge.begin(tid)
+	return tid
 }
 
 func (tbm *tableManage) Commit(tid uint64) error {
-	return tbm.verManage.Commit(tid)
+	err := tbm.verManage.Commit(tid)
+	if err != nil {
+		return err
+	}
+	tbm.endDDL(tid, true)
+	tbm.cleanup(tbm.purge.end(tid, true))
+	return nil
 }
 
 func (tbm *tableManage) Rollback(tid uint64) {
 	tbm.verManage.Rollback(tid)
+	tbm.cleanup(tbm.purge.end(tid, false))
+	tbm.endDDL(tid, false)
+}
+
+// cleanup 从索引中删除不再使用的数据版本（删除失败只会导致索引中保留无效的索引项）
+func (tbm *tableManage) cleanup(items []*garbage) {
+	for _, g := range items {
+		_ = g.tree.Delete(g.key, g.rid)
+	}
 }
 
 func (tbm *tableManage) Create(tid uint64, stmt *sql.CreateStmt) (err error) {
@@ -91,52 +119,70 @@ func (tbm *tableManage) Create(tid uint64, stmt *sql.CreateStmt) (err error) {
 	if exist := tbm.tables.Has(stmt.Name); exist {
 		return
 	}
+	err = tbm.beginDDL(tid)
+	if err != nil {
+		return err
+	}
 
 	t := new(table)
 	t.tbm = tbm
 	t.Name = stmt.Name
-	t.Next = tbm.readTableId() // 上一张表的 itemId
-	t.Fields = make([]*field, 0)
+	t.all = make([]*field, 0)
+
+	// 检查索引字段
+	// 多列索引保存在第一个字段上，每个字段只能有一个索引
+	indexes := make(map[string]*sql.CreateIndex)
+	all := stmt.Table.Index
+	if stmt.Table.Pk != nil {
+		all = append([]*sql.CreateIndex{stmt.Table.Pk}, all...)
+	}
+	for _, i := range all {
+		err = checkIndexFields(i.Field, func(name string) bool {
+			return slices.ContainsFunc(stmt.Table.Field, func(tf *sql.CreateField) bool {
+				return tf.Name == name
+			})
+		})
+		if err != nil {
+			return err
+		}
+		if _, exist := indexes[i.Field[0]]; exist {
+			return NewError(ErrFieldIndexed, i.Field[0])
+		}
+		indexes[i.Field[0]] = i
+	}
 
-	// 读取 主键 和 索引
-	indexes := make([]string, 0)
-	for _, i := range stmt.Table.Index {
-		indexes = append(indexes, i.Field)
+	// 创建保存数据的堆
+	t.HeapId, err = tbm.DataManage().NewHeap()
+	if err != nil {
+		return err
 	}
+	tbm.ddl.created = append(tbm.ddl.created, t)
 
 	// 读取 field
 	for _, tf := range stmt.Table.Field {
-		f := new(field)
-		f.tbm = tbm
-		f.Name = tf.Name
-		f.Type = tf.Type.String()
-		f.TreeId = 0
-		f.Default = tf.Default
-		f.Nullable = tf.Nullable
-
-		indexed := false
-		if slices.Contains(indexes, tf.Name) {
-			indexed = true
-			f.Nullable = false
+		// 索引字段允许为空（NULL 不会写入索引）
+		i, indexed := indexes[tf.Name]
+
+		f, err1 := tbm.newField(tf, indexed)
+		if err1 != nil {
+			return err1
+		}
+		if indexed {
+			f.IndexName = i.Name
+			if len(i.Field) > 1 {
+				f.IndexCols = i.Field
+			}
 		}
 
 		// 如果是主键
-		// 则不允许为空，且是索引
-		if stmt.Table.Pk.Field == tf.Name {
-			indexed = true
+		// 则不允许为空，且是唯一索引
+		if stmt.Table.Pk != nil && slices.Contains(stmt.Table.Pk.Field, tf.Name) {
 			f.Nullable = false
 			f.PrimaryKey = true
-		}
-
-		if indexed {
-			i, err1 := index.NewIndex(tbm.DataManage(), &db.Option{
-				Open: false,
-			})
-			if err1 != nil {
-				return err1
+			if indexed && i == stmt.Table.Pk {
+				f.IndexName = primaryIndex
+				f.Unique = true
 			}
-			f.index = i
-			f.TreeId = i.GetBootId()
 		}
 
 		// 保存字段信息
@@ -144,31 +190,25 @@ func (tbm *tableManage) Create(tid uint64, stmt *sql.CreateStmt) (err error) {
 		if err != nil {
 			return err
 		}
-		t.Fields = append(t.Fields, f)
-	}
-
-	// 保存表信息
-	err = t.save(tid)
-	if err != nil {
-		return err
+		t.all = append(t.all, f)
 	}
+	t.init()
 
 	// 更新表信息
 	tbm.tables.Set(t.Name, t)
-	tbm.updateTableId(t.itemId) // 更新为当前表的 itemId
-	return
+	return tbm.saveCatalog(tid)
 }
 
-func (tbm *tableManage) Insert(tid uint64, stmt *sql.InsertStmt) (err error) {
+func (tbm *tableManage) Insert(tid uint64, stmt *sql.InsertStmt) (n int, err error) {
 	// 获取表对象
-	t, ok := tbm.tables.Get(stmt.Table)
-	if !ok {
-		return ErrNoSuchTable
+	t, err := tbm.getTable(tid, stmt.Table)
+	if err != nil {
+		return 0, err
 	}
 
 	// 格式化插入数据
 	if len(stmt.Value) != len(stmt.Field) {
-		return ErrInsertNotMatch
+		return 0, ErrInsertNotMatch
 	}
 	row := make(map[string]any)
 	for _, f := range t.Fields {
@@ -176,183 +216,346 @@ func (tbm *tableManage) Insert(tid uint64, stmt *sql.InsertStmt) (err error) {
 		if i == -1 {
 			continue
 		}
-		row[f.Name] = sql.FormatVal(f.Type, stmt.Value[i])
+		row[f.Name], err = f.formatVal(stmt.Value[i])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// 补全默认值和自增字段
+	err = t.fillRow(row)
+	if err != nil {
+		return 0, err
+	}
+
+	// 写入数据
+	_, err = tbm.insertRow(tid, t, row)
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// insertRow 检查唯一约束之后写入数据和索引
+//
+// 检查唯一约束和写入索引需要互斥执行，否则两个事务可能同时通过检查，写入重复的值
+func (tbm *tableManage) insertRow(tid uint64, t *table, row Entry) (uint64, error) {
+	tbm.unique.Lock()
+	defer tbm.unique.Unlock()
+
+	// 检查唯一约束
+	err := tbm.checkUnique(tid, t, row)
+	if err != nil {
+		return 0, err
 	}
 
 	// 构建数据
 	raw, err := t.wrapRaw(row)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// 写入数据
-	rid, err := tbm.verManage.Write(tid, raw)
+	rid, err := t.write(tid, raw)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// 判断是否有字段需要索引
 	for _, f := range t.Fields {
 		if f.TreeId != 0 {
-			v, exist := row[f.Name]
-			if !exist {
-				return NewError(ErrNotAllowNull, f.Name)
+			key := t.indexKey(f, row)
+			if key == nil {
+				continue
 			}
 
 			// 格式化索引字段
-			err = f.index.Insert(hash.Sum64(v), rid)
+			err = f.index.Insert(key, rid)
 			if err != nil {
-				return err
+				return 0, err
 			}
 		}
 	}
-	return
+	tbm.purge.write(tid, rowGarbage(t, row, rid))
+	return rid, nil
 }
 
-func (tbm *tableManage) Delete(tid uint64, stmt *sql.DeleteStmt) (err error) {
-	t, ok := tbm.tables.Get(stmt.Table)
-	if !ok {
-		return ErrNoSuchTable
+func (tbm *tableManage) Delete(tid uint64, stmt *sql.DeleteStmt) (n int, err error) {
+	t, err := tbm.getTable(tid, stmt.Table)
+	if err != nil {
+		return 0, err
 	}
 
 	if len(stmt.Where) == 0 {
-		return ErrMustHaveCondition
+		return 0, ErrMustHaveCondition
 	}
 
-	// 查询解析
-	rids, err := t.parseWhere(stmt.Where)
+	// 读取数据（索引只能确定数据的范围，需要过滤数据）
+	scan, err := t.access(tbm, tid, stmt.Where)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	op := &filterOp{child: scan, where: stmt.Where}
+	defer op.Close()
+	err = op.Open()
+	if err != nil {
+		return 0, err
 	}
+	for {
+		row, err := op.Next()
+		if err != nil {
+			return n, err
+		}
+		if row == nil {
+			return n, nil
+		}
 
-	// 读取数据
-	for _, rid := range rids {
-		_, err = tbm.verManage.Delete(tid, rid)
+		// 删除数据
+		rid := scan.rid()
+		ok, err := tbm.verManage.Delete(tid, rid)
 		if err != nil {
-			return err
+			return n, err
+		}
+		if ok {
+			n++
+			tbm.purge.delete(tid, rowGarbage(t, row, rid))
 		}
 	}
-	return
 }
 
-func (tbm *tableManage) Update(tid uint64, stmt *sql.UpdateStmt) (err error) {
-	t, ok := tbm.tables.Get(stmt.Table)
-	if !ok {
-		return ErrNoSuchTable
+func (tbm *tableManage) Update(tid uint64, stmt *sql.UpdateStmt) (n int, err error) {
+	t, err := tbm.getTable(tid, stmt.Table)
+	if err != nil {
+		return 0, err
 	}
 
 	if len(stmt.Where) == 0 {
-		return ErrMustHaveCondition
+		return 0, ErrMustHaveCondition
 	}
 
-	var (
-		raw  = make([]byte, 0)
-		rids = make([]uint64, 0)
-	)
-
-	// 查询解析
-	rids, err = t.parseWhere(stmt.Where)
+	// 读取数据
+	scan, err := t.access(tbm, tid, stmt.Where)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	op := &filterOp{child: scan, where: stmt.Where}
+	defer op.Close()
+	err = op.Open()
+	if err != nil {
+		return 0, err
 	}
 
-	// 读取数据
-	for _, rid := range rids {
-		raw, ok, err = tbm.verManage.Read(tid, rid)
+	// 更新之后的数据写入索引，可能在之后被读取到，需要跳过（否则会被重复更新）
+	written := make(map[uint64]bool)
+	for {
+		row, err := op.Next()
 		if err != nil {
-			return err
-		}
-		if !ok {
-			continue
+			return n, err
 		}
-
-		// 解析数据
-		row := t.wrapEntry(raw, stmt.Where)
 		if row == nil {
+			return n, nil
+		}
+		rid := scan.rid()
+		if written[rid] {
 			continue
 		}
 
 		// 删除数据
-		_, err = tbm.verManage.Delete(tid, rid)
+		ok, err := tbm.verManage.Delete(tid, rid)
 		if err != nil {
-			return err
+			return n, err
+		}
+		if !ok {
+			continue
 		}
+		tbm.purge.delete(tid, rowGarbage(t, row, rid))
 
 		// 更新数据
-		for k, v := range stmt.Value {
-			if _, ok = row[k]; ok {
-				row[k] = v
+		for _, f := range t.Fields {
+			if v, exist := stmt.Value[f.Name]; exist {
+				row[f.Name], err = f.formatVal(v)
+				if err != nil {
+					return n, err
+				}
 			}
 		}
-		raw, err = t.wrapRaw(row)
+
+		// 写入新版本（旧版本已经被当前事务删除，不会与自身冲突）
+		rid, err = tbm.insertRow(tid, t, row)
 		if err != nil {
-			return err
+			return n, err
 		}
-		rid, err = tbm.verManage.Write(tid, raw)
+		written[rid] = true
+		n++
+	}
+}
+
+// checkUnique 检查唯一索引的字段是否与已经存在的数据重复（NULL 不会重复）
+//
+// 读取数据的最新版本，而不是当前事务可见的版本（快照之后提交的数据也会重复）
+// 数据由其他未提交的事务写入或者删除时，返回写冲突（当前事务自动回滚，参考 ver.Manage 的 ReadLatest）
+// 索引中保存的键可能被截断，所以需要读取数据，比较完整的字段值（字段值相同时才读取最新版本）
+func (tbm *tableManage) checkUnique(tid uint64, t *table, row Entry) error {
+	for _, f := range t.Fields {
+		if !f.Unique || f.index == nil {
+			continue
+		}
+		key := t.uniqueKey(f, row)
+		if key == nil {
+			continue
+		}
+
+		rids, err := f.index.Search(key)
 		if err != nil {
 			return err
 		}
+		for _, rid := range rids {
+			// 先比较完整的字段值，只有前缀相同的数据不会产生写冲突
+			raw, ok, err := tbm.verManage.ReadAny(rid)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			old := t.uniqueKey(f, t.wrapEntry(raw, nil))
+			if old == nil || !bytes.Equal(old, key) {
+				continue
+			}
 
-		// 更新索引
-		for _, f := range t.Fields {
-			if f.TreeId != 0 {
-				v, exist := row[f.Name]
-				if !exist {
-					return NewError(ErrNotAllowNull, f.Name)
-				}
-
-				// 格式化索引字段
-				err = f.index.Insert(hash.Sum64(v), rid)
-				if err != nil {
-					return err
-				}
+			_, ok, err = tbm.verManage.ReadLatest(tid, rid)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return &DuplicateKeyError{Index: f.IndexName, Value: t.indexValue(f, row)}
 			}
 		}
 	}
-	return
+	return nil
 }
 
 // Select 查询数据
 func (tbm *tableManage) Select(tid uint64, stmt *sql.SelectStmt) ([]Entry, error) {
-	// 获取表对象
-	t, ok := tbm.tables.Get(stmt.Table)
-	if !ok {
-		return nil, ErrNoSuchTable
+	op, err := tbm.selectOp(tid, stmt)
+	if err != nil {
+		return nil, err
 	}
+	return drain(op)
+}
 
-	var (
-		err  error
-		rids = make([]uint64, 0)
-	)
+// Query 查询数据，同时返回查询结果的字段信息
+//
+// 字段信息和数据使用相同的表结构（分别调用 Select 和 Describe 时，两次调用之间表结构可能被其他事务修改）
+func (tbm *tableManage) Query(tid uint64, stmt *sql.SelectStmt) ([]*Column, []Entry, error) {
+	op, err := tbm.selectOp(tid, stmt)
+	if err != nil {
+		return nil, nil, err
+	}
+	entries, err := drain(op)
+	if err != nil {
+		return nil, nil, err
+	}
+	return describe(op.(*projectOp).cols), entries, nil
+}
+
+// selectOp 构造查询的算子树（参考 exec.go）
+func (tbm *tableManage) selectOp(tid uint64, stmt *sql.SelectStmt) (operator, error) {
+	if len(stmt.Join) != 0 {
+		return tbm.joinOp(tid, stmt)
+	}
+
+	// 获取表对象
+	t, err := tbm.getTable(tid, stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+	s := newSource(t, stmt.Alias)
 
 	// 查询条件
-	rids, err = t.parseWhere(stmt.Where)
+	where, err := s.where(stmt.Where)
+	if err != nil {
+		return nil, err
+	}
+	return query(s, stmt, func(order []*sql.SelectOrder) (operator, bool, error) {
+		scan, sorted, err := t.orderedAccess(tbm, tid, where, order)
+		if err != nil {
+			return nil, false, err
+		}
+		return &filterOp{child: scan, where: where}, sorted, nil
+	})
+}
+
+// source 查询的数据中的字段，get 用于获取表对象
+func (tbm *tableManage) source(stmt *sql.SelectStmt, get func(string) (*table, error)) (*source, error) {
+	if len(stmt.Join) != 0 {
+		j, err := newJoin(stmt, get)
+		if err != nil {
+			return nil, err
+		}
+		return j.src, nil
+	}
+	t, err := get(stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+	return newSource(t, stmt.Alias), nil
+}
+
+// inputFunc 构造读取数据的算子，order 为排序的字段（聚合查询时为空），返回的数据已经有序时 sorted 为 true
+type inputFunc func(order []*sql.SelectOrder) (op operator, sorted bool, err error)
+
+// query 在读取数据的算子之上添加分组、排序、分页和投影
+func query(s *source, stmt *sql.SelectStmt, input inputFunc) (operator, error) {
+	// 查询的字段
+	cols, err := s.columns(stmt.Field)
+	if err != nil {
+		return nil, err
+	}
+	g, err := s.grouping(stmt, cols)
 	if err != nil {
 		return nil, err
 	}
 
 	var (
-		raw  = make([]byte, 0)
-		rows = make([]Entry, 0)
+		op     operator
+		sorted bool
 	)
-
-	// 读取数据
-	for _, rid := range rids {
-		raw, ok, err = tbm.verManage.Read(tid, rid)
+	fields, order := s.fields, s.orderFields(cols, stmt.Order)
+	if g == nil {
+		op, sorted, err = input(order)
+	} else {
+		op, _, err = input(nil)
+		op = &filterOp{child: &aggregateOp{child: op, g: g}, where: stmt.Having}
+		fields, order = g.fields, stmt.Order
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(order) != 0 && !sorted {
+		op, err = newSortOp(op, fields, order, stmt.Limit)
 		if err != nil {
 			return nil, err
 		}
-		if !ok {
-			continue
-		}
+	}
+	op = newLimitOp(op, stmt.Limit)
+	return &projectOp{child: op, cols: cols}, nil
+}
 
-		// 解析数据
-		row := t.wrapEntry(raw, stmt.Where)
-		if row != nil {
-			rows = append(rows, row)
-		}
+func (tbm *tableManage) Columns(table string) ([]*Column, error) {
+	t, ok := tbm.tables.Get(table)
+	if !ok {
+		return nil, ErrNoSuchTable
+	}
+
+	cols := make([]*Column, 0)
+	for _, f := range t.Fields {
+		cols = append(cols, &Column{
+			Name: f.Name,
+			Type: f.Type,
+		})
 	}
-	return rows, nil
+	return cols, nil
 }
 
 func (tbm *tableManage) ShowTable() string {
@@ -371,7 +574,7 @@ func (tbm *tableManage) ShowTable() string {
 }
 
 func (tbm *tableManage) ShowField(table string) string {
-	head := []string{"Field", "Type", "Null", "Key", "Default"}
+	head := []string{"Field", "Type", "Null", "Key", "Default", "Extra"}
 	body := make([][]string, 0)
 	t, exist := tbm.tables.Get(table)
 	if !exist {
@@ -380,22 +583,29 @@ func (tbm *tableManage) ShowField(table string) string {
 
 	for _, f := range t.Fields {
 		indexed := ""
-		if f.TreeId != 0 {
+		if f.PrimaryKey {
+			indexed = "PRI"
+		} else if f.TreeId != 0 {
 			indexed = "YES"
-			if f.PrimaryKey {
-				indexed = "PRI"
+			if f.Unique {
+				indexed = "UNI"
 			}
 		}
 		nullable := "NO"
 		if f.Nullable {
 			nullable = "YES"
 		}
+		extra := ""
+		if f.SeqId != 0 {
+			extra = "AUTO_INCREMENT"
+		}
 		body = append(body, []string{
 			f.Name,
 			f.Type,
 			nullable,
 			indexed,
 			f.Default,
+			extra,
 		})
 	}
 
@@ -406,28 +616,26 @@ func (tbm *tableManage) ShowField(table string) string {
 	return vt.String()
 }
 
-func (tbm *tableManage) ShowResult(table string, entries []Entry) string {
-	// 获取表对象
-	t, ok := tbm.tables.Get(table)
-	if !ok {
+func (tbm *tableManage) ShowResult(stmt *sql.SelectStmt, entries []Entry) string {
+	cols, err := tbm.Describe(stmt)
+	if err != nil {
 		return ""
 	}
 
 	head := make([]string, 0)
 	body := make([][]string, 0)
 
-	for _, f := range t.Fields {
-		head = append(head, f.Name)
+	types := make([]sql.ColumnType, 0, len(cols))
+	for _, col := range cols {
+		head = append(head, col.Name)
+		typ, _ := sql.ParseColumnType(col.Type)
+		types = append(types, typ)
 	}
 
 	for _, ent := range entries {
 		row := make([]string, 0)
-		for _, f := range head {
-			val, exist := ent[f]
-			if !exist {
-				val = ""
-			}
-			row = append(row, fmt.Sprintf("%v", val))
+		for i, name := range head {
+			row = append(row, sql.FormatText(types[i], ent[name]))
 		}
 		body = append(body, row)
 	}
@@ -89,7 +89,7 @@ func TestTableManage_Insert(t *testing.T) {
 
 		// 插入数据
 		txId := tbm.Begin(0)
-		err = tbm.Insert(txId, stmt.(*sql.InsertStmt))
+		_, err = tbm.Insert(txId, stmt.(*sql.InsertStmt))
 		if err != nil {
 			t.Fatalf("%+v", err)
 		}
@@ -114,7 +114,7 @@ func TestTableManage_Delete(t *testing.T) {
 
 	// 插入数据
 	txId := tbm.Begin(0)
-	err = tbm.Delete(txId, stmt.(*sql.DeleteStmt))
+	_, err = tbm.Delete(txId, stmt.(*sql.DeleteStmt))
 	if err != nil {
 		t.Fatalf("%+v", err)
 	}
@@ -138,7 +138,7 @@ func TestTableManage_Update(t *testing.T) {
 
 	// 插入数据
 	txId := tbm.Begin(0)
-	err = tbm.Update(txId, stmt.(*sql.UpdateStmt))
+	_, err = tbm.Update(txId, stmt.(*sql.UpdateStmt))
 	if err != nil {
 		t.Fatalf("%+v", err)
 	}
@@ -171,7 +171,7 @@ func TestTableManage_Select(t *testing.T) {
 	}
 
 	// 展示字段
-	fmt.Println(tbm.ShowResult(stmt.TableName(), entries))
+	fmt.Println(tbm.ShowResult(stmt.(*sql.SelectStmt), entries))
 
 	// 释放资源
 	closeTbm()
@@ -197,7 +197,7 @@ func TestTableManage_Select1(t *testing.T) {
 	}
 
 	// 展示字段
-	fmt.Println(tbm.ShowResult(stmt.TableName(), entries))
+	fmt.Println(tbm.ShowResult(stmt.(*sql.SelectStmt), entries))
 
 	// 释放资源
 	closeTbm()
@@ -223,7 +223,42 @@ func TestTableManage_Select2(t *testing.T) {
 	}
 
 	// 展示字段
-	fmt.Println(tbm.ShowResult(stmt.TableName(), entries))
+	fmt.Println(tbm.ShowResult(stmt.(*sql.SelectStmt), entries))
+
+	// 释放资源
+	closeTbm()
+}
+
+func TestTableManage_SelectRange(t *testing.T) {
+	tbm := openTbm()
+
+	// 解析查询表语句（字符串范围查询）
+	stmt, err := sql.ParseSQL(`select * from user where account >= "账号3" and account < "账号6";`)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	txId := tbm.Begin(0)
+	entries, err := tbm.Select(txId, stmt.(*sql.SelectStmt))
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+	err = tbm.Commit(txId)
+	if err != nil {
+		t.Fatalf("%+v", err)
+	}
+
+	// 展示字段
+	fmt.Println(tbm.ShowResult(stmt.(*sql.SelectStmt), entries))
+	if len(entries) != 3 {
+		t.Fatalf("select range error %d", len(entries))
+	}
+	for _, ent := range entries {
+		account := ent["account"].(string)
+		if account < "账号3" || account >= "账号6" {
+			t.Fatalf("select range error %s", account)
+		}
+	}
 
 	// 释放资源
 	closeTbm()
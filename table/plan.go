@@ -0,0 +1,152 @@
+package table
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/ggymm/db/index"
+	"github.com/ggymm/db/pkg/sql"
+)
+
+// 访问路径
+//
+// 根据查询条件，在以下方式中选择代价最小的方式读取数据：
+// 全表扫描：遍历主键索引或者堆，读取全部数据
+// 索引扫描：读取一个索引中满足条件的区间，根据索引项读取数据
+// 索引合并：读取多个索引中满足条件的区间，取索引项的交集之后读取数据
+//
+// 代价以读取的数据量估算，数据量来自统计信息（参考 stats.go）
+// 没有统计信息时，使用默认的数据量和选择率（等值条件优先使用唯一索引）
+
+const (
+	seqCost    = 1.0 // 全表扫描时读取一条数据
+	randomCost = 1.5 // 根据索引项读取一条数据
+	entryCost  = 0.1 // 读取一个索引项
+
+	defaultRows   = 1000 // 没有统计信息时表的数据量
+	defaultEqual  = 0.05 // 没有统计信息时等值条件的选择率
+	defaultRange  = 0.25 // 没有统计信息时范围条件的选择率
+	maxIntersects = 3    // 索引合并时最多使用的索引数量
+)
+
+type plan struct {
+	scans []*indexScan // 为空时全表扫描
+	rows  float64      // 估算读取的数据量
+	cost  float64
+}
+
+// indexScan 读取索引中的区间
+type indexScan struct {
+	f    *field
+	rs   []*Interval
+	keys float64 // 估算读取的索引项数量
+}
+
+// rows 表的数据量
+func (t *table) rows() float64 {
+	if t.stats != nil {
+		return float64(max(t.stats.Rows, 1))
+	}
+	return defaultRows
+}
+
+// estimate 估算区间内的索引项数量
+func (t *table) estimate(f *field, rs []*Interval) float64 {
+	if t.stats != nil {
+		if is, ok := t.stats.Indexes[f.TreeId]; ok {
+			return is.estimate(rs)
+		}
+	}
+
+	rows := t.rows()
+	n := float64(0)
+	for _, r := range rs {
+		switch {
+		case index.CompareKey(r.Min, index.MinKey()) == 0 && index.CompareKey(r.Max, index.MaxKey()) == 0:
+			n += rows
+		case f.Unique && len(f.IndexCols) == 0 && index.CompareKey(r.Min, r.Max) == 0:
+			n += 1
+		case index.CompareKey(r.Min, r.Max) == 0:
+			n += rows * defaultEqual
+		default:
+			n += rows * defaultRange
+		}
+	}
+	return min(n, rows)
+}
+
+// plan 选择代价最小的访问路径
+//
+// 索引合并时，按照索引项数量从小到大依次加入索引，直到代价不再减少
+// 合并之后的数据量按照条件相互独立估算
+func (t *table) plan(where []sql.SelectWhere) (*plan, error) {
+	rows := t.rows()
+	best := &plan{
+		rows: rows,
+		cost: rows * seqCost,
+	}
+	if len(where) == 0 {
+		return best, nil
+	}
+
+	// 解析每个索引的区间
+	scans := make([]*indexScan, 0)
+	for _, f := range t.Fields {
+		if f.index == nil {
+			continue
+		}
+		var (
+			rs  []*Interval
+			err error
+		)
+		if len(f.IndexCols) != 0 {
+			rs, err = newExplain().composite(t.indexFields(f), where)
+		} else {
+			rs, err = newExplain().execute(f, where)
+		}
+		if err != nil {
+			if errors.Is(err, ErrNotIndex) {
+				continue
+			}
+			return nil, err
+		}
+		scans = append(scans, &indexScan{
+			f:    f,
+			rs:   rs,
+			keys: t.estimate(f, rs),
+		})
+	}
+	slices.SortStableFunc(scans, func(a, b *indexScan) int {
+		if a.keys < b.keys {
+			return -1
+		}
+		if a.keys > b.keys {
+			return 1
+		}
+		return 0
+	})
+
+	// 依次加入索引
+	keys := float64(0)
+	sel := float64(1)
+	for i, s := range scans {
+		if i == maxIntersects {
+			break
+		}
+		keys += s.keys
+		sel *= s.keys / rows
+		p := &plan{
+			scans: scans[:i+1],
+			rows:  rows * sel,
+			cost:  keys*entryCost + rows*sel*randomCost,
+		}
+		if p.cost >= best.cost {
+			if i == 0 {
+				continue
+			}
+			break
+		}
+		best = p
+	}
+	return best, nil
+}
@@ -0,0 +1,225 @@
+package table
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ggymm/db/pkg/sql"
+)
+
+// 投影
+//
+// 查询结果只包含查询的字段，按照查询的顺序排列，* 展开为表中的全部字段（参考 exec.go 中的 projectOp）
+// 字段可以使用 AS 指定别名，查询结果中使用别名作为字段名称，ORDER BY 中可以使用别名
+//
+// 查询结果的数据使用字段名称保存，因此不允许相同名称的字段对应不同的值（例如 SELECT name AS id, id）
+// 聚合函数没有别名时，使用 COUNT(*)、SUM(age) 形式的名称（参考 aggregate.go）
+
+// source 查询的数据中的字段
+//
+// 单表查询时为表中的字段，连接查询时为全部表中的字段（使用 表别名.字段名称 的形式，参考 join.go）
+type source struct {
+	fields []*field            // 数据中的全部字段
+	names  map[string]*field   // 查询中可以使用的名称对应的字段，有歧义的名称对应 nil
+	tables map[string][]*field // 表别名对应的字段（用于展开 别名.*）
+}
+
+// newSource 单表查询的字段，字段也可以使用 别名.字段名称 的形式（没有别名时使用表名）
+func newSource(t *table, alias string) *source {
+	if alias == "" {
+		alias = t.Name
+	}
+	s := &source{
+		fields: t.Fields,
+		names:  make(map[string]*field, 2*len(t.Fields)),
+		tables: map[string][]*field{alias: t.Fields},
+	}
+	for _, f := range t.Fields {
+		s.names[f.Name] = f
+		s.names[alias+"."+f.Name] = f
+	}
+	return s
+}
+
+// field 根据名称获取字段
+func (s *source) field(name string) (*field, error) {
+	f, ok := s.names[name]
+	switch {
+	case !ok:
+		return nil, NewError(ErrNoSuchField, name)
+	case f == nil:
+		return nil, NewError(ErrAmbiguousColumn, name)
+	}
+	return f, nil
+}
+
+// where 将条件中的名称替换为数据中的字段名称
+//
+// 有歧义的名称返回错误，不存在的名称（包括聚合函数）保持不变
+func (s *source) where(where []sql.SelectWhere) ([]sql.SelectWhere, error) {
+	var err error
+	res := renameWhere(where, func(name string) string {
+		f, ok := s.names[name]
+		switch {
+		case !ok:
+			return name
+		case f == nil:
+			err = NewError(ErrAmbiguousColumn, name)
+			return name
+		}
+		return f.Name
+	})
+	return res, err
+}
+
+// renameWhere 复制条件并替换字段名称
+func renameWhere(where []sql.SelectWhere, rename func(string) string) []sql.SelectWhere {
+	if where == nil {
+		return nil
+	}
+
+	res := make([]sql.SelectWhere, 0, len(where))
+	for _, w := range where {
+		switch cond := w.(type) {
+		case *sql.SelectWhereExpr:
+			res = append(res, &sql.SelectWhereExpr{
+				Negation: cond.Negation,
+				Cnf:      renameWhere(cond.Cnf, rename),
+			})
+		case *sql.SelectWhereField:
+			nc := *cond
+			nc.Field = rename(cond.Field)
+			res = append(res, &nc)
+		case *sql.SelectWhereNull:
+			nc := *cond
+			nc.Field = rename(cond.Field)
+			res = append(res, &nc)
+		default:
+			res = append(res, w)
+		}
+	}
+	return res
+}
+
+// column 查询结果中的字段
+//
+// f 为数据中对应的字段，聚合函数时为聚合结果的字段
+type column struct {
+	name string
+	f    *field
+	agg  *aggregate
+}
+
+// columns 解析查询的字段
+func (s *source) columns(fs []*sql.SelectField) ([]*column, error) {
+	cols := make([]*column, 0, len(fs))
+	seen := make(map[string]string)
+	add := func(name string, f *field, agg *aggregate) error {
+		if o, ok := seen[name]; ok && o != f.Name {
+			return NewError(ErrAmbiguousColumn, name)
+		}
+		seen[name] = f.Name
+		cols = append(cols, &column{name: name, f: f, agg: agg})
+		return nil
+	}
+
+	for _, sf := range fs {
+		if sf.Func != "" {
+			agg, err := s.aggregate(sf.Func, sf.Name)
+			if err != nil {
+				return nil, err
+			}
+			name := agg.out.Name
+			if sf.Alias != "" {
+				name = sf.Alias
+			}
+			err = add(name, agg.out, agg)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if sf.Name == "*" || strings.HasSuffix(sf.Name, ".*") {
+			if sf.Alias != "" {
+				return nil, NewError(ErrAliasAll, sf.Alias)
+			}
+			all := s.fields
+			if sf.Name != "*" {
+				var ok bool
+				all, ok = s.tables[strings.TrimSuffix(sf.Name, ".*")]
+				if !ok {
+					return nil, NewError(ErrNoSuchField, sf.Name)
+				}
+			}
+			for _, f := range all {
+				err := add(f.Name, f, nil)
+				if err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		f, err := s.field(sf.Name)
+		if err != nil {
+			return nil, err
+		}
+		name := sf.Name
+		if sf.Alias != "" {
+			name = sf.Alias
+		}
+		err = add(name, f, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cols, nil
+}
+
+// orderFields 将 ORDER BY 中的别名和没有限定的名称替换为数据中的字段名称
+func (s *source) orderFields(cols []*column, order []*sql.SelectOrder) []*sql.SelectOrder {
+	res := make([]*sql.SelectOrder, 0, len(order))
+	for _, o := range order {
+		no := *o
+		i := slices.IndexFunc(cols, func(c *column) bool { return c.name == o.Field })
+		if i != -1 {
+			no.Field = cols[i].f.Name
+		} else if f, err := s.field(o.Field); err == nil {
+			no.Field = f.Name
+		}
+		res = append(res, &no)
+	}
+	return res
+}
+
+// Describe 查询结果的字段信息
+func (tbm *tableManage) Describe(stmt *sql.SelectStmt) ([]*Column, error) {
+	s, err := tbm.source(stmt, func(name string) (*table, error) {
+		t, ok := tbm.tables.Get(name)
+		if !ok {
+			return nil, ErrNoSuchTable
+		}
+		return t, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	cols, err := s.columns(stmt.Field)
+	if err != nil {
+		return nil, err
+	}
+	return describe(cols), nil
+}
+
+// describe 查询的字段转换为字段信息
+func describe(cols []*column) []*Column {
+	res := make([]*Column, 0, len(cols))
+	for _, c := range cols {
+		res = append(res, &Column{
+			Name: c.name,
+			Type: c.f.Type,
+		})
+	}
+	return res
+}
@@ -0,0 +1,152 @@
+package table
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/ggymm/db/index"
+)
+
+// purge 清理索引中不再使用的数据版本
+//
+// 删除或者更新数据时，旧版本的索引项不会立即删除（其他事务可能仍然可以读取旧版本）
+// 事务提交之后，等到在提交之前开始的事务全部结束，旧版本对任何事务都不可见，此时从索引中删除
+// 事务回滚时，当前事务写入的新版本不会被任何事务读取，立即从索引中删除
+//
+// 待清理的索引项只保存在内存中，重启之后没有清理的索引项会一直保留（查询时会判断数据是否可见）
+type purge struct {
+	sync.Mutex
+
+	last    uint64          // 最后开始的事务
+	active  map[uint64]bool // 正在执行的事务
+	deleted map[uint64][]*garbage
+	written map[uint64][]*garbage
+	pending []*garbageGroup // 已经提交的事务删除的旧版本
+}
+
+// garbage 索引项
+type garbage struct {
+	tree index.Index
+	key  []byte
+	rid  uint64
+}
+
+// garbageGroup 同一个事务提交之后等待清理的索引项
+//
+// mark 为事务提交时最后开始的事务，不存在小于等于 mark 的活跃事务时可以清理
+type garbageGroup struct {
+	mark  uint64
+	items []*garbage
+}
+
+func newPurge() *purge {
+	return &purge{
+		active:  make(map[uint64]bool),
+		deleted: make(map[uint64][]*garbage),
+		written: make(map[uint64][]*garbage),
+	}
+}
+
+// rowGarbage 数据版本在全部索引中的索引项
+func rowGarbage(t *table, row Entry, rid uint64) []*garbage {
+	items := make([]*garbage, 0)
+	for _, f := range t.Fields {
+		if f.index == nil {
+			continue
+		}
+		key := t.indexKey(f, row)
+		if key == nil {
+			continue
+		}
+		items = append(items, &garbage{
+			tree: f.index,
+			key:  key,
+			rid:  rid,
+		})
+	}
+	return items
+}
+
+func (p *purge) begin(tid uint64) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.last = max(p.last, tid)
+	p.active[tid] = true
+}
+
+// delete 记录事务删除的旧版本
+func (p *purge) delete(tid uint64, items []*garbage) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.deleted[tid] = append(p.deleted[tid], items...)
+}
+
+// write 记录事务写入的新版本
+func (p *purge) write(tid uint64, items []*garbage) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.written[tid] = append(p.written[tid], items...)
+}
+
+// end 结束事务，返回可以清理的索引项
+func (p *purge) end(tid uint64, commit bool) []*garbage {
+	p.Lock()
+	defer p.Unlock()
+
+	items := make([]*garbage, 0)
+	if commit {
+		if len(p.deleted[tid]) != 0 {
+			p.pending = append(p.pending, &garbageGroup{
+				mark:  p.last,
+				items: p.deleted[tid],
+			})
+		}
+	} else {
+		items = append(items, p.written[tid]...)
+	}
+	delete(p.active, tid)
+	delete(p.deleted, tid)
+	delete(p.written, tid)
+
+	// 最早开始的活跃事务
+	first := uint64(0)
+	for id := range p.active {
+		if first == 0 || id < first {
+			first = id
+		}
+	}
+	i := 0
+	for ; i < len(p.pending); i++ {
+		if first != 0 && first <= p.pending[i].mark {
+			break
+		}
+		items = append(items, p.pending[i].items...)
+	}
+	p.pending = p.pending[i:]
+	return items
+}
+
+// discard 丢弃已经释放的索引中的索引项
+func (p *purge) discard(trees []index.Index) {
+	if len(trees) == 0 {
+		return
+	}
+	p.Lock()
+	defer p.Unlock()
+
+	dropped := func(g *garbage) bool {
+		return slices.Contains(trees, g.tree)
+	}
+	for tid, items := range p.deleted {
+		p.deleted[tid] = slices.DeleteFunc(items, dropped)
+	}
+	for tid, items := range p.written {
+		p.written[tid] = slices.DeleteFunc(items, dropped)
+	}
+	for _, g := range p.pending {
+		g.items = slices.DeleteFunc(g.items, dropped)
+	}
+}
@@ -0,0 +1,136 @@
+package table
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/ggymm/db"
+	"github.com/ggymm/db/boot"
+	"github.com/ggymm/db/data"
+	"github.com/ggymm/db/index"
+	"github.com/ggymm/db/pkg/sql"
+	"github.com/ggymm/db/tx"
+	"github.com/ggymm/db/ver"
+)
+
+type mockTree struct {
+	index.Index
+}
+
+func rids(items []*garbage) []uint64 {
+	res := make([]uint64, 0)
+	for _, g := range items {
+		res = append(res, g.rid)
+	}
+	return res
+}
+
+func TestPurge(t *testing.T) {
+	p := newPurge()
+	a, b := &mockTree{}, &mockTree{}
+
+	p.begin(1)
+	p.begin(2)
+	p.delete(2, []*garbage{{tree: a, rid: 10}, {tree: b, rid: 10}})
+	p.write(2, []*garbage{{tree: a, rid: 11}})
+
+	// 事务 1 在事务 2 提交之前开始，仍然可以读取旧版本
+	if items := p.end(2, true); len(items) != 0 {
+		t.Fatalf("purge before reader ends %v", rids(items))
+	}
+	p.begin(3)
+	p.delete(3, []*garbage{{tree: a, rid: 20}})
+	p.write(3, []*garbage{{tree: a, rid: 21}})
+
+	// 事务回滚，写入的新版本立即清理
+	if items := p.end(3, false); !slices.Equal(rids(items), []uint64{21}) {
+		t.Fatalf("rollback items %v", rids(items))
+	}
+
+	// 事务 4 在事务 2 提交之后开始，不影响清理
+	p.begin(4)
+	if items := p.end(1, true); !slices.Equal(rids(items), []uint64{10, 10}) {
+		t.Fatalf("commit items %v", rids(items))
+	}
+
+	// 释放的索引中的索引项被丢弃
+	p.delete(4, []*garbage{{tree: a, rid: 30}, {tree: b, rid: 31}})
+	p.discard([]index.Index{a})
+	if items := p.end(4, true); !slices.Equal(rids(items), []uint64{31}) {
+		t.Fatalf("discard items %v", rids(items))
+	}
+}
+
+func TestTableManage_Purge(t *testing.T) {
+	opt := db.NewOption(db.RunPath(), "temp/table_purge")
+	opt.Memory = (1 << 20) * 64
+	err := os.RemoveAll(opt.Path)
+	if err != nil {
+		t.Fatalf("remove err %v", err)
+	}
+	tm := tx.NewManager(opt)
+	dm := data.NewManage(tm, opt)
+	defer tm.Close()
+	defer dm.Close()
+	tbm := NewManage(boot.New(opt), ver.NewManage(tm, dm), dm).(*tableManage)
+
+	exec := func(tid uint64, in string) {
+		stmt, err := sql.ParseSQL(in)
+		if err != nil {
+			t.Fatalf("parse %s err %v", in, err)
+		}
+		switch stmt := stmt.(type) {
+		case *sql.CreateStmt:
+			err = tbm.Create(tid, stmt)
+		case *sql.InsertStmt:
+			_, err = tbm.Insert(tid, stmt)
+		case *sql.UpdateStmt:
+			_, err = tbm.Update(tid, stmt)
+		case *sql.DeleteStmt:
+			_, err = tbm.Delete(tid, stmt)
+		}
+		if err != nil {
+			t.Fatalf("exec %s err %v", in, err)
+		}
+	}
+	count := func(name string) int {
+		tb, _ := tbm.tables.Get("user")
+		rs, err := tb.field(name).index.SearchRange(index.MinKey(), index.MaxKey())
+		if err != nil {
+			t.Fatalf("search err %v", err)
+		}
+		return len(rs)
+	}
+
+	tid := tbm.Begin(1)
+	exec(tid, "CREATE TABLE user (id INT64, name VARCHAR, PRIMARY KEY (id), INDEX name_idx (name));")
+	for i := 0; i < 10; i++ {
+		exec(tid, fmt.Sprintf("INSERT INTO user (id, name) VALUE (%d, 'name_%d');", i, i))
+	}
+	_ = tbm.Commit(tid)
+
+	// 读取数据的事务结束之前，旧版本的索引项不会被删除
+	reader := tbm.Begin(1)
+	tid = tbm.Begin(1)
+	exec(tid, "UPDATE user SET name = 'x' WHERE id < 5;")
+	exec(tid, "DELETE FROM user WHERE id = 9;")
+	_ = tbm.Commit(tid)
+	if count("id") != 15 || count("name") != 15 {
+		t.Fatalf("count %d %d", count("id"), count("name"))
+	}
+	tbm.Rollback(reader)
+	if count("id") != 9 || count("name") != 9 {
+		t.Fatalf("count %d %d", count("id"), count("name"))
+	}
+
+	// 回滚的事务写入的索引项立即删除
+	tid = tbm.Begin(1)
+	exec(tid, "UPDATE user SET name = 'y' WHERE id >= 5;")
+	exec(tid, "INSERT INTO user (id, name) VALUE (20, 'name_20');")
+	tbm.Rollback(tid)
+	if count("id") != 9 || count("name") != 9 {
+		t.Fatalf("count %d %d", count("id"), count("name"))
+	}
+}
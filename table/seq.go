@@ -0,0 +1,83 @@
+package table
+
+import (
+	"sync"
+
+	"github.com/ggymm/db/data"
+	"github.com/ggymm/db/pkg/bin"
+	"github.com/ggymm/db/tx"
+)
+
+// sequence 自增序列
+//
+// +----------------+
+// |     value      |
+// +----------------+
+// |     8 bytes    |
+// +----------------+
+//
+// value: 最后一次分配的值
+//
+// 序列保存在一个 data item 中，与索引的根节点相同，直接修改 item 并使用 tx.Super 记录日志
+// 因此序列不受事务控制，回滚的事务已经分配的值不会被再次使用
+type sequence struct {
+	sync.Mutex
+	item data.Item
+}
+
+func newSequence(dm data.Manage) (*sequence, uint64, error) {
+	id, err := dm.Write(tx.Super, bin.Uint64Raw(0))
+	if err != nil {
+		return nil, 0, err
+	}
+	s, err := openSequence(dm, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s, id, nil
+}
+
+func openSequence(dm data.Manage, id uint64) (*sequence, error) {
+	item, ok, err := dm.Read(id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNoSuchSequence
+	}
+	return &sequence{
+		item: item,
+	}, nil
+}
+
+// next 分配下一个值
+func (s *sequence) next() int64 {
+	s.Lock()
+	defer s.Unlock()
+
+	v := int64(bin.Uint64(s.item.DataBody())) + 1
+	s.store(v)
+	return v
+}
+
+// update 插入的值大于序列的值时，更新序列（后续分配的值从 v + 1 开始）
+func (s *sequence) update(v int64) {
+	s.Lock()
+	defer s.Unlock()
+
+	if v > int64(bin.Uint64(s.item.DataBody())) {
+		s.store(v)
+	}
+}
+
+// drop 释放序列
+func (s *sequence) drop(dm data.Manage) error {
+	s.item.Release()
+	return dm.Free(s.item.Id())
+}
+
+func (s *sequence) store(v int64) {
+	s.item.Before()
+	copy(s.item.DataBody(), bin.Uint64Raw(uint64(v)))
+	s.item.After(tx.Super)
+}
@@ -0,0 +1,353 @@
+package table
+
+import (
+	"bufio"
+	"cmp"
+	"container/heap"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ggymm/db/pkg/bin"
+	"github.com/ggymm/db/pkg/sql"
+)
+
+// 排序和分页
+//
+// 查询结果按照 ORDER BY 的字段依次比较，之后跳过 OFFSET 条数据，返回 LIMIT 条数据（参考 exec.go 中的 limitOp）
+// NULL 小于任何值（升序时在最前，降序时在最后），比较结果相同的数据保持读取的顺序
+//
+// 需要的数据量（OFFSET + LIMIT）不超过 topLimit 时，使用堆只保留最小的数据
+// 否则在内存中缓存数据，超过 sortBuffer 条时排序之后写入临时文件，读取时多路归并
+//
+// 临时文件中的数据格式：
+// +----------------+----------------+----------------+----------------+
+// |     length     |     field1     |     field2     |      ...       |
+// +----------------+----------------+----------------+----------------+
+// |     4 bytes    |                |                |                |
+// +----------------+----------------+----------------+----------------+
+// field: 与写入表中的字段值格式相同（参考 field.wrapRaw），按照 fields 的顺序保存
+//
+// fields 为数据中的全部字段，可以是表中的字段，也可以是聚合等计算得到的字段
+
+const (
+	sortBuffer = 1 << 14 // 内存中排序的最大数据量
+	topLimit   = 1 << 10 // 使用堆时 OFFSET + LIMIT 的最大值
+)
+
+type sorter struct {
+	fields []*field
+	order  []*sql.SelectOrder
+	limit  int // 需要的数据量（OFFSET + LIMIT），小于 0 时没有限制
+	buffer int
+
+	seq  int
+	rows []*sortRow
+	top  *topHeap
+	runs []*os.File // 已经排序的临时文件
+
+	merge *mergeHeap // 归并临时文件和内存中的数据
+	pos   int        // 已经读取的数据量
+}
+
+// sortRow 数据和读取的顺序
+type sortRow struct {
+	row Entry
+	seq int
+}
+
+func newSorter(fields []*field, order []*sql.SelectOrder, limit int) (*sorter, error) {
+	for _, o := range order {
+		if !slices.ContainsFunc(fields, func(f *field) bool { return f.Name == o.Field }) {
+			return nil, NewError(ErrNoSuchField, o.Field)
+		}
+	}
+
+	s := &sorter{
+		fields: fields,
+		order:  order,
+		limit:  limit,
+		buffer: sortBuffer,
+		rows:   make([]*sortRow, 0),
+	}
+	if len(order) != 0 && s.limit >= 0 && s.limit <= topLimit {
+		s.top = &topHeap{s: s}
+	}
+	return s, nil
+}
+
+// compareValue 比较相同类型的字段值（NULL 小于任何值）
+func compareValue(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	switch v := a.(type) {
+	case int32:
+		return cmp.Compare(v, b.(int32))
+	case int64:
+		return cmp.Compare(v, b.(int64))
+	case float64:
+		return cmp.Compare(v, b.(float64))
+	case bool:
+		switch {
+		case v == b.(bool):
+			return 0
+		case v:
+			return 1
+		}
+		return -1
+	case sql.Dec:
+		return v.Compare(b.(sql.Dec))
+	case time.Time:
+		return v.Compare(b.(time.Time))
+	case string:
+		return strings.Compare(v, b.(string))
+	}
+	return 0
+}
+
+// compare 比较两条数据（相同时按照读取的顺序）
+func (s *sorter) compare(a, b *sortRow) int {
+	for _, o := range s.order {
+		c := compareValue(a.row[o.Field], b.row[o.Field])
+		if c != 0 {
+			if !o.Asc {
+				return -c
+			}
+			return c
+		}
+	}
+	return cmp.Compare(a.seq, b.seq)
+}
+
+func (s *sorter) add(row Entry) error {
+	if s.limit == 0 {
+		return nil
+	}
+	r := &sortRow{row: row, seq: s.seq}
+	s.seq++
+
+	if s.top != nil {
+		s.top.add(r)
+		return nil
+	}
+	s.rows = append(s.rows, r)
+	if len(s.order) != 0 && len(s.rows) >= s.buffer {
+		return s.spill()
+	}
+	return nil
+}
+
+// spill 排序内存中的数据，写入临时文件
+func (s *sorter) spill() error {
+	slices.SortFunc(s.rows, s.compare)
+
+	f, err := os.CreateTemp("", "db-sort-*")
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, f)
+
+	w := bufio.NewWriter(f)
+	for _, r := range s.rows {
+		raw := make([]byte, 0)
+		for _, f := range s.fields {
+			raw = append(raw, f.wrapRaw(r.row[f.Name])...)
+		}
+		_, err = w.Write(bin.Uint32Raw(uint32(len(raw))))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(raw)
+		if err != nil {
+			return err
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	s.rows = s.rows[:0]
+	return nil
+}
+
+// sort 添加数据完成，排序之后通过 next 读取
+func (s *sorter) sort() error {
+	if s.top != nil {
+		s.rows = s.top.rows
+		s.top = nil
+	}
+	if len(s.order) != 0 {
+		slices.SortFunc(s.rows, s.compare)
+	}
+	if len(s.runs) == 0 {
+		return nil
+	}
+
+	// 归并临时文件和内存中的数据
+	s.merge = &mergeHeap{s: s}
+	for i, f := range s.runs {
+		src := &runReader{fields: s.fields, r: bufio.NewReader(f), seq: i}
+		ok, err := src.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			s.merge.items = append(s.merge.items, src)
+		}
+	}
+	if len(s.rows) != 0 {
+		src := &runReader{rows: s.rows, seq: len(s.runs)}
+		_, _ = src.next()
+		s.merge.items = append(s.merge.items, src)
+		s.rows = nil
+	}
+	heap.Init(s.merge)
+	return nil
+}
+
+// next 按照顺序读取数据（读取到 OFFSET + LIMIT 条数据时停止），没有更多的数据时返回 nil
+func (s *sorter) next() (Entry, error) {
+	if s.limit >= 0 && s.pos >= s.limit {
+		return nil, nil
+	}
+	if s.merge == nil {
+		if s.pos == len(s.rows) {
+			return nil, nil
+		}
+		r := s.rows[s.pos]
+		s.rows[s.pos] = nil
+		s.pos++
+		return r.row, nil
+	}
+
+	m := s.merge
+	if m.Len() == 0 {
+		return nil, nil
+	}
+	src := m.items[0]
+	row := src.cur.row
+	ok, err := src.next()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		heap.Fix(m, 0)
+	} else {
+		heap.Pop(m)
+	}
+	s.pos++
+	return row, nil
+}
+
+// close 删除临时文件
+func (s *sorter) close() {
+	for _, f := range s.runs {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+	s.runs = nil
+	s.merge = nil
+}
+
+// topHeap 保留最小的 limit 条数据（堆顶为最大的数据）
+type topHeap struct {
+	s    *sorter
+	rows []*sortRow
+}
+
+func (h *topHeap) add(r *sortRow) {
+	if len(h.rows) < h.s.limit {
+		heap.Push(h, r)
+		return
+	}
+	if h.s.compare(r, h.rows[0]) < 0 {
+		h.rows[0] = r
+		heap.Fix(h, 0)
+	}
+}
+
+func (h *topHeap) Len() int           { return len(h.rows) }
+func (h *topHeap) Less(i, j int) bool { return h.s.compare(h.rows[i], h.rows[j]) > 0 }
+func (h *topHeap) Swap(i, j int)      { h.rows[i], h.rows[j] = h.rows[j], h.rows[i] }
+func (h *topHeap) Push(x any)         { h.rows = append(h.rows, x.(*sortRow)) }
+func (h *topHeap) Pop() any {
+	n := len(h.rows) - 1
+	r := h.rows[n]
+	h.rows = h.rows[:n]
+	return r
+}
+
+// runReader 按照顺序读取临时文件（或者内存）中的数据
+//
+// 临时文件中的数据不保存读取的顺序，使用文件的顺序代替（先写入的文件中的数据先读取）
+type runReader struct {
+	fields []*field
+	r      *bufio.Reader
+	seq    int
+
+	rows []*sortRow
+	cur  *sortRow
+}
+
+func (rr *runReader) next() (bool, error) {
+	if rr.r == nil {
+		if len(rr.rows) == 0 {
+			return false, nil
+		}
+		rr.cur = &sortRow{row: rr.rows[0].row, seq: rr.seq}
+		rr.rows = rr.rows[1:]
+		return true, nil
+	}
+
+	head := make([]byte, 4)
+	_, err := io.ReadFull(rr.r, head)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	raw := make([]byte, bin.Uint32(head))
+	_, err = io.ReadFull(rr.r, raw)
+	if err != nil {
+		return false, err
+	}
+	row := make(Entry, len(rr.fields))
+	pos := 0
+	for _, f := range rr.fields {
+		val, shift := f.parseRaw(raw[pos:])
+		pos += shift
+		row[f.Name] = val
+	}
+	rr.cur = &sortRow{row: row, seq: rr.seq}
+	return true, nil
+}
+
+type mergeHeap struct {
+	s     *sorter
+	items []*runReader
+}
+
+func (h *mergeHeap) Len() int           { return len(h.items) }
+func (h *mergeHeap) Less(i, j int) bool { return h.s.compare(h.items[i].cur, h.items[j].cur) < 0 }
+func (h *mergeHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *mergeHeap) Push(x any)         { h.items = append(h.items, x.(*runReader)) }
+func (h *mergeHeap) Pop() any {
+	n := len(h.items) - 1
+	r := h.items[n]
+	h.items = h.items[:n]
+	return r
+}
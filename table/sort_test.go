@@ -0,0 +1,102 @@
+package table
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/ggymm/db/pkg/sql"
+)
+
+func TestSorter(t *testing.T) {
+	tb := &table{
+		all: []*field{
+			{Name: "id", typ: sql.ColumnType{Type: sql.Int64}},
+			{Name: "name", typ: sql.ColumnType{Type: sql.Varchar}, Nullable: true},
+			{Name: "score", typ: sql.ColumnType{Type: sql.Double}},
+		},
+	}
+	tb.init()
+
+	r := rand.New(rand.NewSource(1))
+	rows := make([]Entry, 0)
+	for i := 0; i < 1000; i++ {
+		row := Entry{
+			"id":    int64(i),
+			"score": float64(r.Intn(50)),
+			"name":  fmt.Sprintf("n%d", r.Intn(20)),
+		}
+		if i%7 == 0 {
+			row["name"] = nil
+		}
+		rows = append(rows, row)
+	}
+
+	// 按照 name 降序、score 升序排序，相同时按照 id（读取的顺序）
+	order := []*sql.SelectOrder{{Field: "name"}, {Field: "score", Asc: true}}
+	want := slices.Clone(rows)
+	slices.SortStableFunc(want, func(a, b Entry) int {
+		if c := compareValue(b["name"], a["name"]); c != 0 {
+			return c
+		}
+		return compareValue(a["score"], b["score"])
+	})
+
+	for _, c := range []struct {
+		limit  *sql.SelectLimit
+		buffer int
+	}{
+		{nil, sortBuffer},
+		{nil, 64},
+		{&sql.SelectLimit{Limit: 10, Offset: 5}, 64},
+		{&sql.SelectLimit{Limit: 2000, Offset: 990}, 64},
+		{&sql.SelectLimit{Limit: 300, Offset: 800}, 100},
+		{&sql.SelectLimit{Limit: 0}, 64},
+	} {
+		op, err := newSortOp(&rowsOp{rows: rows}, tb.Fields, order, c.limit)
+		if err != nil {
+			t.Fatalf("new sort err %v", err)
+		}
+		op.s.buffer = c.buffer
+		lop := newLimitOp(op, c.limit)
+		err = lop.Open()
+		if err != nil {
+			t.Fatalf("open err %v", err)
+		}
+		got := make([]Entry, 0)
+		for {
+			row, err := lop.Next()
+			if err != nil {
+				t.Fatalf("next err %v", err)
+			}
+			if row == nil {
+				break
+			}
+			got = append(got, row)
+		}
+		names := make([]string, 0, len(op.s.runs))
+		for _, f := range op.s.runs {
+			names = append(names, f.Name())
+		}
+		lop.Close()
+		for _, name := range names {
+			if _, err = os.Stat(name); !os.IsNotExist(err) {
+				t.Fatalf("temp file %s not removed", name)
+			}
+		}
+
+		exp := want
+		if c.limit != nil {
+			exp = exp[min(c.limit.Offset, len(exp)):min(c.limit.Offset+c.limit.Limit, len(exp))]
+		}
+		if fmt.Sprint(got) != fmt.Sprint(exp) {
+			t.Fatalf("limit %+v buffer %d: got %d rows, want %d rows", c.limit, c.buffer, len(got), len(exp))
+		}
+	}
+
+	if _, err := newSorter(tb.Fields, []*sql.SelectOrder{{Field: "nothing"}}, -1); err == nil {
+		t.Fatalf("order by unknown field should fail")
+	}
+}
@@ -0,0 +1,319 @@
+package table
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/ggymm/db/index"
+	"github.com/ggymm/db/pkg/sql"
+)
+
+// 统计信息
+//
+// 执行 ANALYZE TABLE 时，根据当前事务可见的数据，计算表的数据量和每个索引的统计信息
+// 统计信息保存在单独的记录中，表信息中保存记录的 itemId（参考 table.StatsId）
+// 修改数据时不会更新统计信息，需要重新执行 ANALYZE TABLE
+//
+// +----------------+----------------+----------------+----------------+
+// |      Rows      |     Count      |    index1      |      ...       |
+// +----------------+----------------+----------------+----------------+
+// |     uint64     |     uint64     |                |                |
+// +----------------+----------------+----------------+----------------+
+//
+// 索引的统计信息：
+// +----------------+----------------+----------------+----------------+----------------+
+// |     TreeId     |      Keys      |    Distinct    |     Count      |    buckets     |
+// +----------------+----------------+----------------+----------------+----------------+
+// |     uint64     |     uint64     |     uint64     |     uint64     |                |
+// +----------------+----------------+----------------+----------------+----------------+
+//
+// 直方图的桶：
+// +----------------+----------------+----------------+----------------+
+// |      Min       |      Max       |     Count      |    Distinct    |
+// +----------------+----------------+----------------+----------------+
+// |     string     |     string     |     uint64     |     uint64     |
+// +----------------+----------------+----------------+----------------+
+//
+// 索引使用 TreeId 区分（删除索引之后重新创建同名的索引，统计信息不会被误用）
+// 直方图是等深直方图，每个桶中的索引项数量接近，相同的键只会出现在一个桶中
+
+// histogramSize 直方图中桶的最大数量
+const histogramSize = 32
+
+type stats struct {
+	Rows    uint64
+	Indexes map[uint64]*indexStats
+}
+
+type indexStats struct {
+	Keys     uint64 // 索引项的数量（不包含没有写入索引的 NULL）
+	Distinct uint64 // 不同的键的数量
+	Buckets  []*bucket
+}
+
+// bucket 直方图的桶，保存桶中最小和最大的键（截断到 index.KeyLen）
+type bucket struct {
+	Min      []byte
+	Max      []byte
+	Count    uint64
+	Distinct uint64
+}
+
+// collectStats 计算统计信息
+//
+// 先遍历表中的数据计算数据量，再按照键的顺序遍历每个索引构造直方图（不需要读取全部的数据到内存中）
+// 索引中保存全部的数据版本，因此需要判断每个索引项对应的数据是否对当前事务可见
+func (tbm *tableManage) collectStats(tid uint64, t *table) (*stats, error) {
+	s := &stats{
+		Indexes: make(map[uint64]*indexStats),
+	}
+	scan := &scanOp{rowReader: rowReader{tbm: tbm, tid: tid, t: t}}
+	defer scan.Close()
+	err := scan.Open()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		row, err := scan.Next()
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			break
+		}
+		s.Rows++
+	}
+
+	for _, f := range t.Fields {
+		if f.index == nil {
+			continue
+		}
+		is, err := tbm.collectIndexStats(tid, f, s.Rows)
+		if err != nil {
+			return nil, err
+		}
+		s.Indexes[f.TreeId] = is
+	}
+	return s, nil
+}
+
+// collectIndexStats 按照键的顺序遍历索引，构造索引的直方图（rows 为索引项数量的上限）
+func (tbm *tableManage) collectIndexStats(tid uint64, f *field, rows uint64) (*indexStats, error) {
+	it := f.index.Iterator(index.Bound{}, index.Bound{})
+	defer it.Close()
+
+	h := newHistogram(rows)
+	for it.Next() {
+		_, ok, err := tbm.verManage.Read(tid, it.ItemId())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			h.add(it.Key())
+		}
+	}
+	if it.Err() != nil {
+		return nil, it.Err()
+	}
+	return h.finish(), nil
+}
+
+// histogram 根据按照顺序加入的键构造直方图，只保存当前的桶和最后加入的键
+//
+// 相同的键放入同一个桶，加入之后超过桶的深度时放入下一个桶（出现次数多的键单独使用一个桶）
+// 桶的深度根据键的数量上限计算（NULL 较多时，桶的数量少于 histogramSize）
+type histogram struct {
+	is    *indexStats
+	depth uint64
+	b     *bucket
+
+	key []byte // 最后加入的键
+	n   uint64 // 最后加入的键的数量
+}
+
+func newHistogram(limit uint64) *histogram {
+	return &histogram{
+		is:    &indexStats{Buckets: make([]*bucket, 0)},
+		depth: max(1, (limit+histogramSize-1)/histogramSize),
+	}
+}
+
+// add 加入一个键（键需要按照从小到大的顺序加入）
+func (h *histogram) add(key []byte) {
+	if h.n != 0 && bytes.Equal(h.key, key) {
+		h.n++
+		return
+	}
+	h.flush()
+	h.key = truncKey(key)
+	h.n = 1
+}
+
+// flush 将最后加入的相同的键放入桶中
+func (h *histogram) flush() {
+	if h.n == 0 {
+		return
+	}
+	if h.b == nil || (h.b.Count != 0 && h.b.Count+h.n > h.depth) {
+		h.b = &bucket{Min: h.key}
+		h.is.Buckets = append(h.is.Buckets, h.b)
+	}
+	h.b.Max = h.key
+	h.b.Count += h.n
+	h.b.Distinct++
+	h.is.Keys += h.n
+	h.is.Distinct++
+	h.n = 0
+}
+
+func (h *histogram) finish() *indexStats {
+	h.flush()
+	return h.is
+}
+
+func truncKey(key []byte) []byte {
+	if len(key) > index.KeyLen {
+		key = key[:index.KeyLen]
+	}
+	return bytes.Clone(key)
+}
+
+// estimate 估算区间内的索引项数量
+//
+// 完全包含在区间内的桶计算全部的数量，部分包含的桶计算一半的数量
+// 单个键的区间（等值条件）使用桶中每个键的平均数量
+func (is *indexStats) estimate(rs []*Interval) float64 {
+	n := float64(0)
+	for _, r := range rs {
+		point := index.CompareKey(r.Min, r.Max) == 0
+		for _, b := range is.Buckets {
+			if index.CompareKey(r.Max, b.Min) < 0 || index.CompareKey(r.Min, b.Max) > 0 {
+				continue
+			}
+
+			avg := float64(b.Count) / float64(b.Distinct)
+			switch {
+			case point:
+				n += avg
+			case index.CompareKey(r.Min, b.Min) <= 0 && index.CompareKey(r.Max, b.Max) >= 0:
+				n += float64(b.Count)
+			default:
+				n += max(avg, float64(b.Count)/2)
+			}
+		}
+	}
+	return min(n, float64(is.Keys))
+}
+
+func (s *stats) encode() []byte {
+	data := encodeUint64(s.Rows)
+	data = append(data, encodeUint64(uint64(len(s.Indexes)))...)
+
+	// 按照 TreeId 排序，保证编码结果相同
+	ids := make([]uint64, 0, len(s.Indexes))
+	for id := range s.Indexes {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		is := s.Indexes[id]
+		data = append(data, encodeUint64(id)...)
+		data = append(data, encodeUint64(is.Keys)...)
+		data = append(data, encodeUint64(is.Distinct)...)
+		data = append(data, encodeUint64(uint64(len(is.Buckets)))...)
+		for _, b := range is.Buckets {
+			data = append(data, encodeString(string(b.Min))...)
+			data = append(data, encodeString(string(b.Max))...)
+			data = append(data, encodeUint64(b.Count)...)
+			data = append(data, encodeUint64(b.Distinct)...)
+		}
+	}
+	return data
+}
+
+func decodeStats(data []byte) *stats {
+	var (
+		pos   int
+		shift int
+		num   uint64
+	)
+	s := &stats{
+		Indexes: make(map[uint64]*indexStats),
+	}
+	s.Rows, shift = decodeUint64(data[pos:])
+	pos += shift
+	num, shift = decodeUint64(data[pos:])
+	pos += shift
+	for i := uint64(0); i < num; i++ {
+		var (
+			id    uint64
+			count uint64
+		)
+		is := new(indexStats)
+		id, shift = decodeUint64(data[pos:])
+		pos += shift
+		is.Keys, shift = decodeUint64(data[pos:])
+		pos += shift
+		is.Distinct, shift = decodeUint64(data[pos:])
+		pos += shift
+		count, shift = decodeUint64(data[pos:])
+		pos += shift
+
+		is.Buckets = make([]*bucket, 0, count)
+		for j := uint64(0); j < count; j++ {
+			var key string
+			b := new(bucket)
+			key, shift = decodeString(data[pos:])
+			pos += shift
+			b.Min = []byte(key)
+			key, shift = decodeString(data[pos:])
+			pos += shift
+			b.Max = []byte(key)
+			b.Count, shift = decodeUint64(data[pos:])
+			pos += shift
+			b.Distinct, shift = decodeUint64(data[pos:])
+			pos += shift
+			is.Buckets = append(is.Buckets, b)
+		}
+		s.Indexes[id] = is
+	}
+	return s
+}
+
+// Analyze 收集表的统计信息
+//
+// 统计信息和表信息一起写入（与修改表结构相同，事务提交之后生效）
+func (tbm *tableManage) Analyze(tid uint64, stmt *sql.AnalyzeStmt) (err error) {
+	tbm.Lock()
+	defer tbm.Unlock()
+
+	t, err := tbm.lookup(tid, stmt.Table)
+	if err != nil {
+		return err
+	}
+	err = tbm.beginDDL(tid)
+	if err != nil {
+		return err
+	}
+
+	// 根据当前事务可见的数据计算统计信息
+	nt := t.clone()
+	nt.stats, err = tbm.collectStats(tid, t)
+	if err != nil {
+		return err
+	}
+
+	// 保存统计信息
+	nt.StatsId, err = tbm.verManage.Write(tid, nt.stats.encode())
+	if err != nil {
+		return err
+	}
+	if t.StatsId != 0 {
+		tbm.ddl.records = append(tbm.ddl.records, t.StatsId)
+	}
+	tbm.ddl.written = append(tbm.ddl.written, nt.StatsId)
+
+	// 更新表信息
+	tbm.tables.Set(nt.Name, nt)
+	return tbm.saveCatalog(tid)
+}
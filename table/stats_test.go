@@ -0,0 +1,170 @@
+package table
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ggymm/db"
+	"github.com/ggymm/db/boot"
+	"github.com/ggymm/db/data"
+	"github.com/ggymm/db/index"
+	"github.com/ggymm/db/pkg/sql"
+	"github.com/ggymm/db/tx"
+	"github.com/ggymm/db/ver"
+)
+
+func TestIndexStats(t *testing.T) {
+	// 0 ~ 99 每个值 3 个，100 有 300 个
+	h := newHistogram(600)
+	for i := uint64(0); i <= 100; i++ {
+		n := 3
+		if i == 100 {
+			n = 300
+		}
+		for j := 0; j < n; j++ {
+			h.add(key(i))
+		}
+	}
+	is := h.finish()
+	if is.Keys != 600 || is.Distinct != 101 || len(is.Buckets) > histogramSize {
+		t.Fatalf("stats %d %d %d", is.Keys, is.Distinct, len(is.Buckets))
+	}
+
+	for _, c := range []struct {
+		rs       []*Interval
+		min, max float64
+	}{
+		{[]*Interval{{Min: key(5), Max: key(5)}}, 1, 6},
+		{[]*Interval{{Min: key(100), Max: key(100)}}, 300, 300},
+		{[]*Interval{{Min: key(200), Max: key(300)}}, 0, 0},
+		{[]*Interval{{Min: key(10), Max: key(59)}}, 120, 180},
+		{[]*Interval{{Min: index.MinKey(), Max: index.MaxKey()}}, 600, 600},
+	} {
+		n := is.estimate(c.rs)
+		if n < c.min || n > c.max {
+			t.Fatalf("estimate %v got %f", c.rs, n)
+		}
+	}
+
+	// 编码之后解码
+	s := &stats{Rows: 600, Indexes: map[uint64]*indexStats{1: is}}
+	if !reflect.DeepEqual(decodeStats(s.encode()), s) {
+		t.Fatalf("decode stats not equal")
+	}
+}
+
+func TestTableManage_Plan(t *testing.T) {
+	opt := db.NewOption(db.RunPath(), "temp/table_plan")
+	opt.Memory = (1 << 20) * 64
+	err := os.RemoveAll(opt.Path)
+	if err != nil {
+		t.Fatalf("remove err %v", err)
+	}
+	tm := tx.NewManager(opt)
+	dm := data.NewManage(tm, opt)
+	defer tm.Close()
+	defer dm.Close()
+	tbm := NewManage(boot.New(opt), ver.NewManage(tm, dm), dm).(*tableManage)
+
+	parse := func(in string) sql.Statement {
+		stmt, err := sql.ParseSQL(in)
+		if err != nil {
+			t.Fatalf("parse %s err %v", in, err)
+		}
+		return stmt
+	}
+
+	tid := tbm.Begin(1)
+	err = tbm.Create(tid, parse("CREATE TABLE user (id INT64, city VARCHAR, status INT32, age INT32, PRIMARY KEY (id), INDEX city_idx (city), INDEX status_idx (status), INDEX age_idx (age));").(*sql.CreateStmt))
+	if err != nil {
+		t.Fatalf("create err %v", err)
+	}
+	for i := 0; i < 200; i++ {
+		in := fmt.Sprintf("INSERT INTO user (id, city, status, age) VALUE (%d, 'c%d', %d, %d);", i, i%4, i%2, i%100)
+		_, err = tbm.Insert(tid, parse(in).(*sql.InsertStmt))
+		if err != nil {
+			t.Fatalf("insert err %v", err)
+		}
+	}
+	_ = tbm.Commit(tid)
+
+	// 回滚之后统计信息不变
+	tid = tbm.Begin(1)
+	err = tbm.Analyze(tid, &sql.AnalyzeStmt{Table: "user"})
+	if err != nil {
+		t.Fatalf("analyze err %v", err)
+	}
+	tbm.Rollback(tid)
+	if tb, _ := tbm.tables.Get("user"); tb.stats != nil || tb.StatsId != 0 {
+		t.Fatalf("stats after rollback")
+	}
+
+	tid = tbm.Begin(1)
+	err = tbm.Analyze(tid, &sql.AnalyzeStmt{Table: "user"})
+	if err != nil {
+		t.Fatalf("analyze err %v", err)
+	}
+	_ = tbm.Commit(tid)
+
+	tb, _ := tbm.tables.Get("user")
+	if tb.stats == nil || tb.stats.Rows != 200 {
+		t.Fatalf("stats %+v", tb.stats)
+	}
+	if nt := readTable(tbm, tb.itemId); !reflect.DeepEqual(nt.stats, tb.stats) {
+		t.Fatalf("stats not saved")
+	}
+	if is := tb.stats.Indexes[tb.field("age").TreeId]; is.Keys != 200 || is.Distinct != 100 {
+		t.Fatalf("age stats %d %d", is.Keys, is.Distinct)
+	}
+
+	// 索引中删除的数据版本不计入统计信息
+	tid = tbm.Begin(1)
+	_, err = tbm.Delete(tid, parse("DELETE FROM user WHERE age < 10;").(*sql.DeleteStmt))
+	if err != nil {
+		t.Fatalf("delete err %v", err)
+	}
+	err = tbm.Analyze(tid, &sql.AnalyzeStmt{Table: "user"})
+	if err != nil {
+		t.Fatalf("analyze err %v", err)
+	}
+	nt, _ := tbm.getTable(tid, "user")
+	if is := nt.stats.Indexes[nt.field("age").TreeId]; nt.stats.Rows != 180 || is.Keys != 180 || is.Distinct != 90 {
+		t.Fatalf("stats after delete %d %d %d", nt.stats.Rows, is.Keys, is.Distinct)
+	}
+	tbm.Rollback(tid)
+
+	tid = tbm.Begin(1)
+	defer tbm.Rollback(tid)
+	for where, want := range map[string]struct {
+		scans []string
+		rows  int
+	}{
+		// 选择率最低的索引（与字段的声明顺序无关）
+		"age = 5 AND city = 'c1' AND status = 1": {[]string{"age"}, 2},
+		// 选择率高的条件使用全表扫描
+		"status >= 0": {nil, 200},
+		"age != 5":    {nil, 198},
+		// 两个条件都不够精确时使用索引合并
+		"age >= 10 AND age < 20 AND city = 'c1'": {[]string{"age", "city"}, 4},
+	} {
+		stmt := parse("SELECT * FROM user WHERE " + where + ";").(*sql.SelectStmt)
+		p, err := tb.plan(stmt.Where)
+		if err != nil {
+			t.Fatalf("%s: plan err %v", where, err)
+		}
+		scans := make([]string, 0)
+		for _, s := range p.scans {
+			scans = append(scans, s.f.Name)
+		}
+		if fmt.Sprint(scans) != fmt.Sprint(want.scans) {
+			t.Fatalf("%s: scans %v, want %v", where, scans, want.scans)
+		}
+
+		rows, err := tbm.Select(tid, stmt)
+		if err != nil || len(rows) != want.rows {
+			t.Fatalf("%s: got %d rows, err %v", where, len(rows), err)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package table
 
 import (
-	"errors"
+	"fmt"
 	"math"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/ggymm/db"
 	"github.com/ggymm/db/index"
@@ -19,23 +21,42 @@ const (
 
 type Entry map[string]any
 
+// Column 查询结果的字段信息
+type Column struct {
+	Name string
+	Type string
+}
+
 // table 结构
 //
-// +----------------+----------------+----------------+
-// |     Name       |      Next      |     Fields     |
-// +----------------+----------------+----------------+
-// |    string      |     uint64     |    uint64[]    |
-// +----------------+----------------+----------------+
+// +----------------+----------------+----------------+----------------+----------------+----------------+
+// |     Name       |      Next      |     Fields     |       0        |     HeapId     |    StatsId     |
+// +----------------+----------------+----------------+----------------+----------------+----------------+
+// |    string      |     uint64     |    uint64[]    |     uint64     |     uint64     |     uint64     |
+// +----------------+----------------+----------------+----------------+----------------+----------------+
 // Name: 表名
 // Next: 下一张表的 itemId
-// Fields: 表字段 itemId 列表
+// Fields: 表字段 itemId 列表（包含已经删除的字段）
+// HeapId: 保存数据的堆（字段列表之后使用 0 分隔，旧版本的表信息没有堆，数据保存在普通页面中）
+// StatsId: 统计信息的 itemId（参考 stats.go），没有统计信息时省略
+//
+// 有堆的表，通过遍历堆的页面获取全部数据（不需要主键）
+//
+// 表结构使用版本号区分，每次新增或者删除字段时版本号加一
+// 字段中保存新增和删除时的版本号（field.AddVer、field.DropVer）
+// 因此可以得到任意版本的表结构，用于解析修改表结构之前写入的数据
 type table struct {
-	tbm    Manage
-	itemId uint64
-
-	Name   string
-	Next   uint64
-	Fields []*field
+	tbm     Manage
+	itemId  uint64
+	all     []*field // 全部字段（包含已经删除的字段）
+	version uint32   // 当前的表结构版本
+	stats   *stats   // 统计信息（没有执行 ANALYZE TABLE 时为 nil）
+
+	Name    string
+	Next    uint64
+	Fields  []*field // 当前的字段
+	HeapId  uint64
+	StatsId uint64
 }
 
 func readTable(tbm Manage, itemId uint64) *table {
@@ -61,20 +82,209 @@ func readTable(tbm Manage, itemId uint64) *table {
 	t.Next, shift = decodeUint64(data[pos:])
 
 	pos += shift
-	t.Fields = make([]*field, 0)
+	t.all = make([]*field, 0)
 
 	// 读取 fields
 	id := uint64(0)
 	for pos < len(data) {
 		// 读取 field
 		id, shift = decodeUint64(data[pos:])
-		t.Fields = append(t.Fields, readField(tbm, id))
-
 		pos += shift
+
+		// 读取 heapId 和 statsId
+		if id == 0 {
+			t.HeapId, shift = decodeUint64(data[pos:])
+			pos += shift
+			if pos < len(data) {
+				t.StatsId, _ = decodeUint64(data[pos:])
+			}
+			break
+		}
+		t.all = append(t.all, readField(tbm, id))
+	}
+	t.init()
+
+	// 读取统计信息
+	if t.StatsId != 0 {
+		raw, exist, err := tbm.VerManage().Read(tx.Super, t.StatsId)
+		if err != nil || !exist {
+			panic(err)
+		}
+		t.stats = decodeStats(raw)
 	}
 	return t
 }
 
+// init 根据全部字段计算当前的字段和表结构版本
+func (t *table) init() {
+	t.version = 0
+	t.Fields = make([]*field, 0, len(t.all))
+	for _, f := range t.all {
+		t.version = max(t.version, f.AddVer, f.DropVer)
+		if f.DropVer == 0 {
+			t.Fields = append(t.Fields, f)
+		}
+	}
+}
+
+// clone 复制表信息（修改表结构时使用，字段对象是共享的）
+func (t *table) clone() *table {
+	nt := *t
+	nt.all = slices.Clone(t.all)
+	nt.init()
+	return &nt
+}
+
+// field 根据名称获取当前的字段
+func (t *table) field(name string) *field {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// index 根据名称获取当前字段的索引
+func (t *table) index(name string) *field {
+	for _, f := range t.Fields {
+		if f.TreeId != 0 && f.IndexName == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// indexFields 获取字段的索引包含的全部字段
+func (t *table) indexFields(f *field) []*field {
+	if len(f.IndexCols) == 0 {
+		return []*field{f}
+	}
+	fs := make([]*field, 0, len(f.IndexCols))
+	for _, col := range f.IndexCols {
+		fs = append(fs, t.field(col))
+	}
+	return fs
+}
+
+// indexKey 计算数据在字段的索引中的键
+//
+// 第一个字段为 NULL 时返回 nil，不写入索引
+// 多列索引中其他字段为 NULL 时仍然写入索引（前缀查询需要包含这些数据）
+func (t *table) indexKey(f *field, row Entry) []byte {
+	if row[f.Name] == nil {
+		return nil
+	}
+	if len(f.IndexCols) == 0 {
+		return f.wrapKey(row[f.Name])
+	}
+
+	parts := make([][]byte, 0, len(f.IndexCols))
+	for _, c := range t.indexFields(f) {
+		if row[c.Name] == nil {
+			parts = append(parts, index.EncodeNullPart())
+		} else {
+			parts = append(parts, c.wrapPart(row[c.Name]))
+		}
+	}
+	return index.EncodeComposite(parts...)
+}
+
+// uniqueKey 计算唯一索引中需要检查重复的键（任意字段为 NULL 时返回 nil，NULL 不会重复）
+func (t *table) uniqueKey(f *field, row Entry) []byte {
+	for _, c := range t.indexFields(f) {
+		if row[c.Name] == nil {
+			return nil
+		}
+	}
+	return t.indexKey(f, row)
+}
+
+// indexValue 数据在字段的索引中的值（多列索引格式化为 (v1, v2)）
+func (t *table) indexValue(f *field, row Entry) any {
+	if len(f.IndexCols) == 0 {
+		return row[f.Name]
+	}
+
+	vs := make([]string, 0, len(f.IndexCols))
+	for _, col := range f.IndexCols {
+		vs = append(vs, fmt.Sprint(row[col]))
+	}
+	return "(" + strings.Join(vs, ", ") + ")"
+}
+
+// replace 替换字段（修改字段信息后使用）
+func (t *table) replace(old, f *field) {
+	i := slices.Index(t.all, old)
+	if i != -1 {
+		t.all[i] = f
+	}
+	t.init()
+}
+
+// drop 释放表的全部数据、索引、自增序列、字段信息和统计信息
+//
+// 有堆的表直接删除堆，否则数据通过索引获取（每条数据都在主键索引中，包括旧版本和回滚的数据）
+func (t *table) drop() error {
+	dm := t.tbm.DataManage()
+	if t.HeapId != 0 {
+		err := dm.DropHeap(t.HeapId)
+		if err != nil {
+			return err
+		}
+	} else {
+		rids := make([]uint64, 0)
+		for _, f := range t.all {
+			if f.index == nil {
+				continue
+			}
+			ids, err := f.index.SearchRange(index.MinKey(), index.MaxKey())
+			if err != nil {
+				return err
+			}
+			rids = append(rids, ids...)
+		}
+		slices.Sort(rids)
+		for _, rid := range slices.Compact(rids) {
+			err := dm.Free(rid)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, f := range t.all {
+		if f.index != nil {
+			err := f.index.Drop()
+			if err != nil {
+				return err
+			}
+		}
+		if f.seq != nil {
+			err := f.seq.drop(dm)
+			if err != nil {
+				return err
+			}
+		}
+		err := dm.Free(f.itemId)
+		if err != nil {
+			return err
+		}
+	}
+	if t.StatsId != 0 {
+		return dm.Free(t.StatsId)
+	}
+	return nil
+}
+
+// write 写入数据（有堆的表写入到堆中）
+func (t *table) write(tid uint64, raw []byte) (uint64, error) {
+	if t.HeapId != 0 {
+		return t.tbm.VerManage().WriteHeap(tid, t.HeapId, raw)
+	}
+	return t.tbm.VerManage().Write(tid, raw)
+}
+
 func (t *table) save(txId uint64) (err error) {
 	// name
 	data := encodeString(t.Name)
@@ -84,29 +294,85 @@ func (t *table) save(txId uint64) (err error) {
 	data = append(data, raw...)
 
 	// fields
-	for _, f := range t.Fields {
+	for _, f := range t.all {
 		raw = encodeUint64(f.itemId)
 		data = append(data, raw...)
 	}
 
+	// heapId 和 statsId
+	if t.HeapId != 0 || t.StatsId != 0 {
+		data = append(data, encodeUint64(0)...)
+		data = append(data, encodeUint64(t.HeapId)...)
+	}
+	if t.StatsId != 0 {
+		data = append(data, encodeUint64(t.StatsId)...)
+	}
+
 	// 持久化
 	t.itemId, err = t.tbm.VerManage().Write(txId, data)
 	return
 }
 
+// fillRow 补全插入的数据
+//
+// 没有指定的字段使用默认值，显式指定的 NULL 不使用默认值
+// 自增字段没有指定或者为 NULL 时，使用序列分配的值
+func (t *table) fillRow(row Entry) error {
+	for _, f := range t.Fields {
+		v, exist := row[f.Name]
+		if !exist {
+			v = f.defaultVal()
+		}
+		if f.seq != nil {
+			if v == nil {
+				n := f.seq.next()
+				if f.typ.Type == sql.Int32 && n > math.MaxInt32 {
+					return NewError(ErrSequenceExhausted, f.Name)
+				}
+				v = n
+				if f.typ.Type == sql.Int32 {
+					v = int32(n)
+				}
+			} else {
+				f.seq.update(intVal(v))
+			}
+		}
+		row[f.Name] = v
+	}
+	return nil
+}
+
+// 数据的格式
+//
+// 表结构版本为 0 时：
+// +----------------+----------------+----------------+
+// |     field1     |     field2     |      ...       |
+// +----------------+----------------+----------------+
+//
+// 表结构版本大于 0 时：
+// +----------------+----------------+----------------+----------------+
+// |      tag       |    version     |     field1     |      ...       |
+// +----------------+----------------+----------------+----------------+
+// |     1 byte     |     4 bytes    |                |                |
+// +----------------+----------------+----------------+----------------+
+//
+// tag: 固定为 0xFF（字段值的第一个字节为 Null 或者 NotNull，不会与 tag 冲突）
+// version: 写入数据时的表结构版本，数据中只包含该版本中存在的字段
+//
+// 解析数据时，之后新增的字段使用默认值（常量默认值或者 NULL），已经删除的字段被忽略
+const rowTag byte = 0xff
+
 func (t *table) wrapRaw(row Entry) ([]byte, error) {
 	raw := make([]byte, 0)
+	if t.version > 0 {
+		raw = append(raw, rowTag)
+		raw = append(raw, bin.Uint32Raw(t.version)...)
+	}
 	for _, f := range t.Fields {
 		// 获取字段值
-		v, exist := row[f.Name]
-		if !exist || v == "" {
-			if len(f.Default) != 0 {
-				v = f.Default
-				continue
-			}
-			if !f.Nullable {
-				return nil, NewError(ErrNotAllowNull, f.Name)
-			}
+		v := row[f.Name]
+		if v == nil && !f.Nullable {
+			return nil, NewError(ErrNotAllowNull, f.Name)
 		}
 
 		// 获取字段二进制值
@@ -117,11 +383,27 @@ func (t *table) wrapRaw(row Entry) ([]byte, error) {
 
 func (t *table) wrapEntry(raw []byte, where []sql.SelectWhere) Entry {
 	pos := 0
+	ver := uint32(0)
+	if raw[0] == rowTag {
+		ver = bin.Uint32(raw[1:])
+		pos = 5
+	}
+
 	row := make(Entry)
-	for _, f := range t.Fields {
+	for _, f := range t.all {
+		if !f.existIn(ver) {
+			// 写入数据之后新增的字段
+			if f.DropVer == 0 {
+				row[f.Name] = f.fillVal()
+			}
+			continue
+		}
+
 		val, shift := f.parseRaw(raw[pos:])
-		row[f.Name] = val
 		pos += shift
+		if f.DropVer == 0 {
+			row[f.Name] = val
+		}
 	}
 
 	if where == nil || len(where) == 0 {
@@ -143,95 +425,73 @@ func (t *table) wrapEntry(raw []byte, where []sql.SelectWhere) Entry {
 	return row
 }
 
-func (t *table) parseWhere(where []sql.SelectWhere) ([]uint64, error) {
-	var err error
-	pk := func() ([]uint64, error) {
-		f := &field{}
-		for _, f = range t.Fields {
-			if f.PrimaryKey {
-				break
-			}
-		}
-		if f == nil {
-			return nil, ErrNoPrimaryKey
-		}
+// 主键索引的名称
+const primaryIndex = "PRIMARY"
 
-		// 查询索引
-		return f.index.SearchRange(0, math.MaxUint64)
-	}
-
-	// 查询条件
-	if len(where) == 0 {
-		return pk()
-	} else {
-		var (
-			f  = &field{}
-			rs = make([]*Interval, 0)
-		)
-
-		// 条件解析
-		for _, f = range t.Fields {
-			if f.TreeId == 0 {
-				continue
-			}
-			rs, err = newExplain().execute(f, where)
-			if err != nil {
-				if errors.Is(err, ErrNotIndex) {
-					continue
-				}
-				return nil, err
-			}
-			if len(rs) != 0 {
-				break
-			}
-		}
-
-		// 查询索引
-		if len(rs) == 0 {
-			return pk()
-		} else {
-			var (
-				tmp  = make([]uint64, 0)
-				rids = make([]uint64, 0)
-			)
-
-			for _, r := range rs {
-				tmp, err = f.index.SearchRange(r.Min, r.Max)
-				if err != nil {
-					return nil, err
-				}
-				rids = append(rids, tmp...)
-			}
-			return rids, err
-		}
-	}
-}
+// 默认值的类型
+const (
+	defaultNone byte = iota
+	defaultValue
+	defaultCurrentTimestamp
+)
 
 // field 字段信息
 //
 // +----------------+----------------+----------------+----------------+----------------+----------------+
-// |	  name      |	  type       |	   default    |	   allowNull   |	primaryKey  |	  index      |
+// |	  name      |	  type       |	   treeId     |	   default     |	  nullable  |	 primaryKey  |
 // +----------------+----------------+----------------+----------------+----------------+----------------+
-// |	 string     |	 string      |	   string     |	     bool      |	  bool      |	  uint64     |
+// |	 string     |	 string      |	   uint64     |	   string      |	    bool    |	    bool     |
 // +----------------+----------------+----------------+----------------+----------------+----------------+
+// +----------------+----------------+----------------+----------------+----------------+
+// |	defaultKind |	defaultRaw   |	   seqId      |	    addVer     |	  dropVer   |
+// +----------------+----------------+----------------+----------------+----------------+
+// |	   byte     |	   bytes     |	   uint64     |	    uint32     |	  uint32    |
+// +----------------+----------------+----------------+----------------+----------------+
+// +----------------+----------------+----------------+
+// |	 indexName  |	   unique    |	  indexCols   |
+// +----------------+----------------+----------------+
+// |	  string    |	    bool     |	 uint32 + []  |
+// +----------------+----------------+----------------+
 //
 // Name: 名称
-// Type: 类型
+// Type: 类型（sql.ColumnType 的文本格式）
 // TreeId: 索引根节点 itemId
-// Default: 默认值
+// Default: 默认值（文本格式，只用于展示）
 // Nullable: 是否允许为空
 // PrimaryKey: 是否是主键
+// defaultKind: 默认值的类型（无、常量、CURRENT_TIMESTAMP）
+// defaultRaw: 默认值（与数据中的字段值格式相同），只有常量默认值才保存
+// SeqId: 自增序列的 itemId（0 表示不是自增字段）
+// AddVer: 新增字段时的表结构版本（创建表时的字段为 0）
+// DropVer: 删除字段时的表结构版本（0 表示没有被删除）
+// IndexName: 索引名称（主键索引为 PRIMARY）
+// Unique: 是否是唯一索引
+// IndexCols: 多列索引的全部字段名称（按照索引中的顺序，第一个是当前字段），单列索引时为空
+//
+// 多列索引保存在第一个字段中，每个字段只能是一个索引的第一个字段
+// 旧版本的字段信息没有 defaultKind 之后的部分，此时使用 Default 解析默认值
 type field struct {
 	tbm    Manage
+	typ    sql.ColumnType
+	seq    *sequence
 	index  index.Index
 	itemId uint64
 
+	defKind byte
+	defVal  any
+
 	Name       string
 	Type       string
 	TreeId     uint64
 	Default    string
 	Nullable   bool
 	PrimaryKey bool
+	SeqId      uint64
+	AddVer     uint32
+	DropVer    uint32
+	IndexName  string
+	Unique     bool
+	IndexCols  []string
 }
 
 func readField(tbm Manage, itemId uint64) *field {
@@ -255,6 +515,10 @@ func readField(tbm Manage, itemId uint64) *field {
 	// type
 	pos += shift
 	f.Type, shift = decodeString(data[pos:])
+	f.typ, err = sql.ParseColumnType(f.Type)
+	if err != nil {
+		panic(err)
+	}
 
 	// treeId
 	pos += shift
@@ -272,6 +536,64 @@ func readField(tbm Manage, itemId uint64) *field {
 	pos++
 	f.PrimaryKey = data[pos] == 1
 
+	pos++
+	if pos < len(data) {
+		// defaultKind
+		f.defKind = data[pos]
+		pos++
+
+		// defaultRaw
+		if f.defKind == defaultValue {
+			f.defVal, shift = f.parseRaw(data[pos:])
+			pos += shift
+		}
+
+		// seqId
+		f.SeqId, shift = decodeUint64(data[pos:])
+		pos += shift
+
+		// addVer、dropVer
+		if pos < len(data) {
+			f.AddVer = bin.Uint32(data[pos:])
+			f.DropVer = bin.Uint32(data[pos+4:])
+			pos += 8
+		}
+
+		// indexName、unique
+		if pos < len(data) {
+			f.IndexName, shift = decodeString(data[pos:])
+			pos += shift
+			f.Unique = data[pos] == 1
+			pos++
+		}
+
+		// indexCols
+		if pos < len(data) {
+			n := int(bin.Uint32(data[pos:]))
+			pos += 4
+			for i := 0; i < n; i++ {
+				var col string
+				col, shift = decodeString(data[pos:])
+				pos += shift
+				f.IndexCols = append(f.IndexCols, col)
+			}
+		}
+	} else if f.Default != "" {
+		// 旧版本的字段信息
+		f.defVal, err = sql.FormatVal(f.typ, f.Default)
+		if err == nil {
+			f.defKind = defaultValue
+		}
+	}
+
+	// 读取自增序列
+	if f.SeqId != 0 {
+		f.seq, err = openSequence(tbm.DataManage(), f.SeqId)
+		if err != nil {
+			panic(err)
+		}
+	}
+
 	// 读取索引
 	if f.TreeId != 0 {
 		f.index, err = index.NewIndex(tbm.DataManage(), &db.Option{
@@ -312,23 +634,79 @@ func (f *field) save(txId uint64) (err error) {
 		data = append(data, 0)
 	}
 
+	// defaultKind
+	data = append(data, f.defKind)
+
+	// defaultRaw
+	if f.defKind == defaultValue {
+		data = append(data, f.wrapRaw(f.defVal)...)
+	}
+
+	// seqId
+	data = append(data, encodeUint64(f.SeqId)...)
+
+	// addVer、dropVer
+	data = append(data, bin.Uint32Raw(f.AddVer)...)
+	data = append(data, bin.Uint32Raw(f.DropVer)...)
+
+	// indexName
+	data = append(data, encodeString(f.IndexName)...)
+
+	// unique
+	if f.Unique {
+		data = append(data, 1)
+	} else {
+		data = append(data, 0)
+	}
+
+	// indexCols
+	data = append(data, bin.Uint32Raw(uint32(len(f.IndexCols)))...)
+	for _, col := range f.IndexCols {
+		data = append(data, encodeString(col)...)
+	}
+
 	// 保存到磁盘
 	f.itemId, err = f.tbm.VerManage().Write(txId, data)
 	return
 }
 
+// 字段值的二进制格式（小端序）
+//
+// INT32：4 bytes
+// INT64：8 bytes
+// BOOL：1 byte
+// DOUBLE：8 bytes（IEEE 754）
+// DECIMAL：8 bytes（Dec.Value）
+// TIMESTAMP：8 bytes（Unix 时间戳，单位微秒）
+// DATE：4 bytes（距离 1970-01-01 的天数）
+// VARCHAR：4 bytes 的长度 + 原始字节
+const secondsPerDay = 24 * 60 * 60
+
 func (f *field) wrapRaw(v any) []byte {
 	if v == nil {
 		return []byte{Null}
 	}
 
 	var raw []byte
-	switch f.Type {
-	case "INT32":
-		raw = bin.Uint32Raw(v.(uint32))
-	case "INT64":
-		raw = bin.Uint64Raw(v.(uint64))
-	case "VARCHAR":
+	switch f.typ.Type {
+	case sql.Int32:
+		raw = bin.Uint32Raw(uint32(v.(int32)))
+	case sql.Int64:
+		raw = bin.Uint64Raw(uint64(v.(int64)))
+	case sql.Bool:
+		raw = []byte{0}
+		if v.(bool) {
+			raw[0] = 1
+		}
+	case sql.Double:
+		raw = bin.Uint64Raw(math.Float64bits(v.(float64)))
+	case sql.Decimal:
+		raw = bin.Uint64Raw(uint64(v.(sql.Dec).Value))
+	case sql.Timestamp:
+		raw = bin.Uint64Raw(uint64(v.(time.Time).UnixMicro()))
+	case sql.Date:
+		raw = bin.Uint32Raw(uint32(int32(v.(time.Time).Unix() / secondsPerDay)))
+	case sql.Varchar:
 		l := len(v.(string))
 		raw = make([]byte, 4+l)
 
@@ -344,6 +722,124 @@ func (f *field) wrapRaw(v any) []byte {
 	return slices.Insert(raw, 0, NotNull)
 }
 
+// parseDefault 解析字段的默认值和自增属性（创建表时调用）
+func (f *field) parseDefault(tf *sql.CreateField) error {
+	if tf.AutoIncrement {
+		if tf.Default != nil || (f.typ.Type != sql.Int32 && f.typ.Type != sql.Int64) {
+			return NewError(ErrInvalidAutoInc, f.Name)
+		}
+	}
+
+	d := tf.Default
+	switch {
+	case d == nil:
+		f.defKind = defaultNone
+	case d.Expr == sql.CurrentTimestamp:
+		if f.typ.Type != sql.Timestamp && f.typ.Type != sql.Date {
+			return NewError(ErrInvalidDefault, f.Name)
+		}
+		f.defKind = defaultCurrentTimestamp
+		f.Default = sql.CurrentTimestamp
+	case d.Value.Null:
+		if !f.Nullable {
+			return NewError(ErrInvalidDefault, f.Name)
+		}
+		f.defKind = defaultNone
+	default:
+		v, err := sql.FormatVal(f.typ, d.Value.Str)
+		if err != nil {
+			return fmt.Errorf("%w: %w", NewError(ErrInvalidDefault, f.Name), err)
+		}
+		f.defKind = defaultValue
+		f.defVal = v
+		f.Default = sql.FormatText(f.typ, v)
+	}
+	return nil
+}
+
+// defaultVal 字段的默认值，没有默认值时为 nil
+func (f *field) defaultVal() any {
+	switch f.defKind {
+	case defaultValue:
+		return f.defVal
+	case defaultCurrentTimestamp:
+		now := time.Now().UTC()
+		if f.typ.Type == sql.Date {
+			return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		return now.Truncate(time.Microsecond)
+	}
+	return nil
+}
+
+// existIn 字段是否存在于指定版本的表结构中
+func (f *field) existIn(ver uint32) bool {
+	return f.AddVer <= ver && (f.DropVer == 0 || f.DropVer > ver)
+}
+
+// fillVal 新增字段之前写入的数据中，该字段的值
+func (f *field) fillVal() any {
+	if f.defKind == defaultValue {
+		return f.defVal
+	}
+	return nil
+}
+
+// intVal 整数字段值转换为 int64
+func intVal(v any) int64 {
+	switch val := v.(type) {
+	case int32:
+		return int64(val)
+	case int64:
+		return val
+	}
+	return 0
+}
+
+// formatVal 将语句中的值转换为字段值
+func (f *field) formatVal(v *sql.Value) (any, error) {
+	if v.Null {
+		return nil, nil
+	}
+	return sql.FormatVal(f.typ, v.Str)
+}
+
+// wrapKey 将字段值编码为索引键
+func (f *field) wrapKey(v any) []byte {
+	switch f.typ.Type {
+	case sql.Int32:
+		return index.EncodeInt32(v.(int32))
+	case sql.Int64:
+		return index.EncodeInt64(v.(int64))
+	case sql.Bool:
+		return index.EncodeBool(v.(bool))
+	case sql.Double:
+		return index.EncodeFloat64(v.(float64))
+	case sql.Decimal:
+		return index.EncodeInt64(v.(sql.Dec).Value)
+	case sql.Timestamp:
+		return index.EncodeInt64(v.(time.Time).UnixMicro())
+	case sql.Date:
+		return index.EncodeInt32(int32(v.(time.Time).Unix() / secondsPerDay))
+	case sql.Varchar:
+		return index.EncodeString(v.(string))
+	}
+	return nil
+}
+
+// wrapPart 将字段值编码为多列索引键的一部分（字符串是变长的，需要转义）
+func (f *field) wrapPart(v any) []byte {
+	return f.keyPart(f.wrapKey(v))
+}
+
+// keyPart 将单列索引键转换为多列索引键的一部分
+func (f *field) keyPart(key []byte) []byte {
+	if f.typ.Type == sql.Varchar {
+		key = index.EncodeVarPart(key)
+	}
+	return index.EncodePart(key)
+}
+
 func (f *field) parseRaw(raw []byte) (any, int) {
 	if raw[0] == Null {
 		return nil, 1
@@ -351,14 +847,29 @@ func (f *field) parseRaw(raw []byte) (any, int) {
 	var v any
 	var shift int
 	raw = raw[1:]
-	switch f.Type {
-	case "INT32":
-		v = bin.Uint32(raw)
+	switch f.typ.Type {
+	case sql.Int32:
+		v = int32(bin.Uint32(raw))
 		shift = 4
-	case "INT64":
-		v = bin.Uint64(raw)
+	case sql.Int64:
+		v = int64(bin.Uint64(raw))
+		shift = 8
+	case sql.Bool:
+		v = raw[0] == 1
+		shift = 1
+	case sql.Double:
+		v = math.Float64frombits(bin.Uint64(raw))
 		shift = 8
-	case "VARCHAR":
+	case sql.Decimal:
+		v = sql.Dec{Value: int64(bin.Uint64(raw)), Scale: f.typ.Scale}
+		shift = 8
+	case sql.Timestamp:
+		v = time.UnixMicro(int64(bin.Uint64(raw))).UTC()
+		shift = 8
+	case sql.Date:
+		v = time.Unix(int64(int32(bin.Uint32(raw)))*secondsPerDay, 0).UTC()
+		shift = 4
+	case sql.Varchar:
 		l := int(raw[0]) |
 			int(raw[1])<<8 |
 			int(raw[2])<<16 |
@@ -26,7 +26,10 @@ type Manage interface {
 	Rollback(tid uint64)
 
 	Read(tid uint64, key uint64) ([]byte, bool, error)
+	ReadLatest(tid uint64, key uint64) ([]byte, bool, error)
+	ReadAny(key uint64) ([]byte, bool, error)
 	Write(tid uint64, data []byte) (uint64, error)
+	WriteHeap(tid uint64, heap uint64, data []byte) (uint64, error)
 	Delete(tid uint64, key uint64) (bool, error)
 }
 
@@ -155,7 +158,7 @@ func (vm *verManage) Read(tid uint64, key uint64) ([]byte, bool, error) {
 		return nil, false, err
 	}
 	ent := val.(*entry)
-	defer vm.cache.Release(tid) // 释放缓存
+	defer vm.cache.Release(key) // 释放缓存
 
 	if !t.IsVisible(vm.txManage, ent) {
 		return nil, false, nil
@@ -163,6 +166,75 @@ func (vm *verManage) Read(tid uint64, key uint64) ([]byte, bool, error) {
 	return ent.Data(), true, nil
 }
 
+// ReadLatest 读取数据的最新版本（不考虑事务的隔离级别，用于检查唯一约束）
+//
+// 数据由回滚的事务创建，或者已经被删除（删除的事务已经提交或者是当前事务）时返回 false
+// 数据由其他未提交的事务创建或者删除时，无法确定数据最终是否存在，当前事务自动回滚
+func (vm *verManage) ReadLatest(tid uint64, key uint64) ([]byte, bool, error) {
+	vm.Lock()
+	t := vm.txCache[tid]
+	vm.Unlock()
+
+	if t.Err != nil {
+		return nil, false, t.Err
+	}
+
+	// 读取数据
+	val, err := vm.cache.Obtain(key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	ent := val.(*entry)
+	defer vm.cache.Release(key) // 释放缓存
+
+	minId := ent.Min()
+	maxId := ent.Max()
+	if vm.txManage.IsRolledBack(minId) {
+		return nil, false, nil
+	}
+
+	// 判断是否被删除（删除的事务回滚时数据仍然存在）
+	if maxId != 0 && !vm.txManage.IsRolledBack(maxId) {
+		if maxId == tid || vm.txManage.IsCommitted(maxId) {
+			return nil, false, nil
+		}
+		return nil, false, vm.conflict(t)
+	}
+	if minId != tid && !vm.txManage.IsCommitted(minId) {
+		return nil, false, vm.conflict(t)
+	}
+	return ent.Data(), true, nil
+}
+
+// ReadAny 读取数据（不考虑数据的可见性，用于建立索引），数据由回滚的事务创建时返回 false
+func (vm *verManage) ReadAny(key uint64) ([]byte, bool, error) {
+	val, err := vm.cache.Obtain(key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	ent := val.(*entry)
+	defer vm.cache.Release(key) // 释放缓存
+
+	if vm.txManage.IsRolledBack(ent.Min()) {
+		return nil, false, nil
+	}
+	return ent.Data(), true, nil
+}
+
+// conflict 与其他事务冲突，当前事务自动回滚
+func (vm *verManage) conflict(t *transaction) error {
+	vm.rollback(t.Id, false)
+	t.Err = ErrCannotHandle
+	t.AutoRollback = true
+	return t.Err
+}
+
 func (vm *verManage) Write(tid uint64, data []byte) (uint64, error) {
 	vm.Lock()
 	t := vm.txCache[tid]
@@ -179,6 +251,23 @@ func (vm *verManage) Write(tid uint64, data []byte) (uint64, error) {
 	return vm.dataManage.Write(tid, ent)
 }
 
+// WriteHeap 写入数据到堆中（与 Write 相同，只是数据保存在堆的页面中）
+func (vm *verManage) WriteHeap(tid uint64, heap uint64, data []byte) (uint64, error) {
+	vm.Lock()
+	t := vm.txCache[tid]
+	vm.Unlock()
+
+	if t.Err != nil {
+		return 0, t.Err
+	}
+
+	// 包装成 entry 数据
+	ent := make([]byte, offData+len(data))
+	bin.PutUint64(ent[offMin:], tid)
+	copy(ent[offData:], data)
+	return vm.dataManage.WriteHeap(tid, heap, ent)
+}
+
 func (vm *verManage) Delete(tid uint64, key uint64) (bool, error) {
 	vm.Lock()
 	t := vm.txCache[tid]
@@ -197,7 +286,7 @@ func (vm *verManage) Delete(tid uint64, key uint64) (bool, error) {
 		return false, err
 	}
 	ent := val.(*entry)
-	defer vm.cache.Release(tid) // 释放缓存
+	defer vm.cache.Release(key) // 释放缓存
 
 	// 判断是否可见
 	if !t.IsVisible(vm.txManage, ent) {
@@ -207,10 +296,7 @@ func (vm *verManage) Delete(tid uint64, key uint64) (bool, error) {
 	// 添加锁并判断是否死锁
 	ok, ch := vm.txLock.Add(tid, key)
 	if !ok {
-		vm.rollback(tid, false) // 自动回滚
-		t.Err = ErrCannotHandle
-		t.AutoRollback = true
-		return false, t.Err
+		return false, vm.conflict(t) // 自动回滚
 	}
 	<-ch // 等待锁释放
 
@@ -221,10 +307,7 @@ func (vm *verManage) Delete(tid uint64, key uint64) (bool, error) {
 
 	// 判断是否发生了版本跳跃
 	if t.IsSkip(vm.txManage, ent) {
-		vm.rollback(tid, false) // 自动回滚
-		t.Err = ErrCannotHandle
-		t.AutoRollback = true
-		return false, t.Err
+		return false, vm.conflict(t) // 自动回滚
 	}
 
 	// 更新 max
@@ -1,10 +1,12 @@
 package ver
 
 import (
+	"os"
 	"testing"
 
 	"github.com/ggymm/db"
 	"github.com/ggymm/db/data"
+	"github.com/ggymm/db/pkg/cache"
 	"github.com/ggymm/db/tx"
 )
 
@@ -49,3 +51,63 @@ func TestVerManage_Handle(t *testing.T) {
 	t.Logf("read data: %s", string(src))
 	t.Logf("read data: %s", string(dst))
 }
+
+// refCache 记录每个缓存项的引用数量
+type refCache struct {
+	cache.Cache
+	refs map[uint64]int
+}
+
+func (c *refCache) Obtain(key uint64) (any, error) {
+	val, err := c.Cache.Obtain(key)
+	if err == nil {
+		c.refs[key]++
+	}
+	return val, err
+}
+
+func (c *refCache) Release(key uint64) {
+	c.refs[key]--
+	c.Cache.Release(key)
+}
+
+func TestVerManage_Release(t *testing.T) {
+	opt := db.NewOption(db.RunPath(), "temp/ver_release")
+	opt.Memory = (1 << 20) * 64
+	err := os.RemoveAll(opt.Path)
+	if err != nil {
+		t.Fatalf("remove err %v", err)
+	}
+	tm := tx.NewManager(opt)
+	dm := data.NewManage(tm, opt)
+	defer tm.Close()
+	defer dm.Close()
+
+	vm := NewManage(tm, dm).(*verManage)
+	rc := &refCache{Cache: vm.cache, refs: make(map[uint64]int)}
+	vm.cache = rc
+
+	// 读取和删除之后，释放的缓存项与获取的缓存项相同
+	tid := vm.Begin(0)
+	key, err := vm.Write(tid, []byte("test"))
+	if err != nil {
+		t.Fatalf("write err %v", err)
+	}
+	_, ok, err := vm.Read(tid, key)
+	if err != nil || !ok {
+		t.Fatalf("read %v, err %v", ok, err)
+	}
+	ok, err = vm.Delete(tid, key)
+	if err != nil || !ok {
+		t.Fatalf("delete %v, err %v", ok, err)
+	}
+	err = vm.Commit(tid)
+	if err != nil {
+		t.Fatalf("commit err %v", err)
+	}
+	for k, n := range rc.refs {
+		if n != 0 {
+			t.Fatalf("key %d refs %d", k, n)
+		}
+	}
+}